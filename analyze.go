@@ -0,0 +1,40 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+
+	"github.com/geldata/gel-go/gelplan"
+)
+
+// Analyze runs cmd with the analyze statement and returns the plan the
+// database used. The query is executed, so analyzing a query that modifies
+// data modifies the data.
+func (c *Client) Analyze(
+	ctx context.Context,
+	cmd string,
+	args ...any,
+) (*gelplan.Plan, error) {
+	var data []byte
+	err := c.QuerySingleJSON(ctx, "analyze "+cmd, &data, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return gelplan.Parse(data)
+}
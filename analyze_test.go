@@ -0,0 +1,50 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/geldata/gel-go/gelplan"
+)
+
+func TestAnalyze(t *testing.T) {
+	ctx := context.Background()
+	query := "SELECT User { name } FILTER .name = <str>$0"
+	plan, err := client.Analyze(ctx, query, "alice")
+	require.NoError(t, err)
+	require.NotNil(t, plan.Root)
+	require.NotEmpty(t, plan.Buffers)
+	assert.Contains(t, plan.Buffers[0], "FILTER .name = <str>$0")
+
+	steps := 0
+	plan.Walk(func(n *gelplan.Node, _ int) bool {
+		steps += len(n.Steps)
+		for _, span := range n.Spans {
+			_, ok := plan.Locate(span)
+			assert.True(t, ok)
+		}
+		return true
+	})
+	assert.Greater(t, steps, 0)
+	assert.True(t, strings.Contains(plan.String(), "cost="))
+}
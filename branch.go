@@ -0,0 +1,119 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/geldata/gel-go/gelcfg"
+	gelerrint "github.com/geldata/gel-go/internal/gelerr"
+)
+
+// WithBranch returns a client for another branch of the same instance. The
+// new client has the same connection configuration, retry options, query
+// options, globals and config as c, but it has its own connection pool. It
+// must be closed separately from c.
+func (c *Client) WithBranch(name string) *Client {
+	return &Client{pool: c.pool.WithBranch(name)}
+}
+
+// quoteIdent quotes name so that it can be used as an identifier in EdgeQL.
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// CreateBranch creates the branch name. Schema and data branches are copied
+// from the branch named from, or from the client's branch if from is empty.
+// from must be empty for empty branches.
+//
+// Branches require Gel 5.0 or newer.
+func (c *Client) CreateBranch(ctx context.Context, name string, typ gelcfg.BranchType, from string) error { // nolint:lll
+	switch typ {
+	case gelcfg.EmptyBranch:
+		if from != "" {
+			return gelerrint.NewInvalidArgumentError(
+				"empty branches can not be created from another branch",
+				nil,
+			)
+		}
+
+		return c.Execute(ctx, "CREATE EMPTY BRANCH "+quoteIdent(name))
+	case gelcfg.SchemaBranch, gelcfg.DataBranch:
+		if from == "" {
+			err := c.QuerySingle(
+				ctx,
+				"SELECT sys::get_current_branch()",
+				&from,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return c.Execute(ctx, fmt.Sprintf(
+			"CREATE %v BRANCH %v FROM %v",
+			typ,
+			quoteIdent(name),
+			quoteIdent(from),
+		))
+	default:
+		return gelerrint.NewInvalidArgumentError(
+			fmt.Sprintf("unknown branch type %q", typ), nil)
+	}
+}
+
+// DropBranch drops the branch name. If force is true, connections to the
+// branch are closed by the server, otherwise the branch can only be dropped
+// when no one is connected to it.
+func (c *Client) DropBranch(ctx context.Context, name string, force bool) error { // nolint:lll
+	cmd := "DROP BRANCH " + quoteIdent(name)
+	if force {
+		cmd += " FORCE"
+	}
+
+	return c.Execute(ctx, cmd)
+}
+
+// RenameBranch renames the branch from to to. If force is true, connections
+// to the branch are closed by the server.
+func (c *Client) RenameBranch(ctx context.Context, from, to string, force bool) error { // nolint:lll
+	cmd := fmt.Sprintf(
+		"ALTER BRANCH %v RENAME TO %v",
+		quoteIdent(from),
+		quoteIdent(to),
+	)
+	if force {
+		cmd += " FORCE"
+	}
+
+	return c.Execute(ctx, cmd)
+}
+
+// ListBranches returns the names of all branches sorted by name.
+func (c *Client) ListBranches(ctx context.Context) ([]string, error) {
+	var names []string
+	err := c.Query(ctx, "SELECT sys::Branch.name", &names)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
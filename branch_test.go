@@ -0,0 +1,87 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranches(t *testing.T) {
+	ctx := context.Background()
+
+	query := "SELECT sys::get_current_branch()"
+
+	var current string
+	err := client.QuerySingle(ctx, query, &current)
+	require.NoError(t, err)
+
+	err = client.CreateBranch(ctx, "go-test-data", gelcfg.DataBranch, "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.DropBranch(ctx, "go-test-data", true)
+		_ = client.DropBranch(ctx, "go-test-renamed", true)
+	})
+
+	err = client.CreateBranch(ctx, "go-test-empty", gelcfg.EmptyBranch, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.DropBranch(ctx, "go-test-empty", true) })
+
+	branches, err := client.ListBranches(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, branches, current)
+	assert.Contains(t, branches, "go-test-data")
+	assert.Contains(t, branches, "go-test-empty")
+
+	err = client.RenameBranch(ctx, "go-test-data", "go-test-renamed", true)
+	require.NoError(t, err)
+
+	renamed := client.WithBranch("go-test-renamed")
+	defer renamed.Close() // nolint:errcheck
+
+	var branch string
+	err = renamed.QuerySingle(ctx, query, &branch)
+	require.NoError(t, err)
+	assert.Equal(t, "go-test-renamed", branch)
+
+	// Data branches have the schema of their source branch.
+	var count int64
+	err = renamed.QuerySingle(ctx, "SELECT count(User)", &count)
+	require.NoError(t, err)
+
+	empty := client.WithBranch("go-test-empty")
+	defer empty.Close() // nolint:errcheck
+
+	err = empty.QuerySingle(ctx, "SELECT count(User)", &count)
+	assert.Error(t, err)
+}
+
+func TestCreateBranchInvalidType(t *testing.T) {
+	ctx := context.Background()
+
+	err := client.CreateBranch(ctx, "go-test", gelcfg.BranchType("x"), "")
+	assert.EqualError(t, err,
+		`gel.InvalidArgumentError: unknown branch type "x"`)
+
+	err = client.CreateBranch(ctx, "go-test", gelcfg.EmptyBranch, "main")
+	assert.EqualError(t, err, "gel.InvalidArgumentError: "+
+		"empty branches can not be created from another branch")
+}
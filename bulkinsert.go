@@ -0,0 +1,174 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/bulk"
+	gelerrint "github.com/geldata/gel-go/internal/gelerr"
+)
+
+// BulkInsert inserts rows into the object type typeName. rows must be a slice
+// of structs or of pointers to structs. Each exported field is inserted into
+// the property named by its gel tag or, if it has no tag, by its field name.
+// Fields named id are skipped. Fields must have a scalar type, an optional
+// scalar type like [geltypes.OptionalStr] or a slice of a scalar type. Links
+// and properties with custom scalar or enum types are not supported.
+//
+// Rows are split into chunks as configured by opts. Each chunk is sent as
+// an array<tuple<...>> argument to a single INSERT statement that is run in
+// its own transaction, so chunks that succeeded are committed even if a later
+// chunk fails. Chunks are retried as described in [Client.Tx]. If any chunk
+// fails a [*BulkInsertError] is returned.
+//
+// All rows are checked before the first chunk is sent, so invalid rows like
+// nil pointers cause an InvalidArgumentError without inserting anything.
+// typeName may be qualified with a module name like default::User.
+func (c *Client) BulkInsert(ctx context.Context, typeName string, rows any, opts gelcfg.BulkInsertOptions) error { // nolint:gocritic,lll
+	if !opts.IsValid() {
+		return gelerrint.NewInvalidArgumentError(
+			"BulkInsertOptions not created with NewBulkInsertOptions() "+
+				"are not valid",
+			nil,
+		)
+	}
+
+	if opts.MaxRows() < 1 {
+		return gelerrint.NewInvalidArgumentError(
+			"BulkInsertOptions max rows must be greater than 0", nil)
+	}
+
+	val := reflect.ValueOf(rows)
+	if val.Kind() != reflect.Slice {
+		return gelerrint.NewInvalidArgumentError(fmt.Sprintf(
+			"expected rows to be a slice got %T", rows,
+		), nil)
+	}
+
+	typ := val.Type().Elem()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	shape, err := bulk.NewShape(typ)
+	if err != nil {
+		return gelerrint.NewInvalidArgumentError(err.Error(), nil)
+	}
+
+	query, err := shape.Query(typeName)
+	if err != nil {
+		return gelerrint.NewInvalidArgumentError(err.Error(), nil)
+	}
+
+	var chunks []bulk.Chunk
+	for offset := 0; offset < val.Len(); {
+		chunk, err := shape.NextChunk(
+			val,
+			offset,
+			opts.MaxRows(),
+			opts.MaxBytes(),
+		)
+		if err != nil {
+			return gelerrint.NewInvalidArgumentError(err.Error(), nil)
+		}
+
+		chunks = append(chunks, chunk)
+		offset += len(chunk.Rows)
+	}
+
+	result := &BulkInsertError{}
+	for _, chunk := range chunks {
+		err := c.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+			return tx.Execute(ctx, query, chunk.Rows)
+		})
+		if err != nil {
+			result.Chunks = append(result.Chunks, BulkInsertChunkError{
+				Offset: chunk.Offset,
+				Rows:   len(chunk.Rows),
+				Err:    err,
+			})
+
+			if !opts.ContinueOnError() {
+				break
+			}
+			continue
+		}
+
+		result.Inserted += len(chunk.Rows)
+	}
+
+	if len(result.Chunks) > 0 {
+		return result
+	}
+
+	return nil
+}
+
+// BulkInsertError is returned by [Client.BulkInsert] when one or more chunks
+// failed.
+type BulkInsertError struct {
+	// Chunks are the chunks that failed in the order they were inserted.
+	Chunks []BulkInsertChunkError
+
+	// Inserted is the number of rows in chunks that were committed.
+	Inserted int
+}
+
+// BulkInsertChunkError is the error for a chunk of rows.
+type BulkInsertChunkError struct {
+	// Offset is the index of the chunk's first row.
+	Offset int
+
+	// Rows is the number of rows in the chunk.
+	Rows int
+
+	// Err is the error the chunk failed with.
+	Err error
+}
+
+func (e *BulkInsertError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "bulk insert failed for %v chunk(s)", len(e.Chunks))
+
+	for _, chunk := range e.Chunks {
+		fmt.Fprintf(
+			&b,
+			"; rows %v-%v: %v",
+			chunk.Offset,
+			chunk.Offset+chunk.Rows-1,
+			chunk.Err,
+		)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the chunk errors.
+func (e *BulkInsertError) Unwrap() []error {
+	errs := make([]error, len(e.Chunks))
+	for i, chunk := range e.Chunks {
+		errs[i] = chunk.Err
+	}
+
+	return errs
+}
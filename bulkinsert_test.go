@@ -0,0 +1,155 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/gelerr"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bulkTestRow struct {
+	ID   geltypes.UUID        `gel:"id"`
+	Name string               `gel:"name"`
+	Nick geltypes.OptionalStr `gel:"nick"`
+	Tags []string             `gel:"tags"`
+}
+
+func deleteBulkTestRows(t *testing.T) {
+	err := client.Execute(context.Background(), "DELETE BulkTest")
+	require.NoError(t, err)
+}
+
+func TestBulkInsert(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() { deleteBulkTestRows(t) })
+
+	rows := make([]bulkTestRow, 25)
+	for i := range rows {
+		rows[i].Name = fmt.Sprintf("row %02d", i)
+		if i%2 == 0 {
+			rows[i].Nick = geltypes.NewOptionalStr(fmt.Sprint(i))
+			rows[i].Tags = []string{"even"}
+		}
+	}
+
+	opts := gelcfg.NewBulkInsertOptions().WithMaxRows(10)
+	err := client.BulkInsert(ctx, "BulkTest", rows, opts)
+	require.NoError(t, err)
+
+	var result []bulkTestRow
+	err = client.Query(
+		ctx,
+		"SELECT BulkTest { name, nick, tags } ORDER BY .name",
+		&result,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 25, len(result))
+
+	for i, row := range result {
+		assert.Equal(t, rows[i].Name, row.Name)
+		assert.Equal(t, rows[i].Nick, row.Nick)
+		if i%2 == 0 {
+			assert.Equal(t, []string{"even"}, row.Tags)
+		} else {
+			assert.Equal(t, []string{}, row.Tags)
+		}
+	}
+}
+
+func TestBulkInsertChunkErrors(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() { deleteBulkTestRows(t) })
+
+	rows := []*bulkTestRow{
+		{Name: "a"},
+		{Name: "b"},
+		{Name: "a"},
+		{Name: "c"},
+		{Name: "b"},
+	}
+
+	opts := gelcfg.NewBulkInsertOptions().WithMaxRows(2)
+	err := client.BulkInsert(ctx, "BulkTest", rows, opts)
+
+	var bulkErr *BulkInsertError
+	require.True(t, errors.As(err, &bulkErr), err)
+	assert.Equal(t, 2, bulkErr.Inserted)
+	require.Equal(t, 1, len(bulkErr.Chunks))
+	assert.Equal(t, 2, bulkErr.Chunks[0].Offset)
+	assert.Equal(t, 2, bulkErr.Chunks[0].Rows)
+
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr))
+	assert.True(t, gelErr.Category(gelerr.ConstraintViolationError))
+
+	deleteBulkTestRows(t)
+	err = client.BulkInsert(
+		ctx,
+		"BulkTest",
+		rows,
+		opts.WithContinueOnError(true),
+	)
+	require.True(t, errors.As(err, &bulkErr), err)
+	assert.Equal(t, 2, bulkErr.Inserted)
+	require.Equal(t, 2, len(bulkErr.Chunks))
+	assert.Equal(t, 4, bulkErr.Chunks[1].Offset)
+	assert.Equal(t, 1, bulkErr.Chunks[1].Rows)
+}
+
+func TestBulkInsertInvalidRows(t *testing.T) {
+	ctx := context.Background()
+	opts := gelcfg.NewBulkInsertOptions()
+
+	err := client.BulkInsert(ctx, "BulkTest", bulkTestRow{}, opts)
+	assert.EqualError(t, err, "gel.InvalidArgumentError: "+
+		"expected rows to be a slice got gel.bulkTestRow")
+
+	err = client.BulkInsert(
+		ctx,
+		"BulkTest",
+		[]bulkTestRow{},
+		gelcfg.BulkInsertOptions{},
+	)
+	assert.EqualError(t, err, "gel.InvalidArgumentError: "+
+		"BulkInsertOptions not created with NewBulkInsertOptions() "+
+		"are not valid")
+
+	err = client.BulkInsert(
+		ctx,
+		"BulkTest",
+		[]*bulkTestRow{{Name: "a"}, {Name: "b"}, nil},
+		opts.WithMaxRows(2),
+	)
+	assert.EqualError(t, err, "gel.InvalidArgumentError: row 2 is nil")
+
+	var count int64
+	err = client.QuerySingle(ctx, "SELECT count(BulkTest)", &count)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	err = client.BulkInsert(ctx, "::BulkTest", []bulkTestRow{}, opts)
+	assert.EqualError(t, err, "gel.InvalidArgumentError: "+
+		`invalid type name "::BulkTest"`)
+}
@@ -25,6 +25,12 @@ import (
 	gel "github.com/geldata/gel-go/internal/client"
 )
 
+func init() {
+	gel.ClientPool = func(client any) *gel.Pool {
+		return client.(*Client).pool
+	}
+}
+
 // CreateClient returns a new client. The client connects lazily. Call
 // [Client.EnsureConnected] to force a connection.
 //
@@ -127,6 +133,11 @@ func (c *Client) Query(ctx context.Context, cmd string, out interface{}, args ..
 // needed for dock link [gelerr.NoDataError]
 var _ = gelerr.NoDataError
 
+var (
+	_ geltypes.SingleSQLQuerier = (*Client)(nil)
+	_ geltypes.SingleSQLQuerier = (*gel.Tx)(nil)
+)
+
 // QuerySingle runs a singleton-returning query and returns its element.  If
 // the query executes successfully but doesn't return a result a
 // [gelerr.NoDataError] is returned.  If the out
@@ -197,6 +208,14 @@ func (c *Client) QuerySingleJSON(ctx context.Context, cmd string, out interface{
 }
 
 // QuerySQL runs a SQL query and returns the results.
+//
+// The out argument must be a pointer to a slice of structs, map[string]any or
+// []any. Columns are matched to struct fields by `gel` tag, `db` tag or field
+// name, and finally by comparing the column name case insensitively to the
+// field name or its snake_case form, so a column named user_id can be decoded
+// into a field named UserID. NULL values can only be decoded into optional
+// types. Rows decoded into map[string]any or []any use the default go type
+// for each column and represent NULL as nil.
 func (c *Client) QuerySQL(ctx context.Context, cmd string, out interface{}, args ...interface{}) error { //nolint:lll
 	conn, err := c.pool.Acquire(ctx)
 	if err != nil {
@@ -217,6 +236,31 @@ func (c *Client) QuerySQL(ctx context.Context, cmd string, out interface{}, args
 	return gel.FirstError(err, c.pool.Release(conn, err))
 }
 
+// QuerySingleSQL runs a SQL query that returns at most one row and decodes
+// it into out using the same rules as [Client.QuerySQL]. If the query
+// executes successfully but doesn't return a row a [gelerr.NoDataError] is
+// returned. If the out argument is an optional type the out argument will be
+// set to missing instead of returning a NoDataError.
+func (c *Client) QuerySingleSQL(ctx context.Context, cmd string, out interface{}, args ...interface{}) error { //nolint:lll
+	conn, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = gel.RunQuery(
+		ctx,
+		conn,
+		"QuerySingleSQL",
+		cmd,
+		out,
+		args,
+		c.pool.State,
+		&c.pool.QueryConfig,
+		false,
+	)
+	return gel.FirstError(err, c.pool.Release(conn, err))
+}
+
 // ExecuteSQL executes a SQL command (or commands).
 func (c *Client) ExecuteSQL(ctx context.Context, cmd string, args ...interface{}) error { //nolint:lll
 	conn, err := c.pool.Acquire(ctx)
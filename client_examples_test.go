@@ -21,6 +21,7 @@ import (
 	"log"
 
 	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/gelcfg"
 )
 
 func ExampleCreateClient() {
@@ -57,6 +58,20 @@ func ExampleClient_ExecuteSQL() {
 	// Output:
 }
 
+func ExampleClient_BulkInsert() {
+	type User struct {
+		Name string `gel:"name"`
+	}
+
+	users := []User{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}}
+	opts := gelcfg.NewBulkInsertOptions().WithMaxRows(500)
+
+	err := client.BulkInsert(ctx, "User", users, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 func ExampleClient_Query() {
 	var output []struct {
 		Result int64 `gel:"result"`
@@ -96,6 +111,18 @@ func ExampleClient_QuerySQL() {
 	// Output: [{4}]
 }
 
+func ExampleClient_QuerySingleSQL() {
+	var output map[string]any
+
+	err := client.QuerySingleSQL(ctx, `SELECT 2 + 2 AS result`, &output)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(output)
+	// Output: map[result:4]
+}
+
 func ExampleClient_QuerySingle() {
 	var output struct {
 		Result int64 `gel:"result"`
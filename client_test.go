@@ -233,6 +233,32 @@ func TestQuerySQL(t *testing.T) {
 		err = client.QuerySQL(ctx, "select 1 + $1::int8", &result3, int64(41))
 		assert.NoError(t, err)
 		assert.Equal(t, int64(42), result3[0].Col1)
+
+		type res4 struct {
+			UserID    int32 `db:"user_id"`
+			FirstName string
+			LastName  geltypes.OptionalStr
+		}
+		var result4 []res4
+		err = client.QuerySQL(
+			ctx,
+			"select 1 AS user_id, 'a' AS first_name, NULL::text AS LASTNAME",
+			&result4,
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, []res4{{UserID: 1, FirstName: "a"}}, result4)
+
+		var result5 []map[string]any
+		err = client.QuerySQL(
+			ctx, "select 1 AS foo, NULL::text AS bar", &result5)
+		assert.NoError(t, err)
+		assert.Equal(
+			t, []map[string]any{{"foo": int32(1), "bar": nil}}, result5)
+
+		var result6 [][]any
+		err = client.QuerySQL(ctx, "select 1, 'two'", &result6)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]any{{int32(1), "two"}}, result6)
 	} else {
 		var res []interface{}
 		err := client.QuerySQL(ctx, "select 1", &res)
@@ -253,6 +279,30 @@ func TestQuerySQL(t *testing.T) {
 	}
 }
 
+func TestQuerySingleSQL(t *testing.T) {
+	ctx := context.Background()
+
+	if !serverVersionGTE(t, 6, 0) {
+		t.Skip("SQL queries require server version 6.0 or newer")
+	}
+
+	var result struct {
+		Foo int32
+	}
+	err := client.QuerySingleSQL(ctx, "select 1 AS foo", &result)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), result.Foo)
+
+	var row map[string]any
+	err = client.QuerySingleSQL(ctx, "select 1 AS foo WHERE false", &row)
+	assert.EqualError(t, err, "gel.NoDataError: zero results")
+
+	err = client.QuerySingleSQL(
+		ctx, "select * FROM (VALUES (1), (2)) AS t(foo)", &row)
+	assert.EqualError(t, err, "gel.ResultCardinalityMismatchError: "+
+		"the query returned 2 rows but at most one was expected")
+}
+
 func TestSessionIdleTimeout(t *testing.T) {
 	ctx := context.Background()
 	p, err := gel.NewPool("", opts)
@@ -0,0 +1,386 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/geldata/gel-go/internal/project"
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+const defaultOutputSuffix = "_edgeql.go"
+
+// config is the [edgeql-go] section of a project's gel.toml file.
+type config struct {
+	options
+
+	// Include and Exclude are globs matched against .edgeql file paths
+	// relative to the project root. ** matches any number of directories.
+	Include []string `toml:"include"`
+	Exclude []string `toml:"exclude"`
+
+	// Overrides maps directories relative to the project root to options
+	// that apply to the directory and its subdirectories.
+	Overrides map[string]options `toml:"overrides"`
+
+	root string
+}
+
+// options can be set for the whole project and overridden per directory.
+// Unset fields inherit their value from the parent configuration.
+type options struct {
+	MixedCaps    *bool                  `toml:"mixedcaps"`
+	PubFuncs     *bool                  `toml:"pubfuncs"`
+	PubTypes     *bool                  `toml:"pubtypes"`
+	RawMessage   *bool                  `toml:"rawmessage"`
+	ArgStruct    *bool                  `toml:"argstruct"`
+	TypedEnums   *bool                  `toml:"typedenums"`
+	OutputSuffix string                 `toml:"output-suffix"`
+	Package      string                 `toml:"package"`
+	Types        map[string]typeMapping `toml:"types"`
+}
+
+// typeMapping replaces the go type generated for a schema scalar type. Go
+// types are written as a fully qualified import path and type name, for
+// example github.com/shopspring/decimal.Decimal.
+type typeMapping struct {
+	Type     string `toml:"type"`
+	Optional string `toml:"optional"`
+}
+
+// merge returns o with the fields that are set in other replaced.
+func (o options) merge(other options) options {
+	if other.MixedCaps != nil {
+		o.MixedCaps = other.MixedCaps
+	}
+	if other.PubFuncs != nil {
+		o.PubFuncs = other.PubFuncs
+	}
+	if other.PubTypes != nil {
+		o.PubTypes = other.PubTypes
+	}
+	if other.RawMessage != nil {
+		o.RawMessage = other.RawMessage
+	}
+	if other.ArgStruct != nil {
+		o.ArgStruct = other.ArgStruct
+	}
+	if other.TypedEnums != nil {
+		o.TypedEnums = other.TypedEnums
+	}
+	if other.OutputSuffix != "" {
+		o.OutputSuffix = other.OutputSuffix
+	}
+	if other.Package != "" {
+		o.Package = other.Package
+	}
+	if len(other.Types) > 0 {
+		types := make(map[string]typeMapping,
+			len(o.Types)+len(other.Types))
+		for name, mapping := range o.Types {
+			types[name] = mapping
+		}
+		for name, mapping := range other.Types {
+			types[name] = mapping
+		}
+		o.Types = types
+	}
+
+	return o
+}
+
+// loadConfig reads the edgeql-go configuration from the project's manifest.
+func loadConfig(p *project.Project) (*config, error) {
+	data, err := os.ReadFile(p.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var x struct {
+		EdgeQLGo config `toml:"edgeql-go"`
+	}
+	err = toml.Unmarshal(data, &x)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p.Manifest, err)
+	}
+
+	cfg := &x.EdgeQLGo
+	cfg.root = p.RootDir
+	err = cfg.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid [edgeql-go] config in %s: %w",
+			p.Manifest, err)
+	}
+
+	return cfg, nil
+}
+
+func (c *config) validate() error {
+	for _, glob := range append(c.Include, c.Exclude...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("bad glob %q: %w", glob, err)
+		}
+	}
+
+	overrides := make(map[string]options, len(c.Overrides))
+	for dir, o := range c.Overrides {
+		clean := filepath.ToSlash(filepath.Clean(dir))
+		if filepath.IsAbs(dir) || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf(
+				"override %q is not a directory inside the project", dir)
+		}
+		overrides[clean] = o
+	}
+	c.Overrides = overrides
+
+	for _, o := range append([]options{c.options}, values(overrides)...) {
+		for name, mapping := range o.Types {
+			if mapping.Type == "" {
+				return fmt.Errorf("type mapping for %s is missing type", name)
+			}
+		}
+		if o.OutputSuffix == "" {
+			continue
+		}
+		if strings.ContainsAny(o.OutputSuffix, `/\`) ||
+			!strings.HasSuffix(o.OutputSuffix, ".go") {
+			return fmt.Errorf(
+				"output-suffix %q must be a file name ending in .go",
+				o.OutputSuffix)
+		}
+	}
+
+	return nil
+}
+
+func values(m map[string]options) []options {
+	v := make([]options, 0, len(m))
+	for _, o := range m {
+		v = append(v, o)
+	}
+	return v
+}
+
+// dirOptions returns the options for dir. Overrides for parent directories are
+// applied before overrides for their subdirectories.
+func (c *config) dirOptions(dir string) (options, error) {
+	rel, err := filepath.Rel(c.root, dir)
+	if err != nil {
+		return options{}, err
+	}
+	rel = filepath.ToSlash(rel)
+
+	var dirs []string
+	for d := range c.Overrides {
+		if d == "." || d == rel || strings.HasPrefix(rel, d+"/") {
+			dirs = append(dirs, d)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return len(dirs[i]) < len(dirs[j])
+	})
+
+	o := options{OutputSuffix: defaultOutputSuffix}.merge(c.options)
+	for _, d := range dirs {
+		o = o.merge(c.Overrides[d])
+	}
+
+	return o, nil
+}
+
+// includes returns true if queryFile should be generated.
+func (c *config) includes(queryFile string) (bool, error) {
+	rel, err := filepath.Rel(c.root, queryFile)
+	if err != nil {
+		return false, err
+	}
+	rel = filepath.ToSlash(rel)
+
+	if len(c.Include) > 0 && !matchAny(c.Include, rel) {
+		return false, nil
+	}
+
+	return !matchAny(c.Exclude, rel), nil
+}
+
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matchGlob(strings.Split(glob, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against glob segments. A ** segment
+// matches zero or more path segments.
+func matchGlob(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		ok, _ := path.Match(glob[0], name[0])
+		if !ok {
+			return false
+		}
+		glob, name = glob[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// mappedType is the go type that replaces a schema type.
+type mappedType struct {
+	schemaName string
+	required   goTypeName
+	optional   *goTypeName
+}
+
+// goTypeName is a go type reference and the package it is imported from.
+// alias is set if the package name used in reference is not the last
+// element of the import path.
+type goTypeName struct {
+	reference  string
+	importPath string
+	alias      string
+}
+
+func newMappedType(name string, mapping typeMapping) (*mappedType, error) {
+	required, err := parseGoType(mapping.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &mappedType{schemaName: name, required: required}
+	if mapping.Optional != "" {
+		optional, err := parseGoType(mapping.Optional)
+		if err != nil {
+			return nil, err
+		}
+		t.optional = &optional
+	}
+
+	return t, nil
+}
+
+// goType returns the go type name and its imports. Aliased imports are
+// returned as the alias and the import path separated by a space.
+func (t *mappedType) goType(required bool) (string, []string, error) {
+	typ := &t.required
+	if !required {
+		if t.optional == nil {
+			return "", nil, fmt.Errorf(
+				"%s is optional but the type mapping has no optional type",
+				t.schemaName)
+		}
+		typ = t.optional
+	}
+
+	if typ.importPath == "" {
+		return typ.reference, nil, nil
+	}
+
+	if typ.alias != "" {
+		return typ.reference, []string{typ.alias + " " + typ.importPath}, nil
+	}
+
+	return typ.reference, []string{typ.importPath}, nil
+}
+
+// parseGoType parses a fully qualified go type like
+// github.com/shopspring/decimal.Decimal. Predeclared types like string have
+// no import path.
+func parseGoType(spec string) (goTypeName, error) {
+	slash := strings.LastIndex(spec, "/")
+	dot := strings.LastIndex(spec, ".")
+	if dot < slash {
+		return goTypeName{}, fmt.Errorf("missing type name in %q", spec)
+	}
+
+	if dot == -1 {
+		return goTypeName{reference: spec}, nil
+	}
+
+	importPath, name := spec[:dot], spec[dot+1:]
+	if importPath == "" || name == "" {
+		return goTypeName{}, fmt.Errorf("malformed go type %q", spec)
+	}
+
+	pkg := packageName(importPath)
+	if pkg == "" {
+		return goTypeName{}, fmt.Errorf("malformed go type %q", spec)
+	}
+
+	t := goTypeName{reference: pkg + "." + name, importPath: importPath}
+	if pkg != path.Base(importPath) {
+		t.alias = pkg
+	}
+
+	return t, nil
+}
+
+// packageName guesses the package name from the import path. Major version
+// suffixes like /v2 or .v3 are skipped and characters that are not allowed
+// in identifiers are removed. The generated code imports the package with
+// this name as its alias if it is not the last element of the path.
+func packageName(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && isMajorVersion(name) {
+		name = parts[len(parts)-2]
+	}
+
+	if i := strings.LastIndex(name, "."); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
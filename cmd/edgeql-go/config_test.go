@@ -0,0 +1,222 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/geldata/gel-go/internal"
+	"github.com/geldata/gel-go/internal/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeManifest(t *testing.T, manifest string) *project.Project {
+	root := t.TempDir()
+	file := filepath.Join(root, "gel.toml")
+	require.NoError(t, os.WriteFile(file, []byte(manifest), 0o600))
+
+	p, err := project.Find(root)
+	require.NoError(t, err)
+	return p
+}
+
+func TestConfigDirOptions(t *testing.T) {
+	p := writeManifest(t, `
+[edgeql-go]
+pubfuncs = true
+mixedcaps = true
+exclude = ["legacy/**"]
+
+[edgeql-go.types."default::Money"]
+type = "github.com/acme/money/v2.Amount"
+
+[edgeql-go.overrides."services/api"]
+pubtypes = true
+package = "queries"
+output-suffix = ".gen.go"
+
+[edgeql-go.overrides."services/api/internal"]
+mixedcaps = false
+
+[edgeql-go.overrides."services/api/internal".types."default::Money"]
+type = "string"
+optional = "github.com/geldata/gel-go/geltypes.OptionalStr"
+`)
+
+	cfg, err := loadConfig(p)
+	require.NoError(t, err)
+
+	o, err := cfg.dirOptions(p.RootDir)
+	require.NoError(t, err)
+	assert.True(t, isSet(o.PubFuncs))
+	assert.True(t, isSet(o.MixedCaps))
+	assert.False(t, isSet(o.PubTypes))
+	assert.Equal(t, defaultOutputSuffix, o.OutputSuffix)
+	assert.Equal(t, "", o.Package)
+
+	dir := filepath.Join(p.RootDir, "services", "api", "internal", "db")
+	o, err = cfg.dirOptions(dir)
+	require.NoError(t, err)
+	assert.True(t, isSet(o.PubFuncs))
+	assert.False(t, isSet(o.MixedCaps))
+	assert.True(t, isSet(o.PubTypes))
+	assert.Equal(t, ".gen.go", o.OutputSuffix)
+	assert.Equal(t, "queries", o.Package)
+
+	cmdCfg, err := newCmdConfig(o, internal.ProtocolVersion{Major: 2})
+	require.NoError(t, err)
+	name, imports, err := cmdCfg.types["default::Money"].goType(false)
+	require.NoError(t, err)
+	assert.Equal(t, "geltypes.OptionalStr", name)
+	assert.Equal(t, []string{"github.com/geldata/gel-go/geltypes"}, imports)
+
+	o, err = cfg.dirOptions(filepath.Join(p.RootDir, "services", "apis"))
+	require.NoError(t, err)
+	assert.False(t, isSet(o.PubTypes))
+
+	cmdCfg, err = newCmdConfig(o, internal.ProtocolVersion{Major: 2})
+	require.NoError(t, err)
+	name, imports, err = cmdCfg.types["default::Money"].goType(true)
+	require.NoError(t, err)
+	assert.Equal(t, "money.Amount", name)
+	assert.Equal(t, []string{"money github.com/acme/money/v2"}, imports)
+
+	_, _, err = cmdCfg.types["default::Money"].goType(false)
+	assert.EqualError(t, err, "default::Money is optional "+
+		"but the type mapping has no optional type")
+
+	_, err = newCmdConfig(o, internal.ProtocolVersion{Major: 1})
+	assert.EqualError(t, err,
+		"type mappings require protocol version 2.0 or later")
+}
+
+func TestParseGoType(t *testing.T) {
+	tests := []struct {
+		spec      string
+		reference string
+		imports   []string
+	}{
+		{"string", "string", nil},
+		{"time.Duration", "time.Duration", []string{"time"}},
+		{
+			"github.com/shopspring/decimal.Decimal",
+			"decimal.Decimal",
+			[]string{"github.com/shopspring/decimal"},
+		},
+		{
+			"github.com/acme/money/v2.Amount",
+			"money.Amount",
+			[]string{"money github.com/acme/money/v2"},
+		},
+		{
+			"github.com/mattn/go-sqlite3.X",
+			"gosqlite3.X",
+			[]string{"gosqlite3 github.com/mattn/go-sqlite3"},
+		},
+		{
+			"gopkg.in/yaml.v3.Node",
+			"yaml.Node",
+			[]string{"yaml gopkg.in/yaml.v3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			typ, err := newMappedType(
+				"default::T",
+				typeMapping{Type: test.spec},
+			)
+			require.NoError(t, err)
+
+			name, imports, err := typ.goType(true)
+			require.NoError(t, err)
+			assert.Equal(t, test.reference, name)
+			assert.Equal(t, test.imports, imports)
+		})
+	}
+
+	for _, spec := range []string{"acme/money", "acme/.Amount", "money."} {
+		_, err := parseGoType(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestImportSpec(t *testing.T) {
+	assert.Equal(t, `"time"`, importSpec("time"))
+	assert.Equal(
+		t,
+		`yaml "gopkg.in/yaml.v3"`,
+		importSpec("yaml gopkg.in/yaml.v3"),
+	)
+	assert.True(t, isStdLib("time"))
+	assert.False(t, isStdLib("yaml gopkg.in/yaml.v3"))
+}
+
+func TestConfigIncludes(t *testing.T) {
+	p := writeManifest(t, `
+[edgeql-go]
+include = ["queries/**/*.edgeql", "*.edgeql"]
+exclude = ["queries/**/skip_*.edgeql"]
+`)
+
+	cfg, err := loadConfig(p)
+	require.NoError(t, err)
+
+	for file, expected := range map[string]bool{
+		"top.edgeql":                    true,
+		"queries/a.edgeql":              true,
+		"queries/a/b/c.edgeql":          true,
+		"queries/a/skip_me.edgeql":      false,
+		"other/a.edgeql":                false,
+		"queries/a/b/c.edgeql.template": false,
+	} {
+		ok, err := cfg.includes(filepath.Join(p.RootDir, file))
+		require.NoError(t, err)
+		assert.Equal(t, expected, ok, file)
+	}
+}
+
+func TestConfigWithoutSection(t *testing.T) {
+	p := writeManifest(t, "[project]\nschema-dir = \"dbschema\"\n")
+
+	cfg, err := loadConfig(p)
+	require.NoError(t, err)
+
+	o, err := cfg.dirOptions(p.RootDir)
+	require.NoError(t, err)
+	assert.Equal(t, options{OutputSuffix: defaultOutputSuffix}, o)
+
+	ok, err := cfg.includes(filepath.Join(p.RootDir, "a", "b.edgeql"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestConfigInvalid(t *testing.T) {
+	for _, manifest := range []string{
+		"[edgeql-go]\ninclude = [\"[\"]\n",
+		"[edgeql-go]\noutput-suffix = \"_edgeql.txt\"\n",
+		"[edgeql-go.overrides.\"../other\"]\npubtypes = true\n",
+		"[edgeql-go.types.\"default::Money\"]\noptional = \"string\"\n",
+	} {
+		p := writeManifest(t, manifest)
+		_, err := loadConfig(p)
+		assert.Error(t, err, manifest)
+	}
+}
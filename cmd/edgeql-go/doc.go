@@ -23,6 +23,36 @@
 // a get_user.edgeql file, edgeql-go will create a get_user_edgeql.go file
 // with a getUser(...) and getUserJSON(...) function.
 //
+// # Argument structs
+//
+// With the -argstruct option query arguments are passed in a generated
+// struct instead of as separate function parameters. For example
+// get_user.edgeql gets a getUserArgs struct and the generated function
+// becomes getUser(ctx, client, getUserArgs{...}). Optional parameters use
+// optional types. The struct is exported with -pubtypes like the result
+// types.
+//
+// # Enums
+//
+// Schema enums are strings in generated code by default. With the
+// -typedenums option schema enums used by queries are generated as named
+// string types with a constant for each member instead. This changes the
+// types of generated struct fields and function parameters so existing
+// callers may need to be updated when the option is turned on. The types are
+// written once per package to an edgeql_enums.go file. For example
+// default::Color becomes:
+//
+//	type color string
+//
+//	const (
+//		colorRed   color = "Red"
+//		colorGreen color = "Green"
+//	)
+//
+// Enums from modules other than default are prefixed with the module name
+// and the -pubtypes option makes the types public. Each type also has an
+// optional variant, i.e. optionalColor, for optional values.
+//
 // # Install
 //
 // For go 1.24 and above:
@@ -48,5 +78,84 @@
 //
 //	edgeql-go -help
 //
+// # Watch mode
+//
+// During development edgeql-go can keep running and regenerate files as
+// they change:
+//
+//	edgeql-go -watch
+//
+// Queries are regenerated when their .edgeql file changes and all queries are
+// regenerated when a migration is added. When an .edgeql file is deleted its
+// generated file is deleted too. Query errors are printed instead of stopping
+// edgeql-go.
+//
+// # Offline generation
+//
+// edgeql-go normally connects to Gel to describe each query. Running
+//
+//	edgeql-go -lock
+//
+// also records the query descriptors in an edgeql-go.lock file in the
+// project root. When the lock file is checked in, code can be generated
+// without a server:
+//
+//	edgeql-go -offline
+//
+// After a migration, edgeql-go -verify connects to Gel and reports queries
+// whose descriptors no longer match the lock file.
+//
+// # Checking generated files
+//
+// To check that generated files are up to date without changing them, for
+// example in CI, run:
+//
+//	edgeql-go -check
+//
+// It prints a diff for every generated file that is out of date or missing,
+// lists orphaned generated files and exits with a non-zero status if there
+// are any. A generated file is orphaned when its .edgeql file was deleted or
+// is excluded by the configuration. Outside of watch mode edgeql-go doesn't
+// delete orphaned files, they have to be removed by hand. -check can be
+// combined with -offline.
+//
+// # Configuration
+//
+// Options can also be set in an [edgeql-go] section in the project's gel.toml
+// file. Flags passed on the command line take precedence over gel.toml.
+//
+//	[edgeql-go]
+//	mixedcaps = true
+//	pubfuncs = true
+//	pubtypes = true
+//	rawmessage = false
+//	argstruct = false
+//	typedenums = false
+//	include = ["**/*.edgeql"]
+//	exclude = ["legacy/**"]
+//	output-suffix = "_edgeql.go"
+//
+//	[edgeql-go.types."default::Money"]
+//	type = "github.com/shopspring/decimal.Decimal"
+//	optional = "github.com/shopspring/decimal.NullDecimal"
+//
+//	[edgeql-go.overrides."services/api"]
+//	pubtypes = false
+//	package = "queries"
+//
+// The include and exclude globs are matched against paths relative to the
+// project root, ** matches any number of directories. Overrides apply to a
+// directory and its subdirectories, overrides for deeper directories take
+// precedence. The package option sets the package name for generated files
+// instead of detecting it from the directory.
+//
+// Type mappings replace the go type generated for a schema scalar type or any
+// type extending it. The go types must implement the marshaling interfaces
+// documented in the gel package. Optional values use the optional type.
+// Packages whose name can't be taken from the last element of the import
+// path, like gopkg.in/yaml.v3, are imported with an alias.
+// Type mappings require protocol version 2.0 or later, using them with an
+// older server is an error.
+//
 // [go generate]: https://go.dev/blog/generate
 package main
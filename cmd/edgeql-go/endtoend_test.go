@@ -73,6 +73,21 @@ var tests = []struct {
 		directory:   "testdata/rawmessage",
 		args:        []string{"-rawmessage"},
 	},
+	{
+		description: "invoke edgeql-go with -argstruct",
+		directory:   "testdata/argstruct",
+		args:        []string{"-argstruct"},
+	},
+	{
+		description: "invoke edgeql-go with -argstruct and -pubtypes",
+		directory:   "testdata/argstruct-pubtypes",
+		args:        []string{"-argstruct", "-pubtypes"},
+	},
+	{
+		description: "invoke edgeql-go with -typedenums",
+		directory:   "testdata/typedenums",
+		args:        []string{"-typedenums"},
+	},
 	{
 		// in response to https://github.com/geldata/gel-go/issues/387 which
 		// was caused by a connection leak in introspection functions.
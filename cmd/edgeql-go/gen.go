@@ -19,6 +19,8 @@ package main
 import (
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/geldata/gel-go/internal/codecs"
 	"github.com/geldata/gel-go/internal/descriptor"
@@ -607,6 +609,15 @@ func generateBaseScalarV2(
 	isResult bool,
 	isField bool,
 ) ([]goType, []string, error) {
+	if t := lookupMappedType(desc, cmdCfg); t != nil {
+		name, imports, err := t.goType(required)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return []goType{&goScalar{Name: name}}, imports, nil
+	}
+
 	if desc.Type == descriptor.Scalar {
 		desc = codecs.GetScalarDescriptorV2(desc)
 	}
@@ -614,13 +625,23 @@ func generateBaseScalarV2(
 	var name string
 	var imports []string
 	if desc.Type == descriptor.Enum {
+		if !cmdCfg.typedenums {
+			if required {
+				name = "string"
+			} else {
+				name = "geltypes.OptionalStr"
+			}
+
+			return []goType{&goScalar{Name: name}}, imports, nil
+		}
+
+		enum := generateEnum(desc, cmdCfg)
 		if required {
-			name = "string"
-		} else {
-			name = "geltypes.OptionalStr"
+			return []goType{enum}, imports, nil
 		}
 
-		return []goType{&goScalar{Name: name}}, imports, nil
+		optional := &goScalar{Name: enum.OptionalName}
+		return []goType{optional, enum}, imports, nil
 	}
 
 	switch desc.ID {
@@ -636,7 +657,7 @@ func generateBaseScalarV2(
 		} else {
 			name = "geltypes.OptionalStr"
 		}
-	case codecs.JSONID:
+	case codecs.JSONID, codecs.PgJSONID:
 		if required {
 			if cmdCfg.rawmessage && isResult && isField {
 				imports = append(imports, "encoding/json")
@@ -689,20 +710,20 @@ func generateBaseScalarV2(
 		} else {
 			name = "geltypes.OptionalBool"
 		}
-	case codecs.DateTimeID:
+	case codecs.DateTimeID, codecs.PgTimestampTZID:
 		if required {
 			imports = append(imports, "time")
 			name = "time.Time"
 		} else {
 			name = "geltypes.OptionalDateTime"
 		}
-	case codecs.LocalDTID:
+	case codecs.LocalDTID, codecs.PgTimestampID:
 		if required {
 			name = "geltypes.LocalDateTime"
 		} else {
 			name = "geltypes.OptionalLocalDateTime"
 		}
-	case codecs.LocalDateID:
+	case codecs.LocalDateID, codecs.PgDateID:
 		if required {
 			name = "geltypes.LocalDate"
 		} else {
@@ -727,7 +748,7 @@ func generateBaseScalarV2(
 		} else {
 			name = "geltypes.OptionalBigInt"
 		}
-	case codecs.RelativeDurationID:
+	case codecs.RelativeDurationID, codecs.PgIntervalID:
 		if required {
 			name = "geltypes.RelativeDuration"
 		} else {
@@ -745,11 +766,119 @@ func generateBaseScalarV2(
 		} else {
 			name = "geltypes.OptionalMemory"
 		}
+	default:
+		name = extensionScalar(desc.Name, required)
 	}
 
 	return []goType{&goScalar{Name: name}}, imports, nil
 }
 
+// extensionScalar returns the go type for an extension scalar type.
+// Extension types are identified by name because they don't have well known
+// descriptor IDs.
+func extensionScalar(name string, required bool) string {
+	switch name {
+	case codecs.VectorName:
+		if required {
+			return "geltypes.Vector"
+		}
+		return "geltypes.OptionalVector"
+	case codecs.HalfVectorName:
+		if required {
+			return "geltypes.HalfVector"
+		}
+		return "geltypes.OptionalHalfVector"
+	case codecs.SparseVectorName:
+		if required {
+			return "geltypes.SparseVector"
+		}
+		return "geltypes.OptionalSparseVector"
+	case codecs.GeometryName, codecs.GeographyName:
+		if required {
+			return "geltypes.Geometry"
+		}
+		return "geltypes.OptionalGeometry"
+	default:
+		return ""
+	}
+}
+
+// lookupMappedType returns the configured type mapping for desc or its
+// closest ancestor. It returns nil if none of them are mapped.
+func lookupMappedType(desc *descriptor.V2, cmdCfg *cmdConfig) *mappedType {
+	if t, ok := cmdCfg.types[desc.Name]; ok {
+		return t
+	}
+
+	for _, ancestor := range desc.Ancestors {
+		if t, ok := cmdCfg.types[ancestor.Desc.Name]; ok {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// generateEnum returns a named go type for an enum descriptor. The type name
+// is derived from the schema name, leaving out the default module.
+func generateEnum(desc *descriptor.V2, cmdCfg *cmdConfig) *goEnum {
+	name := strings.TrimPrefix(desc.Name, "default::")
+	name = goIdentifier(strings.Split(name, "::")...)
+	optional := "Optional" + name
+	newOptional := "NewOptional" + name
+	if !cmdCfg.pubtypes {
+		name = lowerFirst(name)
+		optional = lowerFirst(optional)
+		newOptional = lowerFirst(newOptional)
+	}
+
+	enum := &goEnum{
+		Name:            name,
+		EQLName:         desc.Name,
+		OptionalName:    optional,
+		NewOptionalName: newOptional,
+	}
+
+	seen := make(map[string]bool, len(desc.Members))
+	for i, member := range desc.Members {
+		goName := name + goIdentifier(member)
+		if goName == name || seen[goName] {
+			goName = fmt.Sprintf("%sMember%d", name, i)
+		}
+		seen[goName] = true
+
+		enum.Members = append(enum.Members, goEnumMember{
+			GoName: goName,
+			Value:  member,
+		})
+	}
+
+	return enum
+}
+
+// goIdentifier joins the letters and digits in parts into a MixedCaps
+// identifier. Any other character starts a new word.
+func goIdentifier(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		words := strings.FieldsFunc(part, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			r, size := utf8.DecodeRuneInString(word)
+			b.WriteRune(unicode.ToUpper(r))
+			b.WriteString(word[size:])
+		}
+	}
+
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
 func nameFromPath(path []string) string {
 	if len(path) == 0 {
 		return ""
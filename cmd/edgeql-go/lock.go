@@ -0,0 +1,310 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/geldata/gel-go/internal"
+	gelint "github.com/geldata/gel-go/internal/client"
+)
+
+// lockFileName is the name of the lock file in the project root.
+const lockFileName = "edgeql-go.lock"
+
+// describer describes queries.
+type describer interface {
+	describe(
+		ctx context.Context,
+		cmd,
+		qryFile string,
+	) (*gelint.CommandDescription, error)
+	describeV2(
+		ctx context.Context,
+		cmd,
+		qryFile string,
+	) (*gelint.CommandDescriptionV2, error)
+}
+
+// poolDescriber describes queries using a server connection.
+type poolDescriber struct {
+	pool *gelint.Pool
+}
+
+func (d *poolDescriber) describe(
+	ctx context.Context,
+	cmd,
+	qryFile string,
+) (*gelint.CommandDescription, error) {
+	return gelint.Describe(ctx, d.pool, cmd, qryFile)
+}
+
+func (d *poolDescriber) describeV2(
+	ctx context.Context,
+	cmd,
+	qryFile string,
+) (*gelint.CommandDescriptionV2, error) {
+	return gelint.DescribeV2(ctx, d.pool, cmd, qryFile)
+}
+
+// lockFile records the descriptors of every query in a project so that code
+// can be generated without connecting to a server.
+type lockFile struct {
+	ProtocolVersion lockProtocolVersion   `json:"protocol_version"`
+	Queries         map[string]*lockEntry `json:"queries"`
+
+	mu   sync.Mutex
+	root string
+}
+
+type lockProtocolVersion struct {
+	Major uint16 `json:"major"`
+	Minor uint16 `json:"minor"`
+}
+
+type lockEntry struct {
+	// Hash is the sha256 hash of the query text.
+	Hash        string                       `json:"hash"`
+	Description *gelint.CommandDescriptionV2 `json:"description"`
+}
+
+func newLockFile(root string, version internal.ProtocolVersion) *lockFile {
+	return &lockFile{
+		ProtocolVersion: lockProtocolVersion{
+			Major: version.Major,
+			Minor: version.Minor,
+		},
+		Queries: make(map[string]*lockEntry),
+		root:    root,
+	}
+}
+
+func readLockFile(root string) (*lockFile, error) {
+	file := filepath.Join(root, lockFileName)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(
+			"%s not found, generate it by running edgeql-go -lock", file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &lockFile{root: root}
+	err = json.Unmarshal(data, lock)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+
+	if lock.Queries == nil {
+		lock.Queries = make(map[string]*lockEntry)
+	}
+
+	return lock, nil
+}
+
+func (l *lockFile) write() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+	return os.WriteFile(filepath.Join(l.root, lockFileName), data, 0644)
+}
+
+func (l *lockFile) protocolVersion() internal.ProtocolVersion {
+	return internal.ProtocolVersion{
+		Major: l.ProtocolVersion.Major,
+		Minor: l.ProtocolVersion.Minor,
+	}
+}
+
+func (l *lockFile) key(qryFile string) (string, error) {
+	rel, err := filepath.Rel(l.root, qryFile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func hashQuery(cmd string) string {
+	sum := sha256.Sum256([]byte(cmd))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (l *lockFile) add(
+	qryFile,
+	cmd string,
+	description *gelint.CommandDescriptionV2,
+) error {
+	key, err := l.key(qryFile)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Queries[key] = &lockEntry{
+		Hash:        hashQuery(cmd),
+		Description: description,
+	}
+
+	return nil
+}
+
+// describe is not supported because lock files are only written when using
+// protocol version 2.0 or later.
+func (l *lockFile) describe(
+	_ context.Context,
+	_,
+	qryFile string,
+) (*gelint.CommandDescription, error) {
+	return nil, fmt.Errorf(
+		"%s uses an unsupported protocol version %d.%d",
+		lockFileName, l.ProtocolVersion.Major, l.ProtocolVersion.Minor)
+}
+
+// describeV2 returns the recorded description for qryFile.
+func (l *lockFile) describeV2(
+	_ context.Context,
+	cmd,
+	qryFile string,
+) (*gelint.CommandDescriptionV2, error) {
+	key, err := l.key(qryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	entry, ok := l.Queries[key]
+	l.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%s is missing from %s, "+
+			"update it by running edgeql-go -lock", key, lockFileName)
+	}
+
+	if entry.Hash != hashQuery(cmd) {
+		return nil, fmt.Errorf("%s changed since %s was written, "+
+			"update it by running edgeql-go -lock", key, lockFileName)
+	}
+
+	return entry.Description, nil
+}
+
+// diff returns a description of every query that differs between l and
+// other. other is assumed to be up to date.
+func (l *lockFile) diff(other *lockFile) ([]string, error) {
+	var diffs []string
+	if l.ProtocolVersion != other.ProtocolVersion {
+		diffs = append(diffs, fmt.Sprintf(
+			"protocol version changed from %d.%d to %d.%d",
+			l.ProtocolVersion.Major,
+			l.ProtocolVersion.Minor,
+			other.ProtocolVersion.Major,
+			other.ProtocolVersion.Minor,
+		))
+	}
+
+	keys := make([]string, 0, len(other.Queries))
+	for key := range other.Queries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		entry := other.Queries[key]
+		old, ok := l.Queries[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, key+": missing from the lock file")
+		case old.Hash != entry.Hash:
+			diffs = append(diffs, key+": query text changed")
+		default:
+			equal, err := sameDescription(old.Description, entry.Description)
+			if err != nil {
+				return nil, err
+			}
+			if !equal {
+				diffs = append(diffs, key+": descriptors changed")
+			}
+		}
+	}
+
+	keys = keys[:0]
+	for key := range l.Queries {
+		if _, ok := other.Queries[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		diffs = append(diffs, key+": query file no longer exists")
+	}
+
+	return diffs, nil
+}
+
+func sameDescription(a, b *gelint.CommandDescriptionV2) (bool, error) {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(left, right), nil
+}
+
+// lockRecorder records the descriptions returned by a describer.
+type lockRecorder struct {
+	describer
+	lock *lockFile
+}
+
+func (r *lockRecorder) describeV2(
+	ctx context.Context,
+	cmd,
+	qryFile string,
+) (*gelint.CommandDescriptionV2, error) {
+	description, err := r.describer.describeV2(ctx, cmd, qryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.lock.add(qryFile, cmd, description)
+	if err != nil {
+		return nil, err
+	}
+
+	return description, nil
+}
@@ -0,0 +1,119 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal"
+	gelint "github.com/geldata/gel-go/internal/client"
+	"github.com/geldata/gel-go/internal/codecs"
+	"github.com/geldata/gel-go/internal/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFileRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	qryFile := filepath.Join(root, "queries", "get_color.edgeql")
+	cmd := "select <Color>$color"
+	description := &gelint.CommandDescriptionV2{
+		In: descriptor.V2{
+			Type: descriptor.Object,
+			ID:   geltypes.UUID{1},
+			Fields: []*descriptor.FieldV2{{
+				Name:     "color",
+				Required: true,
+				Desc: descriptor.V2{
+					Type:          descriptor.Enum,
+					ID:            geltypes.UUID{2},
+					Name:          "default::Color",
+					SchemaDefined: true,
+					Members:       []string{"Red", "Green"},
+				},
+			}},
+		},
+		Out: descriptor.V2{
+			Type: descriptor.Scalar,
+			ID:   codecs.StrID,
+			Name: "std::str",
+		},
+		Card: gelint.One,
+	}
+
+	lock := newLockFile(root, internal.ProtocolVersion{Major: 3})
+	require.NoError(t, lock.add(qryFile, cmd, description))
+	require.NoError(t, lock.write())
+
+	read, err := readLockFile(root)
+	require.NoError(t, err)
+	assert.Equal(t, internal.ProtocolVersion{Major: 3}, read.protocolVersion())
+
+	ctx := context.Background()
+	result, err := read.describeV2(ctx, cmd, qryFile)
+	require.NoError(t, err)
+	assert.Equal(t, description, result)
+
+	_, err = read.describeV2(ctx, "select 1", qryFile)
+	assert.EqualError(t, err, "queries/get_color.edgeql changed since "+
+		"edgeql-go.lock was written, update it by running edgeql-go -lock")
+
+	other := filepath.Join(root, "other.edgeql")
+	_, err = read.describeV2(ctx, cmd, other)
+	assert.EqualError(t, err, "other.edgeql is missing from edgeql-go.lock, "+
+		"update it by running edgeql-go -lock")
+}
+
+func TestLockFileDiff(t *testing.T) {
+	root := t.TempDir()
+	description := func(card gelint.Cardinality) *gelint.CommandDescriptionV2 {
+		return &gelint.CommandDescriptionV2{
+			In:   descriptor.V2{Type: descriptor.Tuple, ID: descriptor.IDZero},
+			Out:  descriptor.V2{Type: descriptor.Scalar, ID: codecs.Int64ID},
+			Card: card,
+		}
+	}
+	file := func(name string) string { return filepath.Join(root, name) }
+
+	old := newLockFile(root, internal.ProtocolVersion{Major: 2})
+	require.NoError(t, old.add(file("a.edgeql"), "select 1", description(1)))
+	require.NoError(t, old.add(file("b.edgeql"), "select 2", description(1)))
+	require.NoError(t, old.add(file("c.edgeql"), "select 3", description(1)))
+	require.NoError(t, old.add(file("d.edgeql"), "select 4", description(1)))
+
+	cur := newLockFile(root, internal.ProtocolVersion{Major: 2})
+	require.NoError(t, cur.add(file("a.edgeql"), "select 1", description(1)))
+	require.NoError(t, cur.add(file("b.edgeql"), "select 2", description(2)))
+	require.NoError(t, cur.add(file("c.edgeql"), "select 33", description(1)))
+	require.NoError(t, cur.add(file("e.edgeql"), "select 5", description(1)))
+
+	diffs, err := old.diff(cur)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"b.edgeql: descriptors changed",
+		"c.edgeql: query text changed",
+		"e.edgeql: missing from the lock file",
+		"d.edgeql: query file no longer exists",
+	}, diffs)
+
+	diffs, err = old.diff(old)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
@@ -28,8 +28,8 @@ import (
 	"io/fs"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -40,7 +40,7 @@ import (
 	"github.com/geldata/gel-go/internal"
 	gelint "github.com/geldata/gel-go/internal/client"
 	"github.com/geldata/gel-go/internal/descriptor"
-	toml "github.com/pelletier/go-toml/v2"
+	"github.com/geldata/gel-go/internal/project"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -52,6 +52,15 @@ var (
 	packageNames sync.Map
 )
 
+const (
+	// enumsFile is the name of the file that holds the enum types used by
+	// the queries in a package.
+	enumsFile = "edgeql_enums.go"
+
+	generatedHeader = "// Code generated by " +
+		"github.com/geldata/gel-go/cmd/edgeql-go DO NOT EDIT."
+)
+
 func usage() {
 	_, _ = fmt.Fprintf(flag.CommandLine.Output(), ""+
 		"Generate go functions from edgeql files.\n"+
@@ -67,9 +76,45 @@ type cmdConfig struct {
 	pubfuncs        bool
 	pubtypes        bool
 	rawmessage      bool
+	argstruct       bool
+	typedenums      bool
+	types           map[string]*mappedType
 	protocolVersion internal.ProtocolVersion
 }
 
+func newCmdConfig(
+	o options,
+	protocolVersion internal.ProtocolVersion,
+) (*cmdConfig, error) {
+	if len(o.Types) > 0 &&
+		protocolVersion.LT(internal.ProtocolVersion{Major: 2}) {
+		return nil, errors.New(
+			"type mappings require protocol version 2.0 or later")
+	}
+
+	types := make(map[string]*mappedType, len(o.Types))
+	for name, mapping := range o.Types {
+		t, err := newMappedType(name, mapping)
+		if err != nil {
+			return nil, fmt.Errorf("type mapping for %s: %w", name, err)
+		}
+		types[name] = t
+	}
+
+	return &cmdConfig{
+		mixedCaps:       isSet(o.MixedCaps),
+		pubfuncs:        isSet(o.PubFuncs),
+		pubtypes:        isSet(o.PubTypes),
+		rawmessage:      isSet(o.RawMessage),
+		argstruct:       isSet(o.ArgStruct),
+		typedenums:      isSet(o.TypedEnums),
+		types:           types,
+		protocolVersion: protocolVersion,
+	}, nil
+}
+
+func isSet(b *bool) bool { return b != nil && *b }
+
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("edgeql-go: ")
@@ -84,15 +129,244 @@ func main() {
 		"Make generated types public.")
 	rawmessage := flag.Bool("rawmessage", false,
 		"Use json.RawMessage instead of []bytes in struct fields")
+	argstruct := flag.Bool("argstruct", false,
+		"Pass query arguments in a struct instead of as separate "+
+			"function parameters.")
+	typedenums := flag.Bool("typedenums", false,
+		"Generate named go types for schema enums instead of strings.")
+	lock := flag.Bool("lock", false,
+		"Record query descriptors in "+lockFileName+
+			" for use with -offline.")
+	offline := flag.Bool("offline", false,
+		"Generate code from "+lockFileName+
+			" without connecting to Gel.")
+	verify := flag.Bool("verify", false,
+		"Report queries whose descriptors differ from "+lockFileName+
+			" without generating code.")
+	check := flag.Bool("check", false,
+		"Report generated files that are out of date "+
+			"without changing them.")
+	watch := flag.Bool("watch", false,
+		"Regenerate files when .edgeql files or migrations change.")
 	flag.Parse()
 
-	cfg := &cmdConfig{
-		mixedCaps:  *mixedCaps,
-		pubfuncs:   *pubfuncs,
-		pubtypes:   *pubtypes,
-		rawmessage: *rawmessage,
+	if countTrue(*lock, *offline, *verify) > 1 {
+		log.Fatal("only one of -lock, -offline and -verify can be used")
+	}
+
+	if *check && (*lock || *verify) {
+		log.Fatal("-check can not be used with -lock or -verify")
+	}
+
+	if *watch && countTrue(*lock, *offline, *verify, *check) > 0 {
+		log.Fatal("-watch can not be used with " +
+			"-lock, -offline, -verify or -check")
+	}
+
+	// Flags that are set explicitly take precedence over gel.toml.
+	var flagOptions options
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "mixedcaps":
+			flagOptions.MixedCaps = mixedCaps
+		case "pubfuncs":
+			flagOptions.PubFuncs = pubfuncs
+		case "pubtypes":
+			flagOptions.PubTypes = pubtypes
+		case "rawmessage":
+			flagOptions.RawMessage = rawmessage
+		case "argstruct":
+			flagOptions.ArgStruct = argstruct
+		case "typedenums":
+			flagOptions.TypedEnums = typedenums
+		}
+	})
+
+	p, err := project.Find(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg, err := loadConfig(p)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	var d describer
+	var protocolVersion internal.ProtocolVersion
+	var recorder *lockRecorder
+	if *offline {
+		lockFile, e := readLockFile(p.RootDir)
+		if e != nil {
+			log.Fatal(e)
+		}
+		d = lockFile
+		protocolVersion = lockFile.protocolVersion()
+	} else {
+		var c *gelint.Pool
+		c, protocolVersion = connect(ctx)
+		d = &poolDescriber{pool: c}
+	}
+
+	if *lock || *verify {
+		if protocolVersion.LT(internal.ProtocolVersion{Major: 2}) {
+			log.Fatalf("%s requires protocol version 2.0 or later",
+				lockFileName)
+		}
+		recorder = &lockRecorder{
+			describer: d,
+			lock:      newLockFile(p.RootDir, protocolVersion),
+		}
+		d = recorder
+	}
+
+	fileQueue := queueFilesInBackground(p, cfg)
+	if *verify {
+		verifyLockFile(ctx, fileQueue, recorder)
+		return
+	}
+
+	t, err := template.New("files.template").
+		Funcs(map[string]any{
+			"isStdLib":   isStdLib,
+			"importSpec": importSpec,
+		}).
+		ParseFS(templates, "templates/*.template")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := &generator{
+		project:         p,
+		cfg:             cfg,
+		flagOptions:     flagOptions,
+		describer:       d,
+		protocolVersion: protocolVersion,
+		templates:       t,
+		out:             diskOutput{},
+	}
+
+	var checker *checkOutput
+	if *check {
+		checker = newCheckOutput(p.RootDir)
+		g.out = checker
+	}
+
+	if *watch {
+		g.generateAll(ctx, fileQueue, func(e error) { log.Print(e) })
+		g.watch(ctx)
+		return
+	}
+
+	g.generateAll(ctx, fileQueue, func(e error) { log.Fatal(e) })
+
+	if *check {
+		e := findOrphans(p, cfg, checker)
+		if e != nil {
+			log.Fatal(e)
+		}
+
+		n, e := checker.print(os.Stdout)
+		if e != nil {
+			log.Fatal(e)
+		}
+		if n > 0 {
+			log.Fatalf("%d generated files are out of date, "+
+				"run edgeql-go to update them", n)
+		}
+	}
+
+	if *lock {
+		err = recorder.lock.write()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// generator generates go files for queries.
+type generator struct {
+	project         *project.Project
+	cfg             *config
+	flagOptions     options
+	describer       describer
+	protocolVersion internal.ProtocolVersion
+	templates       *template.Template
+	out             output
+	enums           packageEnums
+}
+
+// generateAll generates the go files for every query in files. The shared
+// enums files are updated afterwards.
+// onError is called with every error that occurs.
+func (g *generator) generateAll(
+	ctx context.Context,
+	files <-chan string,
+	onError func(error),
+) {
+	var wg sync.WaitGroup
+	for queryFile := range files {
+		wg.Add(1)
+		go func(queryFile string) {
+			defer wg.Done()
+			e := g.generate(ctx, queryFile)
+			if e != nil {
+				onError(e)
+			}
+		}(queryFile)
 	}
+	wg.Wait()
+
+	err := g.enums.write(g.templates, g.out)
+	if err != nil {
+		onError(err)
+	}
+}
+
+// generate generates the go file for queryFile.
+func (g *generator) generate(ctx context.Context, queryFile string) error {
+	o, err := g.cfg.dirOptions(filepath.Dir(queryFile))
+	if err != nil {
+		return fmt.Errorf("processing %s: %s", queryFile, err)
+	}
+
+	cmdCfg, err := newCmdConfig(o.merge(g.flagOptions), g.protocolVersion)
+	if err != nil {
+		return fmt.Errorf("processing %s: %s", queryFile, err)
+	}
+
+	outFile := getOutFile(queryFile, o.OutputSuffix)
+	q, err := newQuery(ctx, g.describer, queryFile, outFile, cmdCfg)
+	if err != nil {
+		return err
+	}
+
+	err = writeGoFile(g.templates, g.out, outFile, []*Query{q})
+	if err != nil {
+		return fmt.Errorf("processing %s: %s", queryFile, err)
+	}
+
+	err = g.enums.set(queryFile, q.enums)
+	if err != nil {
+		return fmt.Errorf("processing %s: %s", queryFile, err)
+	}
+
+	return nil
+}
+
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
 
+func connect(ctx context.Context) (*gelint.Pool, internal.ProtocolVersion) {
 	c, err := gelint.NewPool("", gelcfg.Options{})
 	if err != nil {
 		log.Fatalf("creating client: %s", err) // nolint:gocritic
@@ -102,7 +376,6 @@ func main() {
 		log.Println("connecting to Gel ...")
 	})
 
-	ctx := context.Background()
 	err = c.EnsureConnected(ctx)
 	if err != nil {
 		log.Fatalf("connecting to Gel: %v", err)
@@ -112,16 +385,22 @@ func main() {
 		log.Println("connected")
 	}
 
-	cfg.protocolVersion, err = gelint.ProtocolVersion(ctx, c)
+	protocolVersion, err := gelint.ProtocolVersion(ctx, c)
 	if err != nil {
 		log.Fatalf("error determining the protocol version: %s", err)
 	}
 
-	fileQueue := queueFilesInBackground()
+	return c, protocolVersion
+}
 
-	t, err := template.New("files.template").
-		Funcs(map[string]any{"hasPrefix": strings.HasPrefix}).
-		ParseFS(templates, "templates/*.template")
+// verifyLockFile describes every query and reports the queries that differ
+// from the lock file. It exits with a non-zero status if any differ.
+func verifyLockFile(
+	ctx context.Context,
+	fileQueue chan string,
+	recorder *lockRecorder,
+) {
+	old, err := readLockFile(recorder.lock.root)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -131,123 +410,186 @@ func main() {
 		wg.Add(1)
 		go func(queryFile string) {
 			defer wg.Done()
-			outFile := getOutFile(queryFile)
-			q, e := newQuery(ctx, c, queryFile, outFile, cfg)
+			cmd, e := os.ReadFile(queryFile)
 			if e != nil {
 				log.Fatalf("processing %s: %s", queryFile, e)
 			}
 
-			e = writeGoFile(t, outFile, []*Query{q})
+			_, e = recorder.describeV2(ctx, string(cmd), queryFile)
 			if e != nil {
 				log.Fatalf("processing %s: %s", queryFile, e)
 			}
 		}(queryFile)
 	}
 	wg.Wait()
-}
 
-func isEdgeDBTOML(file string) (bool, error) {
-	info, err := os.Stat(file)
-	if err == nil {
-		if info.Mode() == fs.ModeDir {
-			return false, fmt.Errorf(
-				"expected %q to be a file not a directory",
-				file,
-			)
-		}
-		return true, nil
+	diffs, err := old.diff(recorder.lock)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if errors.Is(err, os.ErrNotExist) {
-		return false, nil
+	if len(diffs) == 0 {
+		return
 	}
 
-	return false, err
+	for _, diff := range diffs {
+		log.Println(diff)
+	}
+	log.Fatalf("%s is out of date, update it by running edgeql-go -lock",
+		lockFileName)
 }
 
-type project struct {
-	rootDir       string
-	migrationsDir string
+// packageEnums collects the enum types used by queries so that each type is
+// written only once per package.
+type packageEnums struct {
+	mu sync.Mutex
+
+	// queries maps directory names to the enum types used by each query
+	// file in the directory.
+	queries map[string]map[string][]*goEnum
 }
 
-func getProject() (*project, error) {
-	dir, err := filepath.Abs(".")
+// set records the enum types used by queryFile.
+func (p *packageEnums) set(queryFile string, enums []*goEnum) error {
+	dirname, err := getDirName(queryFile)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	for {
-		parent := filepath.Dir(dir)
-		if dir == parent {
-			return nil, fmt.Errorf(
-				"could not find gel.toml, " +
-					"fix this by initializing a project, run: " +
-					" gel project init",
-			)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.queries == nil {
+		p.queries = make(map[string]map[string][]*goEnum)
+	}
+
+	queries, ok := p.queries[dirname]
+	if !ok {
+		queries = make(map[string][]*goEnum)
+		p.queries[dirname] = queries
+	}
+	queries[queryFile] = enums
+
+	return nil
+}
+
+// remove forgets the enum types used by queryFile.
+func (p *packageEnums) remove(queryFile string) error {
+	dirname, err := getDirName(queryFile)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.queries[dirname], queryFile)
+
+	return nil
+}
+
+// write writes the enum types for each package to a shared file. The file is
+// removed from packages that no longer use any enums. If dirnames is empty
+// every package is written.
+func (p *packageEnums) write(
+	t *template.Template,
+	out output,
+	dirnames ...string,
+) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(dirnames) == 0 {
+		for dirname := range p.queries {
+			dirnames = append(dirnames, dirname)
 		}
+	}
 
-		file := filepath.Join(dir, "gel.toml")
-		isTOML, err := isEdgeDBTOML(file)
+	for _, dirname := range dirnames {
+		err := p.writeDir(t, out, dirname)
 		if err != nil {
-			return nil, err
+			return err
 		}
+	}
 
-		if !isTOML {
-			file = filepath.Join(dir, "edgedb.toml")
-			isTOML, err = isEdgeDBTOML(file)
-			if err != nil {
-				return nil, err
-			}
+	return nil
+}
+
+func (p *packageEnums) writeDir(
+	t *template.Template,
+	out output,
+	dirname string,
+) error {
+	known := make(map[string]*goEnum)
+	for _, enums := range p.queries[dirname] {
+		for _, enum := range enums {
+			known[enum.EQLName] = enum
 		}
+	}
 
-		if isTOML {
-			data, err := os.ReadFile(file)
-			if err != nil {
-				return nil, err
-			}
+	outFile := filepath.Join(dirname, enumsFile)
+	if len(known) == 0 {
+		return removeGeneratedFile(out, outFile)
+	}
 
-			var x struct {
-				Project struct {
-					SchemaDir string `toml:"schema-dir"`
-				}
-			}
-			x.Project.SchemaDir = "dbschema"
-			err = toml.Unmarshal(data, &x)
-			if err != nil {
-				return nil, err
-			}
+	enums := make([]*goEnum, 0, len(known))
+	for _, enum := range known {
+		enums = append(enums, enum)
+	}
+	slices.SortFunc(enums, func(a, b *goEnum) int {
+		return strings.Compare(a.Name, b.Name)
+	})
 
-			return &project{
-				rootDir: dir,
-				migrationsDir: filepath.Join(
-					dir, x.Project.SchemaDir, "migrations"),
-			}, nil
+	for i := 1; i < len(enums); i++ {
+		if enums[i].Name == enums[i-1].Name {
+			return fmt.Errorf(
+				"enums %s and %s both map to the go type %s in %s",
+				enums[i-1].EQLName,
+				enums[i].EQLName,
+				enums[i].Name,
+				dirname,
+			)
 		}
-		dir = parent
 	}
-}
 
-func queueFilesInBackground() chan string {
-	queue := make(chan string)
-	p, err := getProject()
+	packageName, ok := packageNames.Load(dirname)
+	if !ok {
+		return fmt.Errorf("no package name found for %q", outFile)
+	}
+
+	var buf bytes.Buffer
+	err := t.ExecuteTemplate(&buf, "enums.template", map[string]any{
+		"PackageName": packageName,
+		"Enums":       enums,
+	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
+	return writeFormatted(out, outFile, buf.Bytes())
+}
+
+func queueFilesInBackground(p *project.Project, cfg *config) chan string {
+	queue := make(chan string)
+
 	go func() {
 		er := filepath.WalkDir(
-			p.rootDir,
+			p.RootDir,
 			func(f string, d fs.DirEntry, e error) error {
 				if e != nil {
 					return e
 				}
 
 				if d.IsDir() &&
-					f == p.migrationsDir {
+					f == p.MigrationsDir {
 					return fs.SkipDir
 				}
 
 				if !d.IsDir() && strings.HasSuffix(f, ".edgeql") {
+					ok, err := cfg.includes(f)
+					if err != nil || !ok {
+						return err
+					}
+
 					dirname, err := getDirName(f)
 					if err != nil {
 						return err
@@ -260,12 +602,9 @@ func queueFilesInBackground() chan string {
 					// condition where an empty or partially written file will
 					// cause getPackageName to return an error because the file
 					// is malformed.
-					if _, ok := packageNames.Load(dirname); !ok {
-						packageName, err := getPackageName(dirname)
-						if err != nil {
-							return err
-						}
-						packageNames.Store(dirname, packageName)
+					err = cachePackageName(cfg, dirname)
+					if err != nil {
+						return err
 					}
 					queue <- f
 				}
@@ -283,8 +622,33 @@ func queueFilesInBackground() chan string {
 	return queue
 }
 
+// cachePackageName looks up the package name for dirname unless it is
+// already known.
+func cachePackageName(cfg *config, dirname string) error {
+	if _, ok := packageNames.Load(dirname); ok {
+		return nil
+	}
+
+	o, err := cfg.dirOptions(dirname)
+	if err != nil {
+		return err
+	}
+
+	packageName := o.Package
+	if packageName == "" {
+		packageName, err = getPackageName(dirname)
+		if err != nil {
+			return err
+		}
+	}
+	packageNames.Store(dirname, packageName)
+
+	return nil
+}
+
 func writeGoFile(
 	t *template.Template,
+	out output,
 	outFile string,
 	queries []*Query,
 ) error {
@@ -313,19 +677,7 @@ func writeGoFile(
 		return err
 	}
 
-	err = os.WriteFile(outFile, buf.Bytes(), 0644)
-	if err != nil {
-		return err
-	}
-
-	cmd := exec.Command("gofmt", "-s", "-w", outFile)
-	cmd.Stderr = os.Stderr
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("formatting %s: %w", outFile, err)
-	}
-
-	return nil
+	return writeFormatted(out, outFile, buf.Bytes())
 }
 
 func getDirName(file string) (string, error) {
@@ -380,13 +732,35 @@ func getPackageName(dirname string) (string, error) {
 	), nil
 }
 
-func getOutFile(queryFile string) string {
+func getOutFile(queryFile, suffix string) string {
 	base := filepath.Base(queryFile)
 	base = strings.TrimSuffix(base, ".edgeql")
-	base += "_edgeql.go"
+	base += suffix
 	return filepath.Join(filepath.Dir(queryFile), base)
 }
 
+// isStdLib returns true if importPath is in the standard library.
+// importPath may be prefixed with an alias as returned by
+// [mappedType.goType].
+func isStdLib(importPath string) bool {
+	if _, p, ok := strings.Cut(importPath, " "); ok {
+		importPath = p
+	}
+
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// importSpec returns the import declaration for importPath which may be
+// prefixed with an alias.
+func importSpec(importPath string) string {
+	if alias, p, ok := strings.Cut(importPath, " "); ok {
+		return alias + " " + strconv.Quote(p)
+	}
+
+	return strconv.Quote(importPath)
+}
+
 func isNumberedArgs(desc descriptor.Descriptor) bool {
 	if len(desc.Fields) == 0 {
 		return false
@@ -0,0 +1,228 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/geldata/gel-go/internal/project"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// output receives generated files.
+type output interface {
+	writeFile(file string, data []byte) error
+	removeFile(file string) error
+}
+
+// diskOutput writes generated files to disk. Files that are already up to
+// date are not written so that their modification time does not change.
+type diskOutput struct{}
+
+func (diskOutput) writeFile(file string, data []byte) error {
+	current, err := os.ReadFile(file)
+	if err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+func (diskOutput) removeFile(file string) error { return os.Remove(file) }
+
+// checkOutput compares generated files to the files on disk without changing
+// them.
+type checkOutput struct {
+	mu       sync.Mutex
+	root     string
+	problems map[string]string
+}
+
+func newCheckOutput(root string) *checkOutput {
+	return &checkOutput{root: root, problems: make(map[string]string)}
+}
+
+func (o *checkOutput) writeFile(file string, data []byte) error {
+	name := o.name(file)
+	current, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		o.report(file, name+" is missing\n")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(current, data) {
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(current),
+		B:        splitLines(data),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+
+	o.report(file, diff)
+	return nil
+}
+
+// splitLines splits data after each newline.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func (o *checkOutput) removeFile(file string) error {
+	o.report(file, o.name(file)+" is orphaned and should be removed\n")
+	return nil
+}
+
+func (o *checkOutput) name(file string) string {
+	rel, err := filepath.Rel(o.root, file)
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (o *checkOutput) report(file, problem string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.problems[file] = problem
+}
+
+// print writes the problems sorted by file name and returns the number of
+// problems.
+func (o *checkOutput) print(w io.Writer) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	files := make([]string, 0, len(o.problems))
+	for file := range o.problems {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		_, err := io.WriteString(w, o.problems[file])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(files), nil
+}
+
+// writeFormatted formats data with gofmt and writes it to outFile.
+func writeFormatted(out output, outFile string, data []byte) error {
+	var stdout bytes.Buffer
+	cmd := exec.Command("gofmt", "-s")
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("formatting %s: %w", outFile, err)
+	}
+
+	return out.writeFile(outFile, stdout.Bytes())
+}
+
+// removeGeneratedFile removes file if it exists and was written by
+// edgeql-go.
+func removeGeneratedFile(out output, file string) error {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(data, []byte(generatedHeader)) {
+		return nil
+	}
+
+	return out.removeFile(file)
+}
+
+// findOrphans reports generated query files whose .edgeql file no longer
+// exists or is excluded by the configuration. Orphans are only listed by
+// -check, watch mode deletes the generated file when it sees its .edgeql file
+// being deleted.
+func findOrphans(p *project.Project, cfg *config, out *checkOutput) error {
+	return filepath.WalkDir(
+		p.RootDir,
+		func(f string, d fs.DirEntry, e error) error {
+			if e != nil {
+				return e
+			}
+
+			if d.IsDir() {
+				if f == p.MigrationsDir {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			if d.Name() == enumsFile || !strings.HasSuffix(f, ".go") {
+				return nil
+			}
+
+			o, err := cfg.dirOptions(filepath.Dir(f))
+			if err != nil {
+				return err
+			}
+
+			if !strings.HasSuffix(f, o.OutputSuffix) {
+				return nil
+			}
+
+			queryFile := strings.TrimSuffix(f, o.OutputSuffix) + ".edgeql"
+			_, err = os.Stat(queryFile)
+			if err == nil {
+				included, err := cfg.includes(queryFile)
+				if err != nil || included {
+					return err
+				}
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			return removeGeneratedFile(out, f)
+		},
+	)
+}
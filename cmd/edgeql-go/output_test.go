@@ -0,0 +1,91 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOutput(t *testing.T) {
+	root := t.TempDir()
+	same := filepath.Join(root, "same_edgeql.go")
+	changed := filepath.Join(root, "changed_edgeql.go")
+	missing := filepath.Join(root, "missing_edgeql.go")
+	require.NoError(t, os.WriteFile(same, []byte("a\n"), 0o600))
+	require.NoError(t, os.WriteFile(changed, []byte("a\nb\nc\n"), 0o600))
+
+	out := newCheckOutput(root)
+	require.NoError(t, out.writeFile(same, []byte("a\n")))
+	require.NoError(t, out.writeFile(changed, []byte("a\nx\nc\n")))
+	require.NoError(t, out.writeFile(missing, []byte("a\n")))
+	require.NoError(t, out.removeFile(filepath.Join(root, "old_edgeql.go")))
+
+	var buf strings.Builder
+	n, err := out.print(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, `--- a/changed_edgeql.go
++++ b/changed_edgeql.go
+@@ -1,3 +1,3 @@
+ a
+-b
++x
+ c
+missing_edgeql.go is missing
+old_edgeql.go is orphaned and should be removed
+`, buf.String())
+
+	data, err := os.ReadFile(changed)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\n", string(data))
+}
+
+func TestFindOrphans(t *testing.T) {
+	p := writeManifest(t, "[edgeql-go]\nexclude = [\"skip/**\"]\n")
+	write := func(name, content string) {
+		file := filepath.Join(p.RootDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o700))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+	generated := generatedHeader + "\n\npackage x\n"
+	write("q/kept.edgeql", "select 1")
+	write("q/kept_edgeql.go", generated)
+	write("q/gone_edgeql.go", generated)
+	write("q/handwritten_edgeql.go", "package x\n")
+	write("skip/excluded.edgeql", "select 1")
+	write("skip/excluded_edgeql.go", generated)
+	write("q/"+enumsFile, generated)
+
+	cfg, err := loadConfig(p)
+	require.NoError(t, err)
+
+	out := newCheckOutput(p.RootDir)
+	require.NoError(t, findOrphans(p, cfg, out))
+
+	var buf strings.Builder
+	_, err = out.print(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "q/gone_edgeql.go is orphaned and should be removed\n"+
+		"skip/excluded_edgeql.go is orphaned and should be removed\n",
+		buf.String())
+}
@@ -21,9 +21,9 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/geldata/gel-go/geltypes"
@@ -46,6 +46,7 @@ type queryConfig struct {
 	structs []*goStruct
 	sTypes  *goStruct
 	rTypes  []goType
+	enums   []*goEnum
 }
 
 type queryConfigV1 struct{}
@@ -59,13 +60,13 @@ type querySetup interface {
 		qryFile,
 		outFile string,
 		cfg *cmdConfig,
-		c *gelint.Pool,
+		d describer,
 	) (*queryConfig, error)
 }
 
 func newQuery(
 	ctx context.Context,
-	p *gelint.Pool,
+	d describer,
 	qryFile,
 	outFile string,
 	cfg *cmdConfig,
@@ -78,7 +79,7 @@ func newQuery(
 
 	queryBytes, err := os.ReadFile(qryFile)
 	if err != nil {
-		log.Fatalf("error reading %q: %s", qryFile, err)
+		return nil, fmt.Errorf("error reading %q: %w", qryFile, err)
 	}
 
 	var qs querySetup
@@ -89,13 +90,14 @@ func newQuery(
 		qs = &queryConfigV1{}
 	}
 
-	q, err := qs.setup(ctx, string(queryBytes), qryFile, outFile, cfg, p)
+	q, err := qs.setup(ctx, string(queryBytes), qryFile, outFile, cfg, d)
 	if err != nil {
-		log.Fatalf("failed to setup query: %s", err)
+		return nil, fmt.Errorf("failed to setup query: %w", err)
 	}
 
-	return &Query{
+	query := &Query{
 		imports:             q.imports,
+		enums:               q.enums,
 		QueryFile:           q.file,
 		QueryName:           q.name,
 		CMDVarName:          cmdVarName(qryFile),
@@ -103,7 +105,65 @@ func newQuery(
 		SignatureReturnType: q.rTypes[0].Reference(),
 		SignatureArgs:       q.sTypes.Fields,
 		Method:              q.method,
-	}, nil
+	}
+
+	if cfg.argstruct && len(query.SignatureArgs) > 0 {
+		query.ArgsStruct = newArgsStruct(
+			typeName(qryFile, cfg),
+			q.name,
+			q.sTypes.Fields,
+			parameterCasts(string(queryBytes)),
+		)
+	}
+
+	return query, nil
+}
+
+// parameterRegex matches parameter casts like <optional array<str>>$name.
+var parameterRegex = regexp.MustCompile(
+	`<((?:[^<>]|<(?:[^<>]|<[^<>]*>)*>)*)>\s*\$([A-Za-z_][A-Za-z0-9_]*)`,
+)
+
+// parameterCasts maps parameter names to their cast in cmd, i.e.
+// <optional str>$name.
+func parameterCasts(cmd string) map[string]string {
+	casts := make(map[string]string)
+	for _, match := range parameterRegex.FindAllStringSubmatch(cmd, -1) {
+		name := match[2]
+		if _, ok := casts[name]; !ok {
+			cast := strings.Join(strings.Fields(match[1]), " ")
+			casts[name] = fmt.Sprintf("<%s>$%s", cast, name)
+		}
+	}
+	return casts
+}
+
+// newArgsStruct returns the arguments struct for a query. Like the result
+// types its name is exported with -pubtypes.
+func newArgsStruct(
+	typeName,
+	queryName string,
+	fields []goStructField,
+	casts map[string]string,
+) *argsStruct {
+	args := &argsStruct{
+		Name:          typeName + "Args",
+		QueryFuncName: queryName,
+	}
+
+	for _, field := range fields {
+		arg := argsField{
+			EQLName: field.EQLName,
+			GoName:  snakeToUpperMixedCase(field.EQLName),
+			Type:    field.Type,
+		}
+		if cast, ok := casts[field.EQLName]; ok {
+			arg.Doc = fmt.Sprintf("%s is passed as %s.", arg.GoName, cast)
+		}
+		args.Fields = append(args.Fields, arg)
+	}
+
+	return args
 }
 
 func (r *queryConfigV1) setup(
@@ -112,9 +172,9 @@ func (r *queryConfigV1) setup(
 	qryFile,
 	outFile string,
 	cmdCfg *cmdConfig,
-	p *gelint.Pool,
+	d describer,
 ) (*queryConfig, error) {
-	description, err := gelint.Describe(ctx, p, cmd, qryFile)
+	description, err := d.describe(ctx, cmd, qryFile)
 
 	if err != nil {
 		return nil, fmt.Errorf("error introspecting query %q: %s", qryFile,
@@ -128,7 +188,7 @@ func (r *queryConfigV1) setup(
 	qryName := queryName(qryFile, cmdCfg)
 	rTypes, imports, err := resultTypes(qryFile, description, cmdCfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	var rStructs []*goStruct
 	for _, typ := range rTypes {
@@ -140,18 +200,18 @@ func (r *queryConfigV1) setup(
 
 	sTypes, i, err := signatureTypes(description, cmdCfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	imports = append(imports, i...)
 
 	qryFile, err = queryFile(outFile, qryFile)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	m, err := method(description)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	return &queryConfig{
@@ -171,9 +231,9 @@ func (r *queryConfigV2) setup(
 	qryFile,
 	outFile string,
 	cmdCfg *cmdConfig,
-	p *gelint.Pool,
+	d describer,
 ) (*queryConfig, error) {
-	description, err := gelint.DescribeV2(ctx, p, cmd, qryFile)
+	description, err := d.describeV2(ctx, cmd, qryFile)
 
 	if err != nil {
 		return nil, fmt.Errorf("error introspecting query %q: %s", qryFile,
@@ -187,30 +247,35 @@ func (r *queryConfigV2) setup(
 	qryName := queryName(qryFile, cmdCfg)
 	rTypes, imports, err := resultTypesV2(qryFile, description, cmdCfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	var rStructs []*goStruct
+	var enums []*goEnum
 	for _, typ := range rTypes {
-		if t, ok := typ.(*goStruct); ok {
+		switch t := typ.(type) {
+		case *goStruct:
 			t.QueryFuncName = qryName
 			rStructs = append(rStructs, t)
+		case *goEnum:
+			enums = append(enums, t)
 		}
 	}
 
-	sTypes, i, err := signatureTypesV2(description, cmdCfg)
+	sTypes, e, i, err := signatureTypesV2(description, cmdCfg)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	imports = append(imports, i...)
+	enums = append(enums, e...)
 
 	qryFile, err = queryFile(outFile, qryFile)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	m, err := methodV2(description)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	return &queryConfig{
@@ -221,6 +286,7 @@ func (r *queryConfigV2) setup(
 		structs: rStructs,
 		sTypes:  sTypes,
 		rTypes:  rTypes,
+		enums:   enums,
 	}, nil
 }
 
@@ -275,7 +341,7 @@ func signatureTypes(
 func signatureTypesV2(
 	description *gelint.CommandDescriptionV2,
 	cmdCfg *cmdConfig,
-) (*goStruct, []string, error) {
+) (*goStruct, []*goEnum, []string, error) {
 	types, imports, err := generateTypeV2(
 		&description.In,
 		true,
@@ -285,10 +351,17 @@ func signatureTypesV2(
 		false,
 	)
 	if err != nil {
-		return &goStruct{}, nil, err
+		return &goStruct{}, nil, nil, err
 	}
 
-	return types[0].(*goStruct), imports, nil
+	var enums []*goEnum
+	for _, typ := range types {
+		if t, ok := typ.(*goEnum); ok {
+			enums = append(enums, t)
+		}
+	}
+
+	return types[0].(*goStruct), enums, imports, nil
 }
 
 func resultTypes(
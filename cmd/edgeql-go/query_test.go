@@ -0,0 +1,72 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParameterCasts(t *testing.T) {
+	casts := parameterCasts(`
+		insert User {
+			name := <str>$name,
+			age := < optional int64 >$age,
+			tags := <array<tuple<str, int64>>>$tags,
+			during := <optional range<datetime>> $during,
+		};
+		select <str>$name;
+	`)
+
+	assert.Equal(t, map[string]string{
+		"name":   "<str>$name",
+		"age":    "<optional int64>$age",
+		"tags":   "<array<tuple<str, int64>>>$tags",
+		"during": "<optional range<datetime>>$during",
+	}, casts)
+}
+
+func TestNewArgsStruct(t *testing.T) {
+	args := newArgsStruct(
+		"InsertUser",
+		"insertUser",
+		[]goStructField{
+			{EQLName: "user_name", GoName: "user_name", Type: "string"},
+			{EQLName: "age", GoName: "age", Type: "geltypes.OptionalInt64"},
+		},
+		map[string]string{"user_name": "<str>$user_name"},
+	)
+
+	assert.Equal(t, &argsStruct{
+		Name:          "InsertUserArgs",
+		QueryFuncName: "insertUser",
+		Fields: []argsField{
+			{
+				EQLName: "user_name",
+				GoName:  "UserName",
+				Type:    "string",
+				Doc:     "UserName is passed as <str>$user_name.",
+			},
+			{
+				EQLName: "age",
+				GoName:  "Age",
+				Type:    "geltypes.OptionalInt64",
+			},
+		},
+	}, args)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/test-go/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func TestGreet(t *testing.T) {
+	ctx := context.Background()
+	client, err := gel.CreateClient(gelcfg.Options{})
+	require.NoError(t, err)
+
+	actual, err := greet(ctx, client, GreetArgs{Greeting: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", actual)
+
+	actual, err = greet(ctx, client, GreetArgs{
+		Greeting: "hi",
+		Name:     geltypes.NewOptionalStr("gel"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hi gel", actual)
+}
@@ -25,8 +25,10 @@ type Query struct {
 	SignatureReturnType string
 	SignatureArgs       []goStructField
 	Method              string
+	ArgsStruct          *argsStruct
 
 	imports []string
+	enums   []*goEnum
 }
 
 type goType interface {
@@ -61,3 +63,33 @@ type goStruct struct {
 }
 
 func (t *goStruct) Reference() string { return t.Name }
+
+type goEnumMember struct {
+	GoName string
+	Value  string
+}
+
+type goEnum struct {
+	Name            string
+	EQLName         string
+	OptionalName    string
+	NewOptionalName string
+	Members         []goEnumMember
+}
+
+func (t *goEnum) Reference() string { return t.Name }
+
+// argsStruct holds a query's arguments when edgeql-go is configured to pass
+// arguments in a struct instead of as separate parameters.
+type argsStruct struct {
+	Name          string
+	QueryFuncName string
+	Fields        []argsField
+}
+
+type argsField struct {
+	EQLName string
+	GoName  string
+	Type    string
+	Doc     string
+}
@@ -0,0 +1,210 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often the project is scanned for changes.
+const watchInterval = 500 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// projectState is the state of the files that edgeql-go watches.
+type projectState struct {
+	queries    map[string]fileState
+	migrations map[string]fileState
+}
+
+// scan records the state of the project's .edgeql files and migrations.
+func (g *generator) scan() (*projectState, error) {
+	state := &projectState{
+		queries:    make(map[string]fileState),
+		migrations: make(map[string]fileState),
+	}
+
+	migrationsDir := g.project.MigrationsDir + string(filepath.Separator)
+	err := filepath.WalkDir(
+		g.project.RootDir,
+		func(f string, d fs.DirEntry, e error) error {
+			if e != nil || d.IsDir() {
+				return e
+			}
+
+			isMigration := strings.HasPrefix(f, migrationsDir)
+			if !isMigration && !strings.HasSuffix(f, ".edgeql") {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			s := fileState{modTime: info.ModTime(), size: info.Size()}
+
+			if isMigration {
+				state.migrations[f] = s
+				return nil
+			}
+
+			ok, err := g.cfg.includes(f)
+			if err != nil || !ok {
+				return err
+			}
+
+			state.queries[f] = s
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// changed returns the files in next that are new or modified since prev.
+func changed(prev, next map[string]fileState) []string {
+	var files []string
+	for f, s := range next {
+		if p, ok := prev[f]; !ok || p != s {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// watch regenerates queries when their .edgeql file changes and regenerates
+// every query when a migration is added or changed. The generated file of a
+// deleted .edgeql file is removed. watch returns when ctx is done.
+func (g *generator) watch(ctx context.Context) {
+	prev, err := g.scan()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("watching %s for changes", g.project.RootDir)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		next, err := g.scan()
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		var files []string
+		migrations := changed(prev.migrations, next.migrations)
+		if len(migrations) > 0 {
+			log.Printf("%s changed, regenerating all queries",
+				g.relative(migrations[0]))
+			for f := range next.queries {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+		} else {
+			files = changed(prev.queries, next.queries)
+			for _, f := range files {
+				log.Printf("%s changed", g.relative(f))
+			}
+		}
+
+		var removed bool
+		for f := range prev.queries {
+			if _, ok := next.queries[f]; !ok {
+				log.Printf("%s removed", g.relative(f))
+				removed = true
+				err = g.removeQuery(f)
+				if err != nil {
+					log.Print(err)
+				}
+			}
+		}
+
+		prev = next
+		if len(files) == 0 && !removed {
+			continue
+		}
+
+		queue, err := g.queue(files)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		g.generateAll(ctx, queue, func(e error) { log.Print(e) })
+	}
+}
+
+// removeQuery removes the generated file and the enum types of the deleted
+// queryFile.
+func (g *generator) removeQuery(queryFile string) error {
+	err := g.enums.remove(queryFile)
+	if err != nil {
+		return err
+	}
+
+	o, err := g.cfg.dirOptions(filepath.Dir(queryFile))
+	if err != nil {
+		return err
+	}
+
+	return removeGeneratedFile(g.out, getOutFile(queryFile, o.OutputSuffix))
+}
+
+// queue caches the package names for files and returns a closed channel
+// holding files.
+func (g *generator) queue(files []string) (chan string, error) {
+	queue := make(chan string, len(files))
+	defer close(queue)
+
+	for _, f := range files {
+		err := cachePackageName(g.cfg, filepath.Dir(f))
+		if err != nil {
+			return nil, err
+		}
+		queue <- f
+	}
+
+	return queue, nil
+}
+
+func (g *generator) relative(file string) string {
+	rel, err := filepath.Rel(g.project.RootDir, file)
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,104 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScan(t *testing.T) {
+	p := writeManifest(t, "[edgeql-go]\nexclude = [\"skip/**\"]\n")
+	write := func(name string) string {
+		file := filepath.Join(p.RootDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o700))
+		require.NoError(t, os.WriteFile(file, []byte("select 1"), 0o600))
+		return file
+	}
+	query := write("q/get.edgeql")
+	migration := write("dbschema/migrations/00001-m1.edgeql")
+	write("dbschema/default.gel")
+	write("skip/skipped.edgeql")
+	write("q/get_edgeql.go")
+
+	cfg, err := loadConfig(p)
+	require.NoError(t, err)
+	g := &generator{project: p, cfg: cfg}
+
+	prev, err := g.scan()
+	require.NoError(t, err)
+	assert.Len(t, prev.queries, 1)
+	assert.Contains(t, prev.queries, query)
+	assert.Len(t, prev.migrations, 1)
+	assert.Contains(t, prev.migrations, migration)
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(query, later, later))
+	added := write("dbschema/migrations/00002-m2.edgeql")
+
+	next, err := g.scan()
+	require.NoError(t, err)
+	assert.Equal(t, []string{query}, changed(prev.queries, next.queries))
+	assert.Equal(t, []string{added},
+		changed(prev.migrations, next.migrations))
+	assert.Empty(t, changed(next.queries, next.queries))
+}
+
+func TestWatchRemovesGeneratedFile(t *testing.T) {
+	p := writeManifest(t, "[edgeql-go]\n")
+	query := filepath.Join(p.RootDir, "get.edgeql")
+	require.NoError(t, os.WriteFile(query, []byte("select 1"), 0o600))
+	generated := filepath.Join(p.RootDir, "get_edgeql.go")
+	require.NoError(t, os.WriteFile(
+		generated,
+		[]byte(generatedHeader+"\n\npackage p\n"),
+		0o600,
+	))
+
+	cfg, err := loadConfig(p)
+	require.NoError(t, err)
+	g := &generator{project: p, cfg: cfg, out: diskOutput{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.watch(ctx)
+	}()
+
+	// Give watch time to take its first scan before deleting the query.
+	time.Sleep(watchInterval / 2)
+	require.NoError(t, os.Remove(query))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(generated)
+		return os.IsNotExist(err)
+	}, 5*watchInterval, watchInterval/10)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * watchInterval):
+		t.Fatal("watch did not return after ctx was canceled")
+	}
+}
@@ -74,6 +74,19 @@
 //	uuid                     geltypes.UUID, geltypes.OptionalUUID
 //	json                     []byte, geltypes.OptionalBytes
 //	bigint                   *big.Int, geltypes.OptionalBigInt
+//	std::pg::json            []byte, geltypes.OptionalBytes
+//	std::pg::timestamptz     time.Time, geltypes.OptionalDateTime
+//	std::pg::timestamp       geltypes.LocalDateTime,
+//	                         geltypes.OptionalLocalDateTime
+//	std::pg::date            geltypes.LocalDate, geltypes.OptionalLocalDate
+//	std::pg::interval        geltypes.RelativeDuration,
+//	                         geltypes.OptionalRelativeDuration
+//	ext::pgvector::vector    geltypes.Vector, geltypes.OptionalVector
+//	ext::pgvector::halfvec   geltypes.HalfVector, geltypes.OptionalHalfVector
+//	ext::pgvector::sparsevec geltypes.SparseVector,
+//	                         geltypes.OptionalSparseVector
+//	ext::postgis::geometry   geltypes.Geometry, geltypes.OptionalGeometry
+//	ext::postgis::geography  geltypes.Geometry, geltypes.OptionalGeometry
 //
 //	decimal                  user defined (see Custom Marshalers)
 //
@@ -0,0 +1,298 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelai is a client for the HTTP API of the ext::ai extension.
+//
+// [FromClient] reuses the resolved connection parameters and credentials of
+// a [github.com/geldata/gel-go.Client]. [New] resolves the server the same
+// way [github.com/geldata/gel-go.CreateClientDSN] does.
+//
+//	ai, err := gelai.FromClient(client)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	ai = ai.WithContext(gelai.Context{Query: "Astronomy"})
+//	answer, err := ai.QueryRAG(ctx, "What color is the sky on Mars?")
+//
+// [RAG] does the same in a single call.
+//
+// The extension must be configured with providers and the objects in the
+// context query must have an ext::ai::index.
+package gelai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/gelcfg"
+	gelint "github.com/geldata/gel-go/internal/client"
+)
+
+// Client sends requests to the ext::ai extension. Clients are safe for
+// concurrent use.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	auth    *auth
+	options Options
+}
+
+// auth fetches a token the first time one is needed and shares it between
+// copies of a client.
+type auth struct {
+	mu    sync.Mutex
+	cfg   *gelint.HTTPConfig
+	token string
+}
+
+func (a *auth) Token(
+	ctx context.Context,
+	client *http.Client,
+) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" {
+		return a.token, nil
+	}
+
+	token, err := a.cfg.Token(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = token
+	return token, nil
+}
+
+// reset forgets token so that the next request authenticates again. Tokens
+// fetched by other requests in the meantime are kept.
+func (a *auth) reset(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == token {
+		a.token = ""
+	}
+}
+
+// New returns a client for the extension on the server that dsn and opts
+// resolve to. TLS is verified the same way as for database connections.
+func New(dsn string, opts gelcfg.Options) (*Client, error) { // nolint:gocritic,lll
+	cfg, err := gelint.ResolveHTTPConfig(dsn, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(cfg), nil
+}
+
+// FromClient returns a client for the extension on the branch that client
+// is connected to. It uses client's connection parameters and credentials.
+func FromClient(client *gel.Client) (*Client, error) {
+	cfg, err := gelint.ClientPool(client).HTTPConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(cfg), nil
+}
+
+func newClient(cfg *gelint.HTTPConfig) *Client {
+	return &Client{
+		baseURL: cfg.BaseURL + "/ext/ai",
+		http:    cfg.HTTPClient(),
+		auth:    &auth{cfg: cfg},
+	}
+}
+
+// BaseURL returns the URL of the extension.
+func (c *Client) BaseURL() string { return c.baseURL }
+
+// Options returns the options requests are sent with.
+func (c *Client) Options() Options { return c.options }
+
+// WithOptions returns a copy of the client that sends requests with
+// options.
+func (c *Client) WithOptions(options Options) *Client { // nolint:gocritic
+	cpy := *c
+	cpy.options = options
+	return &cpy
+}
+
+// WithModel returns a copy of the client that uses model for completions.
+func (c *Client) WithModel(model string) *Client {
+	cpy := *c
+	cpy.options.Model = model
+	return &cpy
+}
+
+// WithPrompt returns a copy of the client that uses prompt for completions.
+func (c *Client) WithPrompt(prompt Prompt) *Client {
+	cpy := *c
+	cpy.options.Prompt = &prompt
+	return &cpy
+}
+
+// WithContext returns a copy of the client that retrieves relevant objects
+// with context.
+func (c *Client) WithContext(context Context) *Client { // nolint:gocritic
+	cpy := *c
+	cpy.options.Context = context
+	return &cpy
+}
+
+// Error is an error response from the extension.
+type Error struct {
+	// StatusCode is the HTTP status code. It is zero for errors that are
+	// sent in a stream.
+	StatusCode int
+
+	// Type is the kind of error, for example BadRequestError.
+	Type string
+
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("gelai: %v: %v", e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("gelai: %v: %v", e.Type, e.Message)
+}
+
+// errorBody is the body of error responses and error events.
+type errorBody struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// post sends body as JSON and returns the response if it was successful.
+// The request is sent again with a new token if the server rejects the
+// cached one.
+func (c *Client) post(
+	ctx context.Context,
+	path string,
+	accept string,
+	body any,
+) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := c.auth.Token(ctx, c.http)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.send(ctx, path, accept, token, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			_ = resp.Body.Close()
+			c.auth.reset(token)
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			defer resp.Body.Close() // nolint:errcheck
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+
+			return nil, newError(resp.StatusCode, data)
+		}
+
+		return resp, nil
+	}
+}
+
+// send sends data as a JSON request authenticated with token.
+func (c *Client) send(
+	ctx context.Context,
+	path string,
+	accept string,
+	token string,
+	data []byte,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.baseURL+"/"+path,
+		bytes.NewReader(data),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return c.http.Do(req)
+}
+
+// postJSON sends body as JSON and decodes the JSON response into out.
+func (c *Client) postJSON(
+	ctx context.Context,
+	path string,
+	body any,
+	out any,
+) error {
+	resp, err := c.post(ctx, path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("gelai: invalid response from %v: %w", path, err)
+	}
+
+	return nil
+}
+
+func newError(status int, body []byte) *Error {
+	var resp errorBody
+	err := &Error{StatusCode: status}
+	if json.Unmarshal(body, &resp) == nil && resp.Error.Message != "" {
+		err.Type = resp.Error.Type
+		err.Message = resp.Error.Message
+		return err
+	}
+
+	err.Message = strings.TrimSpace(string(body))
+	if err.Message == "" {
+		err.Message = http.StatusText(status)
+	}
+
+	return err
+}
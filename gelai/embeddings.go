@@ -0,0 +1,67 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// GenerateEmbeddings returns the embeddings of inputs generated by model,
+// for example text-embedding-3-small. The embeddings are in the order of
+// inputs.
+func (c *Client) GenerateEmbeddings(
+	ctx context.Context,
+	model string,
+	inputs ...string,
+) ([][]float32, error) {
+	body := struct {
+		Model  string   `json:"model"`
+		Inputs []string `json:"inputs"`
+	}{model, inputs}
+
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+
+	if err := c.postJSON(ctx, "embeddings", body, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) != len(inputs) {
+		return nil, fmt.Errorf(
+			"gelai: expected %v embeddings, got %v",
+			len(inputs),
+			len(resp.Data),
+		)
+	}
+
+	sort.SliceStable(resp.Data, func(i, j int) bool {
+		return resp.Data[i].Index < resp.Data[j].Index
+	})
+
+	embeddings := make([][]float32, len(resp.Data))
+	for i, data := range resp.Data {
+		embeddings[i] = data.Embedding
+	}
+
+	return embeddings, nil
+}
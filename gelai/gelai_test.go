@@ -0,0 +1,319 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelai_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/gelai"
+	"github.com/geldata/gel-go/gelcfg"
+)
+
+const prefix = "/branch/main/ext/ai/"
+
+// newServer returns a stand-in for the ext::ai endpoints and a client for
+// it. Requests that are not authenticated with the secret key fail.
+func newServer(
+	t *testing.T,
+	handler http.HandlerFunc,
+) (*gelai.Client, *httptest.Server) {
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			handler(w, r)
+		},
+	))
+	t.Cleanup(server.Close)
+
+	client, err := gelai.New("", serverOptions(t, server))
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/branch/main/ext/ai", client.BaseURL())
+	return client, server
+}
+
+// serverOptions returns the options for connecting to server.
+func serverOptions(t *testing.T, server *httptest.Server) gelcfg.Options {
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	ca := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})
+
+	return gelcfg.Options{
+		Host:      host,
+		Port:      portNum,
+		Branch:    "main",
+		SecretKey: "key",
+		TLSOptions: gelcfg.TLSOptions{
+			CA:           ca,
+			SecurityMode: gelcfg.TLSModeStrict,
+		},
+	}
+}
+
+func decode(t *testing.T, r *http.Request) map[string]any {
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	return body
+}
+
+func TestRAG(t *testing.T) {
+	client, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, prefix+"rag", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		assert.Equal(t, map[string]any{
+			"model": "gpt-4o-mini",
+			"prompt": map[string]any{
+				"name": "astronomy",
+				"custom": []any{
+					map[string]any{
+						"role":    "system",
+						"content": "Be brief.",
+					},
+					map[string]any{
+						"role": "user",
+						"content": []any{map[string]any{
+							"type": "text",
+							"text": "Answer in French.",
+						}},
+					},
+				},
+			},
+			"context": map[string]any{
+				"query":            "Astronomy",
+				"globals":          map[string]any{"lang": "fr"},
+				"max_object_count": float64(3),
+			},
+			"query":  "What color is the sky on Mars?",
+			"stream": false,
+		}, decode(t, r))
+
+		_, _ = w.Write([]byte(`{"response": "Rouge."}`))
+	})
+
+	client = client.
+		WithModel("gpt-4o-mini").
+		WithPrompt(gelai.Prompt{
+			Name: "astronomy",
+			Custom: []gelai.Message{
+				{Role: gelai.System, Content: "Be brief."},
+				{Role: gelai.User, Content: "Answer in French."},
+			},
+		}).
+		WithContext(gelai.Context{
+			Query:          "Astronomy",
+			Globals:        map[string]any{"lang": "fr"},
+			MaxObjectCount: 3,
+		})
+
+	answer, err := client.QueryRAG(
+		context.Background(),
+		"What color is the sky on Mars?",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "Rouge.", answer)
+}
+
+func TestRAGFromClient(t *testing.T) {
+	_, server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/branch/other/ext/ai/rag", r.URL.Path)
+		body := decode(t, r)
+		assert.Equal(t, "gpt-4o-mini", body["model"])
+		assert.Equal(t, map[string]any{"query": "Astronomy"},
+			body["context"])
+
+		_, _ = w.Write([]byte(`{"response": "Red."}`))
+	})
+
+	client, err := gel.CreateClient(serverOptions(t, server))
+	require.NoError(t, err)
+	defer client.Close() // nolint:errcheck
+
+	answer, err := gelai.RAG(
+		context.Background(),
+		client.WithBranch("other"),
+		"What color is the sky on Mars?",
+		gelai.Options{
+			Model:   "gpt-4o-mini",
+			Context: gelai.Context{Query: "Astronomy"},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "Red.", answer)
+}
+
+func TestUnauthorizedRetry(t *testing.T) {
+	requests, rejected := 0, 1
+	client, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if rejected > 0 {
+			// The cached token expired.
+			rejected--
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"response": "Red."}`))
+	})
+
+	answer, err := client.QueryRAG(context.Background(), "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "Red.", answer)
+	assert.Equal(t, 2, requests)
+
+	// The request is only sent again once.
+	requests, rejected = 0, 2
+	_, err = client.QueryRAG(context.Background(), "hi")
+	var aiErr *gelai.Error
+	require.ErrorAs(t, err, &aiErr)
+	assert.Equal(t, http.StatusUnauthorized, aiErr.StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestRAGError(t *testing.T) {
+	client, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {` +
+			`"type": "BadRequestError", "message": "unknown model"}}`))
+	})
+
+	_, err := client.QueryRAG(context.Background(), "hi")
+	var aiErr *gelai.Error
+	require.ErrorAs(t, err, &aiErr)
+	assert.Equal(t, http.StatusBadRequest, aiErr.StatusCode)
+	assert.Equal(t, "BadRequestError", aiErr.Type)
+	assert.EqualError(t, err, "gelai: BadRequestError: unknown model")
+}
+
+func writeEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for i := 0; i < len(events); i += 2 {
+		_, _ = fmt.Fprintf(w, "event: %v\ndata: %v\n\n",
+			events[i], events[i+1])
+		w.(http.Flusher).Flush()
+	}
+}
+
+func TestStreamRAG(t *testing.T) {
+	client, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		assert.Equal(t, true, decode(t, r)["stream"])
+
+		writeEvents(w,
+			"message_start", `{"type": "message_start"}`,
+			"content_block_start", `{"type": "content_block_start"}`,
+			"content_block_delta", `{"type": "content_block_delta",`+
+				` "delta": {"type": "text_delta", "text": "It is "}}`,
+			"content_block_delta", `{"type": "content_block_delta",`+
+				` "delta": {"type": "text_delta", "text": "red."}}`,
+			"content_block_stop", `{"type": "content_block_stop"}`,
+			"message_stop", `{"type": "message_stop"}`,
+		)
+	})
+
+	stream, err := client.StreamRAG(context.Background(), "hi")
+	require.NoError(t, err)
+	defer stream.Close() // nolint:errcheck
+
+	var text []string
+	for stream.Next() {
+		text = append(text, stream.Text())
+	}
+
+	require.NoError(t, stream.Err())
+	assert.Equal(t, []string{"It is ", "red."}, text)
+	assert.False(t, stream.Next())
+}
+
+func TestStreamRAGError(t *testing.T) {
+	client, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			"content_block_delta", `{"type": "content_block_delta",`+
+				` "delta": {"type": "text_delta", "text": "It"}}`,
+			"error", `{"type": "error", "error": {`+
+				`"type": "ProviderError", "message": "rate limited"}}`,
+		)
+	})
+
+	stream, err := client.StreamRAG(context.Background(), "hi")
+	require.NoError(t, err)
+	defer stream.Close() // nolint:errcheck
+
+	require.True(t, stream.Next())
+	assert.Equal(t, "It", stream.Text())
+	assert.False(t, stream.Next())
+	assert.EqualError(t, stream.Err(), "gelai: ProviderError: rate limited")
+}
+
+func TestStreamRAGTruncated(t *testing.T) {
+	client, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, "message_start", `{"type": "message_start"}`)
+	})
+
+	stream, err := client.StreamRAG(context.Background(), "hi")
+	require.NoError(t, err)
+	defer stream.Close() // nolint:errcheck
+
+	assert.False(t, stream.Next())
+	assert.ErrorContains(t, stream.Err(), "unexpected EOF")
+}
+
+func TestGenerateEmbeddings(t *testing.T) {
+	client, _ := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, prefix+"embeddings", r.URL.Path)
+		assert.Equal(t, map[string]any{
+			"model":  "text-embedding-3-small",
+			"inputs": []any{"a", "b"},
+		}, decode(t, r))
+
+		_, _ = w.Write([]byte(`{"data": [` +
+			`{"index": 1, "embedding": [0.5, 0.25]},` +
+			`{"index": 0, "embedding": [1, 2]}]}`))
+	})
+
+	embeddings, err := client.GenerateEmbeddings(
+		context.Background(),
+		"text-embedding-3-small",
+		"a",
+		"b",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 2}, {0.5, 0.25}}, embeddings)
+}
@@ -0,0 +1,100 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelai
+
+import "encoding/json"
+
+// Options are sent with every RAG request.
+type Options struct {
+	// Model is the name of the text generation model, for example
+	// gpt-4o-mini.
+	Model string
+
+	// Prompt is the prompt used for completions. If it is nil the
+	// extension's default prompt is used.
+	Prompt *Prompt
+
+	// Context selects the objects that are passed to the model.
+	Context Context
+}
+
+// Prompt selects a prompt configured in the extension and optionally
+// extends it with custom messages. At most one of Name and ID should be set.
+type Prompt struct {
+	// Name is the name of an ext::ai::ChatPrompt.
+	Name string `json:"name,omitempty"`
+
+	// ID is the id of an ext::ai::ChatPrompt.
+	ID string `json:"id,omitempty"`
+
+	// Custom messages are appended to the configured prompt, or used on
+	// their own if neither Name nor ID is set.
+	Custom []Message `json:"custom,omitempty"`
+}
+
+// Roles of prompt messages.
+const (
+	System    = "system"
+	User      = "user"
+	Assistant = "assistant"
+)
+
+// Message is a message in a prompt.
+type Message struct {
+	// Role is one of [System], [User] or [Assistant].
+	Role string
+
+	Content string
+}
+
+// MarshalJSON implements [encoding/json.Marshaler]. User messages are sent
+// as a list of content blocks.
+func (m Message) MarshalJSON() ([]byte, error) {
+	if m.Role != User {
+		return json.Marshal(struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		}{m.Role, m.Content})
+	}
+
+	type block struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+
+	return json.Marshal(struct {
+		Role    string  `json:"role"`
+		Content []block `json:"content"`
+	}{m.Role, []block{{"text", m.Content}}})
+}
+
+// Context selects the objects that are passed to the model.
+type Context struct {
+	// Query is an EdgeQL expression that returns the objects to search,
+	// for example Astronomy or (select Astronomy filter .public).
+	Query string `json:"query"`
+
+	// Variables are the arguments of Query.
+	Variables map[string]any `json:"variables,omitempty"`
+
+	// Globals are set when Query is evaluated.
+	Globals map[string]any `json:"globals,omitempty"`
+
+	// MaxObjectCount limits the number of objects passed to the model.
+	// The extension's default is used if it is zero.
+	MaxObjectCount int `json:"max_object_count,omitempty"`
+}
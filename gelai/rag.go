@@ -0,0 +1,230 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	gel "github.com/geldata/gel-go"
+)
+
+type ragRequest struct {
+	Model   string  `json:"model"`
+	Prompt  *Prompt `json:"prompt,omitempty"`
+	Context Context `json:"context"`
+	Query   string  `json:"query"`
+	Stream  bool    `json:"stream"`
+}
+
+func (c *Client) ragRequest(query string, stream bool) *ragRequest {
+	return &ragRequest{
+		Model:   c.options.Model,
+		Prompt:  c.options.Prompt,
+		Context: c.options.Context,
+		Query:   query,
+		Stream:  stream,
+	}
+}
+
+// RAG answers query using the connection and credentials of client. The
+// relevant objects are selected by options.Context.
+func RAG(
+	ctx context.Context,
+	client *gel.Client,
+	query string,
+	options Options,
+) (string, error) {
+	ai, err := FromClient(client)
+	if err != nil {
+		return "", err
+	}
+
+	return ai.WithOptions(options).QueryRAG(ctx, query)
+}
+
+// QueryRAG answers query with the objects selected by the client's context
+// and returns the complete response.
+func (c *Client) QueryRAG(ctx context.Context, query string) (string, error) {
+	var resp struct {
+		Response string `json:"response"`
+	}
+
+	err := c.postJSON(ctx, "rag", c.ragRequest(query, false), &resp)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Response, nil
+}
+
+// StreamRAG answers query with the objects selected by the client's context
+// and streams the response as it is generated. The stream must be closed.
+//
+//	stream, err := ai.StreamRAG(ctx, query)
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//
+//	for stream.Next() {
+//		fmt.Print(stream.Text())
+//	}
+//
+//	return stream.Err()
+func (c *Client) StreamRAG(
+	ctx context.Context,
+	query string,
+) (*Stream, error) {
+	resp, err := c.post(
+		ctx,
+		"rag",
+		"text/event-stream",
+		c.ragRequest(query, true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stream{
+		body:    resp.Body,
+		scanner: bufio.NewScanner(resp.Body),
+	}, nil
+}
+
+// Stream is a completion that is being generated.
+type Stream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	text    string
+	err     error
+	done    bool
+}
+
+// Next advances to the next piece of text. It returns false when the
+// completion is finished or an error occurred.
+func (s *Stream) Next() bool {
+	s.text = ""
+	for !s.done {
+		event, data, ok := s.event()
+		if !ok {
+			break
+		}
+
+		if s.handle(event, data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handle processes an event and returns true if it carried text.
+func (s *Stream) handle(event string, data []byte) bool {
+	var payload struct {
+		Type  string `json:"type"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &payload); err != nil {
+			s.fail(fmt.Errorf("gelai: invalid %v event: %w", event, err))
+			return false
+		}
+	}
+
+	if event == "" {
+		event = payload.Type
+	}
+
+	switch event {
+	case "content_block_delta":
+		if payload.Delta.Type == "text_delta" && payload.Delta.Text != "" {
+			s.text = payload.Delta.Text
+			return true
+		}
+	case "message_stop":
+		s.done = true
+	case "error":
+		var body errorBody
+		_ = json.Unmarshal(data, &body)
+		s.fail(&Error{Type: body.Error.Type, Message: body.Error.Message})
+	}
+
+	return false
+}
+
+// event reads the next server-sent event.
+func (s *Stream) event() (string, []byte, bool) {
+	var (
+		event string
+		data  []string
+	)
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			if event == "" && len(data) == 0 {
+				continue
+			}
+
+			return event, []byte(strings.Join(data, "\n")), true
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		s.fail(err)
+	} else if !s.done {
+		s.fail(io.ErrUnexpectedEOF)
+	}
+
+	return "", nil, false
+}
+
+func (s *Stream) fail(err error) {
+	s.err = err
+	s.done = true
+}
+
+// Text returns the text read by the last call to [Stream.Next].
+func (s *Stream) Text() string { return s.text }
+
+// Err returns the error that ended the stream, if any.
+func (s *Stream) Err() error { return s.err }
+
+// Close releases the connection. It is safe to call Close before the
+// stream is finished.
+func (s *Stream) Close() error {
+	s.done = true
+	return s.body.Close()
+}
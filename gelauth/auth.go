@@ -0,0 +1,252 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelauth is a client for the HTTP API of the ext::auth extension.
+//
+// [New] resolves the server the same way
+// [github.com/geldata/gel-go.CreateClientDSN] does and sends requests to
+// the extension's endpoints on the client's branch.
+//
+//	auth, err := gelauth.New("", gelcfg.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	token, err := auth.SignIn(ctx, "alice@example.com", "password")
+//
+// Flows return [TokenData] with the id of the identity that signed in.
+// [TokenData.Identity] fetches the full [Identity] with a database client.
+//
+// Every flow uses PKCE. Flows that complete in another request, like email
+// verification or magic links, return the PKCE verifier which must be kept,
+// for example in a cookie, and passed to [Client.ExchangeCode] together
+// with the code the extension redirects to.
+package gelauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/geltypes"
+	gelint "github.com/geldata/gel-go/internal/client"
+)
+
+// Names of the built in providers.
+const (
+	EmailPasswordProvider = "builtin::local_emailpassword"
+	MagicLinkProvider     = "builtin::local_magic_link"
+	WebAuthnProvider      = "builtin::local_webauthn"
+)
+
+// Client sends requests to the ext::auth extension.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// New returns a client for the extension on the server that dsn and opts
+// resolve to. TLS is verified the same way as for database connections.
+func New(dsn string, opts gelcfg.Options) (*Client, error) { // nolint:gocritic,lll
+	cfg, err := gelint.ResolveHTTPConfig(dsn, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromURL(cfg.BaseURL+"/ext/auth", cfg.HTTPClient()), nil
+}
+
+// NewFromURL returns a client for the extension at baseURL, for example
+// https://localhost:5656/branch/main/ext/auth. If httpClient is nil
+// [http.DefaultClient] is used.
+func NewFromURL(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    httpClient,
+	}
+}
+
+// BaseURL returns the URL of the extension.
+func (c *Client) BaseURL() string { return c.baseURL }
+
+// TokenData is returned when a flow completes.
+type TokenData struct {
+	// AuthToken is a JWT that identifies the identity in queries when it is
+	// set as the ext::auth::client_token global.
+	AuthToken string `json:"auth_token"`
+
+	// IdentityID is the id of the ext::auth::Identity that signed in.
+	IdentityID geltypes.UUID `json:"identity_id"`
+
+	// ProviderToken, ProviderRefreshToken and ProviderIDToken are set by
+	// OAuth providers.
+	ProviderToken        string `json:"provider_token,omitempty"`
+	ProviderRefreshToken string `json:"provider_refresh_token,omitempty"`
+	ProviderIDToken      string `json:"provider_id_token,omitempty"`
+}
+
+// Error is an error response from the extension.
+type Error struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+
+	// Type is the kind of error, for example NoIdentityFound.
+	Type string
+
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("gelauth: %v: %v", e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("gelauth: %v: %v", e.Type, e.Message)
+}
+
+// ExchangeCode exchanges the code the extension redirected to, or returned
+// from a flow, for an auth token. verifier is the verifier of the PKCE pair
+// the flow was started with.
+func (c *Client) ExchangeCode(
+	ctx context.Context,
+	code string,
+	verifier string,
+) (*TokenData, error) {
+	var token TokenData
+	query := url.Values{"code": {code}, "verifier": {verifier}}
+	if err := c.get(ctx, "token", query, &token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	u := c.baseURL + "/" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	return u
+}
+
+// get sends a GET request and decodes the JSON response into out.
+func (c *Client) get(
+	ctx context.Context,
+	path string,
+	query url.Values,
+	out any,
+) error {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.url(path, query),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.do(req, out)
+}
+
+// post sends body as JSON and decodes the JSON response into out.
+func (c *Client) post(
+	ctx context.Context,
+	path string,
+	body any,
+	out any,
+) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.url(path, nil),
+		bytes.NewReader(data),
+	)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, out)
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newError(resp.StatusCode, data)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("gelauth: invalid response from %v: %w",
+			req.URL.Path, err)
+	}
+
+	return nil
+}
+
+func newError(status int, body []byte) *Error {
+	var resp struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	err := &Error{StatusCode: status}
+	if json.Unmarshal(body, &resp) == nil && resp.Error.Message != "" {
+		err.Type = resp.Error.Type
+		err.Message = resp.Error.Message
+		return err
+	}
+
+	err.Message = strings.TrimSpace(string(body))
+	if err.Message == "" {
+		err.Message = http.StatusText(status)
+	}
+
+	return err
+}
@@ -0,0 +1,187 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelauth
+
+import (
+	"context"
+)
+
+// codeResponse is returned by endpoints that complete a flow.
+type codeResponse struct {
+	Code string `json:"code"`
+}
+
+// SignUpResult is the result of signing up.
+type SignUpResult struct {
+	// Verifier is the PKCE verifier to pass to [Client.VerifyEmail] when
+	// the email address must be verified.
+	Verifier string
+
+	// Token is nil when the email address must be verified before signing
+	// in.
+	Token *TokenData
+}
+
+// SignUp registers an identity with an email address and password. If the
+// extension requires email verification a message with a link to verifyURL
+// is sent and the result has no token.
+func (c *Client) SignUp(
+	ctx context.Context,
+	email string,
+	password string,
+	verifyURL string,
+) (*SignUpResult, error) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp codeResponse
+	err = c.post(ctx, "register", map[string]string{
+		"provider":   EmailPasswordProvider,
+		"challenge":  pkce.Challenge,
+		"email":      email,
+		"password":   password,
+		"verify_url": verifyURL,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.signUpResult(ctx, pkce, resp.Code)
+}
+
+func (c *Client) signUpResult(
+	ctx context.Context,
+	pkce *PKCE,
+	code string,
+) (*SignUpResult, error) {
+	result := &SignUpResult{Verifier: pkce.Verifier}
+	if code == "" {
+		return result, nil
+	}
+
+	token, err := c.ExchangeCode(ctx, code, pkce.Verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Token = token
+	return result, nil
+}
+
+// SignIn signs in with an email address and password.
+func (c *Client) SignIn(
+	ctx context.Context,
+	email string,
+	password string,
+) (*TokenData, error) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp codeResponse
+	err = c.post(ctx, "authenticate", map[string]string{
+		"provider":  EmailPasswordProvider,
+		"challenge": pkce.Challenge,
+		"email":     email,
+		"password":  password,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExchangeCode(ctx, resp.Code, pkce.Verifier)
+}
+
+// VerifyEmail verifies the email address of an identity with the token
+// from the verification link. verifier is [SignUpResult.Verifier].
+func (c *Client) VerifyEmail(
+	ctx context.Context,
+	verificationToken string,
+	verifier string,
+) (*TokenData, error) {
+	var resp codeResponse
+	err := c.post(ctx, "verify", map[string]string{
+		"provider":           EmailPasswordProvider,
+		"verification_token": verificationToken,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExchangeCode(ctx, resp.Code, verifier)
+}
+
+// ResendVerificationEmail sends the verification email again.
+func (c *Client) ResendVerificationEmail(
+	ctx context.Context,
+	verificationToken string,
+) error {
+	return c.post(ctx, "resend-verification-email", map[string]string{
+		"provider":           EmailPasswordProvider,
+		"verification_token": verificationToken,
+	}, nil)
+}
+
+// SendPasswordResetEmail sends an email with a link to resetURL to reset
+// the password. The returned verifier must be passed to
+// [Client.ResetPassword].
+func (c *Client) SendPasswordResetEmail(
+	ctx context.Context,
+	email string,
+	resetURL string,
+) (string, error) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.post(ctx, "send-reset-email", map[string]string{
+		"provider":  EmailPasswordProvider,
+		"challenge": pkce.Challenge,
+		"email":     email,
+		"reset_url": resetURL,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return pkce.Verifier, nil
+}
+
+// ResetPassword sets a new password with the token from the reset link and
+// signs in.
+func (c *Client) ResetPassword(
+	ctx context.Context,
+	resetToken string,
+	verifier string,
+	password string,
+) (*TokenData, error) {
+	var resp codeResponse
+	err := c.post(ctx, "reset-password", map[string]string{
+		"provider":    EmailPasswordProvider,
+		"reset_token": resetToken,
+		"password":    password,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExchangeCode(ctx, resp.Code, verifier)
+}
@@ -0,0 +1,396 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelauth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/geldata/gel-go/gelauth"
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/gelfake"
+	"github.com/geldata/gel-go/geltypes"
+)
+
+const prefix = "/branch/main/ext/auth/"
+
+var identity = geltypes.UUID{1, 2, 3}
+
+// fakeAuth is a stand-in for the ext::auth HTTP endpoints.
+type fakeAuth struct {
+	t        *testing.T
+	mu       sync.Mutex
+	requests map[string]map[string]any
+	// challenges maps codes to the PKCE challenge of the flow.
+	challenges map[string]string
+	verify     bool
+}
+
+func newFakeAuth(t *testing.T) (*fakeAuth, *httptest.Server) {
+	f := &fakeAuth{
+		t:          t,
+		requests:   make(map[string]map[string]any),
+		challenges: make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"token", f.token)
+	mux.HandleFunc("GET "+prefix+"webauthn/register/options", f.options)
+	mux.HandleFunc("GET "+prefix+"webauthn/authenticate/options", f.options)
+	for _, path := range []string{
+		"register",
+		"authenticate",
+		"verify",
+		"resend-verification-email",
+		"send-reset-email",
+		"reset-password",
+		"magic-link/register",
+		"magic-link/email",
+		"webauthn/register",
+		"webauthn/authenticate",
+	} {
+		mux.HandleFunc("POST "+prefix+path, f.post)
+	}
+
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeAuth) post(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path[len(prefix):]
+	assert.Equal(f.t, "application/json", r.Header.Get("Content-Type"))
+
+	var body map[string]any
+	require.NoError(f.t, json.NewDecoder(r.Body).Decode(&body))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests[path] = body
+
+	if body["password"] == "wrong" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"type": "NoIdentityFound",` +
+			` "message": "No identity found"}}`))
+		return
+	}
+
+	switch path {
+	case "register", "webauthn/register":
+		if f.verify {
+			writeJSON(w, map[string]any{"verification_email_sent_at": "x"})
+			return
+		}
+	case "resend-verification-email", "send-reset-email",
+		"magic-link/register", "magic-link/email":
+		writeJSON(w, map[string]any{"email_sent": body["email"]})
+		return
+	}
+
+	// Verification and password reset complete flows that were started by
+	// an earlier request.
+	started := body
+	switch path {
+	case "verify":
+		started = f.requests["register"]
+	case "reset-password":
+		started = f.requests["send-reset-email"]
+	}
+
+	challenge, _ := started["challenge"].(string)
+
+	code := "code-" + strconv.Itoa(len(f.challenges))
+	f.challenges[code] = challenge
+	writeJSON(w, map[string]any{"code": code})
+}
+
+func (f *fakeAuth) token(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	code := r.URL.Query().Get("code")
+	verifier := r.URL.Query().Get("verifier")
+	sum := sha256.Sum256([]byte(verifier))
+	if f.challenges[code] != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		http.Error(w, "invalid verifier", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"auth_token":             "token-for-" + code,
+		"identity_id":            identity.String(),
+		"provider_token":         nil,
+		"provider_refresh_token": nil,
+	})
+}
+
+func (f *fakeAuth) options(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"challenge": "abc",
+		"user":      map[string]any{"name": r.URL.Query().Get("email")},
+	})
+}
+
+func (f *fakeAuth) request(path string) map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[path]
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// newClient returns a client that resolves the stand-in server from client
+// options like a database client would.
+func newClient(t *testing.T, server *httptest.Server) *gelauth.Client {
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	portNum, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	ca := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})
+
+	client, err := gelauth.New("", gelcfg.Options{
+		Host:   host,
+		Port:   portNum,
+		Branch: "main",
+		TLSOptions: gelcfg.TLSOptions{
+			CA:           ca,
+			SecurityMode: gelcfg.TLSModeStrict,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/branch/main/ext/auth", client.BaseURL())
+	return client
+}
+
+func TestSignUpAndSignIn(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeAuth(t)
+	client := newClient(t, server)
+
+	result, err := client.SignUp(ctx, "a@example.com", "secret",
+		"https://app/verify")
+	require.NoError(t, err)
+	require.NotNil(t, result.Token)
+	assert.Equal(t, identity, result.Token.IdentityID)
+	assert.Equal(t, "token-for-code-0", result.Token.AuthToken)
+	assert.Equal(t, map[string]any{
+		"provider":   gelauth.EmailPasswordProvider,
+		"challenge":  fake.request("register")["challenge"],
+		"email":      "a@example.com",
+		"password":   "secret",
+		"verify_url": "https://app/verify",
+	}, fake.request("register"))
+
+	token, err := client.SignIn(ctx, "a@example.com", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "token-for-code-1", token.AuthToken)
+
+	_, err = client.SignIn(ctx, "a@example.com", "wrong")
+	var authErr *gelauth.Error
+	require.True(t, errors.As(err, &authErr))
+	assert.Equal(t, &gelauth.Error{
+		StatusCode: http.StatusForbidden,
+		Type:       "NoIdentityFound",
+		Message:    "No identity found",
+	}, authErr)
+	assert.EqualError(t, err, "gelauth: NoIdentityFound: No identity found")
+}
+
+func TestEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeAuth(t)
+	fake.verify = true
+	client := newClient(t, server)
+
+	result, err := client.SignUp(ctx, "a@example.com", "secret",
+		"https://app/verify")
+	require.NoError(t, err)
+	assert.Nil(t, result.Token)
+	require.NotEmpty(t, result.Verifier)
+
+	require.NoError(t, client.ResendVerificationEmail(ctx, "vtoken"))
+	assert.Equal(t, "vtoken",
+		fake.request("resend-verification-email")["verification_token"])
+
+	token, err := client.VerifyEmail(ctx, "vtoken", result.Verifier)
+	require.NoError(t, err)
+	assert.Equal(t, identity, token.IdentityID)
+
+	_, err = client.VerifyEmail(ctx, "vtoken", "wrong verifier")
+	assert.EqualError(t, err, "gelauth: 400: invalid verifier")
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeAuth(t)
+	client := newClient(t, server)
+
+	verifier, err := client.SendPasswordResetEmail(ctx, "a@example.com",
+		"https://app/reset")
+	require.NoError(t, err)
+	assert.Equal(t, "https://app/reset",
+		fake.request("send-reset-email")["reset_url"])
+
+	token, err := client.ResetPassword(ctx, "rtoken", verifier, "new")
+	require.NoError(t, err)
+	assert.Equal(t, identity, token.IdentityID)
+	assert.Equal(t, map[string]any{
+		"provider":    gelauth.EmailPasswordProvider,
+		"reset_token": "rtoken",
+		"password":    "new",
+	}, fake.request("reset-password"))
+}
+
+func TestMagicLink(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeAuth(t)
+	client := newClient(t, server)
+
+	verifier, err := client.SignUpWithMagicLink(ctx, "a@example.com",
+		"https://app/callback", "https://app/failed")
+	require.NoError(t, err)
+	assert.NotEmpty(t, verifier)
+	register := fake.request("magic-link/register")
+	assert.Equal(t, map[string]any{
+		"provider":            gelauth.MagicLinkProvider,
+		"challenge":           register["challenge"],
+		"email":               "a@example.com",
+		"callback_url":        "https://app/callback",
+		"redirect_on_failure": "https://app/failed",
+	}, register)
+
+	_, err = client.SendMagicLink(ctx, "a@example.com",
+		"https://app/callback", "https://app/failed")
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com",
+		fake.request("magic-link/email")["email"])
+}
+
+func TestWebAuthn(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeAuth(t)
+	client := newClient(t, server)
+
+	options, err := client.WebAuthnSignUpOptions(ctx, "a@example.com")
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"challenge": "abc", "user": {"name": "a@example.com"}}`,
+		string(options))
+
+	options, err = client.WebAuthnSignInOptions(ctx, "a@example.com")
+	require.NoError(t, err)
+	assert.Contains(t, string(options), "challenge")
+
+	result, err := client.WebAuthnSignUp(ctx, "a@example.com",
+		json.RawMessage(`{"id": "cred"}`), "handle", "https://app/verify")
+	require.NoError(t, err)
+	require.NotNil(t, result.Token)
+	assert.Equal(t, map[string]any{"id": "cred"},
+		fake.request("webauthn/register")["credentials"])
+	assert.Equal(t, "handle",
+		fake.request("webauthn/register")["user_handle"])
+
+	token, err := client.WebAuthnSignIn(ctx, "a@example.com",
+		json.RawMessage(`{"id": "assertion"}`))
+	require.NoError(t, err)
+	assert.Equal(t, identity, token.IdentityID)
+	assert.Equal(t, gelauth.WebAuthnProvider,
+		fake.request("webauthn/authenticate")["provider"])
+}
+
+func TestURLs(t *testing.T) {
+	client := gelauth.NewFromURL("https://db/branch/main/ext/auth/", nil)
+	pkce := &gelauth.PKCE{Verifier: "v", Challenge: "c"}
+
+	assert.Equal(t, "https://db/branch/main/ext/auth/authorize?"+
+		"challenge=c&provider=builtin%3A%3Aoauth_github&"+
+		"redirect_to=https%3A%2F%2Fapp%2Fcb",
+		client.AuthorizeURL("builtin::oauth_github", pkce,
+			"https://app/cb", ""))
+	assert.Contains(t,
+		client.AuthorizeURL("builtin::oauth_github", pkce,
+			"https://app/cb", "https://app/new"),
+		"redirect_to_on_signup=https%3A%2F%2Fapp%2Fnew")
+	assert.Equal(t, "https://db/branch/main/ext/auth/ui/signin?challenge=c",
+		client.SignInUIURL(pkce))
+	assert.Equal(t, "https://db/branch/main/ext/auth/ui/signup?challenge=c",
+		client.SignUpUIURL(pkce))
+}
+
+func TestNewPKCE(t *testing.T) {
+	pkce, err := gelauth.NewPKCE()
+	require.NoError(t, err)
+	assert.Len(t, pkce.Verifier, 43)
+
+	sum := sha256.Sum256([]byte(pkce.Verifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]),
+		pkce.Challenge)
+
+	other, err := gelauth.NewPKCE()
+	require.NoError(t, err)
+	assert.NotEqual(t, pkce.Verifier, other.Verifier)
+}
+
+func TestIdentity(t *testing.T) {
+	id := geltypes.UUID{1}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake := gelfake.New()
+	fake.Expect(gelfake.Regexp(`ext::auth::Identity`)).
+		WithArgs(id).
+		Return(map[string]any{
+			"id":          id,
+			"issuer":      "local",
+			"subject":     "alice",
+			"created_at":  created,
+			"modified_at": created,
+		})
+
+	token := gelauth.TokenData{AuthToken: "jwt", IdentityID: id}
+	identity, err := token.Identity(context.Background(), fake)
+	require.NoError(t, err)
+	assert.Equal(t, &gelauth.Identity{
+		ID:         id,
+		Issuer:     "local",
+		Subject:    "alice",
+		CreatedAt:  created,
+		ModifiedAt: created,
+	}, identity)
+}
@@ -0,0 +1,74 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/geldata/gel-go/geltypes"
+)
+
+// Identity is an ext::auth::Identity, the account a user signed in with.
+type Identity struct {
+	ID geltypes.UUID `gel:"id"`
+
+	// Issuer is the provider that authenticated the identity, for example
+	// local or https://accounts.google.com.
+	Issuer string `gel:"issuer"`
+
+	// Subject identifies the user at the issuer.
+	Subject string `gel:"subject"`
+
+	CreatedAt  time.Time `gel:"created_at"`
+	ModifiedAt time.Time `gel:"modified_at"`
+}
+
+const identityQuery = `
+	SELECT ext::auth::Identity {
+		id,
+		issuer,
+		subject,
+		created_at,
+		modified_at,
+	}
+	FILTER .id = <uuid>$0`
+
+// FetchIdentity queries the identity with the given id on db, the
+// application's database client.
+// [github.com/geldata/gel-go/gelerr.NoDataError] is returned if there is no
+// such identity.
+func FetchIdentity(
+	ctx context.Context,
+	db geltypes.Executor,
+	id geltypes.UUID,
+) (*Identity, error) {
+	var identity Identity
+	if err := db.QuerySingle(ctx, identityQuery, &identity, id); err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// Identity queries the identity that signed in. See [FetchIdentity].
+func (t *TokenData) Identity(
+	ctx context.Context,
+	db geltypes.Executor,
+) (*Identity, error) {
+	return FetchIdentity(ctx, db, t.IdentityID)
+}
@@ -0,0 +1,74 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelauth
+
+import (
+	"context"
+)
+
+// SignUpWithMagicLink registers an identity with an email address and sends
+// a magic link. When the link is opened the extension redirects to
+// callbackURL with a code query parameter, or to failureURL if signing in
+// failed. The returned verifier must be passed to [Client.ExchangeCode]
+// with the code.
+func (c *Client) SignUpWithMagicLink(
+	ctx context.Context,
+	email string,
+	callbackURL string,
+	failureURL string,
+) (string, error) {
+	return c.magicLink(ctx, "magic-link/register", email, callbackURL,
+		failureURL)
+}
+
+// SendMagicLink sends a magic link to sign in an existing identity. It
+// works like [Client.SignUpWithMagicLink].
+func (c *Client) SendMagicLink(
+	ctx context.Context,
+	email string,
+	callbackURL string,
+	failureURL string,
+) (string, error) {
+	return c.magicLink(ctx, "magic-link/email", email, callbackURL,
+		failureURL)
+}
+
+func (c *Client) magicLink(
+	ctx context.Context,
+	path string,
+	email string,
+	callbackURL string,
+	failureURL string,
+) (string, error) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.post(ctx, path, map[string]string{
+		"provider":            MagicLinkProvider,
+		"challenge":           pkce.Challenge,
+		"email":               email,
+		"callback_url":        callbackURL,
+		"redirect_on_failure": failureURL,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return pkce.Verifier, nil
+}
@@ -0,0 +1,79 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+)
+
+// PKCE is a proof key for code exchange. The challenge is sent when a flow
+// starts and the verifier when the resulting code is exchanged for a token.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+}
+
+// NewPKCE returns a new random verifier and its challenge.
+func NewPKCE() (*PKCE, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	verifier := base64.RawURLEncoding.EncodeToString(b)
+	return &PKCE{Verifier: verifier, Challenge: challenge(verifier)}, nil
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthorizeURL returns the URL that starts the OAuth flow of provider.
+// After signing in the extension redirects to redirectTo, or to
+// redirectToOnSignUp for new identities if it isn't empty, with a code
+// query parameter.
+func (c *Client) AuthorizeURL(
+	provider string,
+	pkce *PKCE,
+	redirectTo string,
+	redirectToOnSignUp string,
+) string {
+	query := url.Values{
+		"provider":    {provider},
+		"challenge":   {pkce.Challenge},
+		"redirect_to": {redirectTo},
+	}
+	if redirectToOnSignUp != "" {
+		query.Set("redirect_to_on_signup", redirectToOnSignUp)
+	}
+
+	return c.url("authorize", query)
+}
+
+// SignInUIURL returns the URL of the built in sign in page.
+func (c *Client) SignInUIURL(pkce *PKCE) string {
+	return c.url("ui/signin", url.Values{"challenge": {pkce.Challenge}})
+}
+
+// SignUpUIURL returns the URL of the built in sign up page.
+func (c *Client) SignUpUIURL(pkce *PKCE) string {
+	return c.url("ui/signup", url.Values{"challenge": {pkce.Challenge}})
+}
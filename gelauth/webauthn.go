@@ -0,0 +1,112 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// WebAuthnSignUpOptions returns the PublicKeyCredentialCreationOptions to
+// pass to navigator.credentials.create() in the browser.
+func (c *Client) WebAuthnSignUpOptions(
+	ctx context.Context,
+	email string,
+) (json.RawMessage, error) {
+	var options json.RawMessage
+	query := url.Values{"email": {email}}
+	err := c.get(ctx, "webauthn/register/options", query, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// WebAuthnSignInOptions returns the PublicKeyCredentialRequestOptions to
+// pass to navigator.credentials.get() in the browser.
+func (c *Client) WebAuthnSignInOptions(
+	ctx context.Context,
+	email string,
+) (json.RawMessage, error) {
+	var options json.RawMessage
+	query := url.Values{"email": {email}}
+	err := c.get(ctx, "webauthn/authenticate/options", query, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// WebAuthnSignUp registers an identity with the credentials created by the
+// browser. userHandle is the user.id from [Client.WebAuthnSignUpOptions].
+// If the extension requires email verification a message with a link to
+// verifyURL is sent and the result has no token.
+func (c *Client) WebAuthnSignUp(
+	ctx context.Context,
+	email string,
+	credentials json.RawMessage,
+	userHandle string,
+	verifyURL string,
+) (*SignUpResult, error) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp codeResponse
+	err = c.post(ctx, "webauthn/register", map[string]any{
+		"provider":    WebAuthnProvider,
+		"challenge":   pkce.Challenge,
+		"email":       email,
+		"credentials": credentials,
+		"user_handle": userHandle,
+		"verify_url":  verifyURL,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.signUpResult(ctx, pkce, resp.Code)
+}
+
+// WebAuthnSignIn signs in with the assertion returned by the browser.
+func (c *Client) WebAuthnSignIn(
+	ctx context.Context,
+	email string,
+	assertion json.RawMessage,
+) (*TokenData, error) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	var resp codeResponse
+	err = c.post(ctx, "webauthn/authenticate", map[string]any{
+		"provider":  WebAuthnProvider,
+		"challenge": pkce.Challenge,
+		"email":     email,
+		"assertion": assertion,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExchangeCode(ctx, resp.Code, pkce.Verifier)
+}
@@ -0,0 +1,33 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelcfg
+
+// BranchType determines what is copied into a new branch.
+//
+// See [github.com/geldata/gel-go.Client.CreateBranch].
+type BranchType string
+
+const (
+	// EmptyBranch creates a branch without schema or data.
+	EmptyBranch BranchType = "EMPTY"
+
+	// SchemaBranch copies the schema of the source branch.
+	SchemaBranch BranchType = "SCHEMA"
+
+	// DataBranch copies the schema and data of the source branch.
+	DataBranch BranchType = "DATA"
+)
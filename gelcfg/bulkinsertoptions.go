@@ -0,0 +1,81 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelcfg
+
+// NewBulkInsertOptions returns the default BulkInsertOptions value with
+// maxRows set to 1,000, maxBytes set to 4 MiB and continueOnError set to
+// false.
+func NewBulkInsertOptions() BulkInsertOptions {
+	return BulkInsertOptions{
+		fromFactory: true,
+		maxRows:     1_000,
+		maxBytes:    4 << 20,
+	}
+}
+
+// BulkInsertOptions controls how
+// [github.com/geldata/gel-go.Client.BulkInsert] splits rows into chunks.
+// Each chunk is inserted by a single query in its own transaction.
+type BulkInsertOptions struct {
+	// fromFactory indicates that a BulkInsertOptions value was created using
+	// NewBulkInsertOptions() and not created directly with
+	// BulkInsertOptions{}.
+	fromFactory bool
+
+	maxRows         int
+	maxBytes        int
+	continueOnError bool
+}
+
+// WithMaxRows sets the max number of rows in a chunk. Defaults to 1,000.
+func (o BulkInsertOptions) WithMaxRows(maxRows int) BulkInsertOptions {
+	o.maxRows = maxRows
+	return o
+}
+
+// WithMaxBytes sets the approximate max size of a chunk's encoded rows. A
+// chunk always has at least one row even if the row is larger than maxBytes.
+// Defaults to 4 MiB.
+func (o BulkInsertOptions) WithMaxBytes(maxBytes int) BulkInsertOptions {
+	o.maxBytes = maxBytes
+	return o
+}
+
+// WithContinueOnError enables inserting the remaining chunks after a chunk
+// failed. By default no more chunks are inserted after the first failure.
+func (o BulkInsertOptions) WithContinueOnError(
+	continueOnError bool,
+) BulkInsertOptions {
+	o.continueOnError = continueOnError
+	return o
+}
+
+// MaxRows returns the max number of rows in a chunk.
+func (o BulkInsertOptions) MaxRows() int { return o.maxRows }
+
+// MaxBytes returns the approximate max size of a chunk.
+func (o BulkInsertOptions) MaxBytes() int { return o.maxBytes }
+
+// ContinueOnError returns true if chunks are inserted after a chunk failed.
+func (o BulkInsertOptions) ContinueOnError() bool { return o.continueOnError }
+
+// IsValid returns true if the BulkInsertOptions was created with
+// NewBulkInsertOptions().
+//
+// This method is intended for internal use only and is not subject to semantic
+// visioning guarantees.
+func (o BulkInsertOptions) IsValid() bool { return o.fromFactory }
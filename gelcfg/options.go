@@ -18,7 +18,9 @@ package gelcfg
 
 import (
 	"errors"
+	"io"
 	"log"
+	"log/slog"
 	"time"
 
 	types "github.com/geldata/gel-go/geltypes"
@@ -144,6 +146,24 @@ type Options struct {
 	// WarningHandler is invoked when Gel returns warnings. Defaults to
 	// gelcfg.LogWarnings.
 	WarningHandler WarningHandler
+
+	// WireTrace receives every protocol frame sent and received by the
+	// client's connections as JSON lines, typically an [os.File]. Frames
+	// include the message type, payload and timing. Authentication messages
+	// and the secret key are redacted. Traces can be served back to a client
+	// with [github.com/geldata/gel-go/gelwire.Replay].
+	//
+	// WireTrace must be safe to use for as long as the client is open.
+	WireTrace io.Writer
+
+	// Logger receives debug messages about the wire protocol: messages sent
+	// and received, capabilities, cardinalities, descriptor IDs, cache
+	// decisions, retries and reconnects. Passwords, secret keys and SCRAM
+	// messages are never logged.
+	//
+	// If Logger is nil and the GEL_GO_DEBUG environment variable contains
+	// protocol, debug messages are written to stderr.
+	Logger *slog.Logger
 }
 
 // TLSOptions contains the parameters needed to configure TLS on Gel
@@ -0,0 +1,251 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelfake
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/geldata/gel-go/internal/introspect"
+)
+
+// fieldFunc finds the struct field for a shape element or column name.
+type fieldFunc func(reflect.Type, string) (reflect.StructField, bool)
+
+// decoder copies canned results into out arguments.
+type decoder struct {
+	field fieldFunc
+}
+
+// decode copies src into dst which must be addressable.
+func (d decoder) decode(dst, src reflect.Value) error {
+	if !src.IsValid() {
+		if setMissing(dst) {
+			return nil
+		}
+
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		default:
+			return fmt.Errorf("cannot decode nil into %v", dst.Type())
+		}
+	}
+
+	if src.Kind() == reflect.Interface || src.Kind() == reflect.Pointer {
+		if src.IsNil() {
+			return d.decode(dst, reflect.Value{})
+		}
+
+		if src.Type().AssignableTo(dst.Type()) {
+			dst.Set(src)
+			return nil
+		}
+
+		return d.decode(dst, src.Elem())
+	}
+
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	if inner, ok := introspect.OptionalType(dst.Type()); ok {
+		if src.Type().AssignableTo(inner) ||
+			src.Type().ConvertibleTo(inner) && convertible(src.Type(), inner) {
+			val := reflect.New(inner).Elem()
+			if err := d.decode(val, src); err != nil {
+				return err
+			}
+
+			dst.Addr().MethodByName("Set").Call([]reflect.Value{val})
+			return nil
+		}
+	}
+
+	if convertible(src.Type(), dst.Type()) {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+
+	switch {
+	case dst.Kind() == reflect.Slice &&
+		(src.Kind() == reflect.Slice || src.Kind() == reflect.Array):
+		return d.decodeSlice(dst, src)
+	case dst.Kind() == reflect.Struct && src.Kind() == reflect.Struct:
+		return d.decodeStruct(dst, src)
+	case dst.Kind() == reflect.Struct &&
+		src.Kind() == reflect.Map &&
+		src.Type().Key().Kind() == reflect.String:
+		return d.decodeMap(dst, src)
+	default:
+		return fmt.Errorf("cannot decode %v into %v", src.Type(), dst.Type())
+	}
+}
+
+func (d decoder) decodeSlice(dst, src reflect.Value) error {
+	slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+	for i := 0; i < src.Len(); i++ {
+		if err := d.decode(slice.Index(i), src.Index(i)); err != nil {
+			return fmt.Errorf("[%v]: %w", i, err)
+		}
+	}
+
+	dst.Set(slice)
+	return nil
+}
+
+func (d decoder) decodeMap(dst, src reflect.Value) error {
+	dst.Set(reflect.Zero(dst.Type()))
+	setPresent(dst)
+
+	iter := src.MapRange()
+	for iter.Next() {
+		name := iter.Key().String()
+		if err := d.decodeField(dst, name, iter.Value()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d decoder) decodeStruct(dst, src reflect.Value) error {
+	dst.Set(reflect.Zero(dst.Type()))
+	setPresent(dst)
+	return d.decodeStructFields(dst, src)
+}
+
+// decodeStructFields copies the fields of src into dst. src fields are
+// named by their gel tag or by their field name and $inline fields are
+// flattened.
+func (d decoder) decodeStructFields(dst, src reflect.Value) error {
+	t := src.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("gel")
+		if !ok {
+			tag = field.Tag.Get("edgedb")
+		}
+
+		if tag == "$inline" {
+			if err := d.decodeStructFields(dst, src.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = field.Name
+		}
+
+		// Embedded optional markers like geltypes.Optional are not shape
+		// elements.
+		if field.Anonymous && tag == "" {
+			continue
+		}
+
+		if err := d.decodeField(dst, name, src.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d decoder) decodeField(
+	dst reflect.Value,
+	name string,
+	val reflect.Value,
+) error {
+	field, ok := d.field(dst.Type(), name)
+	if !ok {
+		return fmt.Errorf(
+			"the \"out\" argument does not have a field with name %q", name)
+	}
+
+	// Offsets of fields found in $inline structs are relative to dst.
+	ptr := unsafe.Add(dst.Addr().UnsafePointer(), field.Offset)
+	out := reflect.NewAt(field.Type, ptr).Elem()
+	if err := d.decode(out, val); err != nil {
+		return fmt.Errorf("%v: %w", name, err)
+	}
+
+	return nil
+}
+
+// convertible returns true if values of type src can be converted to type
+// dst without changing their meaning, for example int to int64.
+func convertible(src, dst reflect.Type) bool {
+	if !src.ConvertibleTo(dst) {
+		return false
+	}
+
+	switch {
+	case isNumber(src.Kind()) && isNumber(dst.Kind()):
+		return true
+	case src.Kind() == reflect.String && dst.Kind() == reflect.String:
+		return true
+	case src.Kind() == reflect.Bool && dst.Kind() == reflect.Bool:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// setMissing sets an optional value to missing. It returns false if val is
+// not optional.
+func setMissing(val reflect.Value) bool {
+	ptr := val.Addr()
+	if m := ptr.MethodByName("SetMissing"); m.IsValid() {
+		val.Set(reflect.Zero(val.Type()))
+		m.Call([]reflect.Value{reflect.ValueOf(true)})
+		return true
+	}
+
+	if m := ptr.MethodByName("Unset"); m.IsValid() {
+		m.Call(nil)
+		return true
+	}
+
+	return false
+}
+
+// setPresent marks optional objects as present.
+func setPresent(val reflect.Value) {
+	if m := val.Addr().MethodByName("SetMissing"); m.IsValid() {
+		m.Call([]reflect.Value{reflect.ValueOf(false)})
+	}
+}
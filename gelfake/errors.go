@@ -0,0 +1,38 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelfake
+
+import (
+	"github.com/geldata/gel-go/gelerr"
+	gelerrint "github.com/geldata/gel-go/internal/gelerr"
+)
+
+// Error returns a gel error in category with the message msg. The error has
+// the same type, categories and tags as errors returned by the server.
+//
+//	fake.Expect(gelfake.Exact(query)).ReturnError(
+//		gelfake.Error(gelerr.ConstraintViolationError, "name violates ..."),
+//	)
+func Error(category gelerr.ErrorCategory, msg string) error {
+	return gelerrint.ErrorFromCategory(category, msg)
+}
+
+// TransactionConflictError returns a retryable
+// [gelerr.TransactionConflictError].
+func TransactionConflictError(msg string) error {
+	return Error(gelerr.TransactionConflictError, msg)
+}
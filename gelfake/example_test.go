@@ -0,0 +1,68 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelfake_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/geldata/gel-go/gelfake"
+	"github.com/geldata/gel-go/geltypes"
+)
+
+type User struct {
+	ID   geltypes.UUID `gel:"id"`
+	Name string        `gel:"name"`
+}
+
+// userNames is the code under test.
+func userNames(ctx context.Context, db geltypes.Executor) ([]string, error) {
+	var users []User
+	err := db.Query(ctx, "SELECT User { name } FILTER .active", &users)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Name
+	}
+
+	return names, nil
+}
+
+func Example() {
+	fake := gelfake.New()
+	fake.Expect(gelfake.Normalized(`
+		SELECT User {
+			name
+		}
+		FILTER .active
+	`)).Return([]map[string]any{{"name": "alice"}, {"name": "bob"}})
+
+	names, err := userNames(context.Background(), fake)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(names)
+	fmt.Println(len(fake.Calls()))
+	// Output:
+	// [alice bob]
+	// 1
+}
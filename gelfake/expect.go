@@ -0,0 +1,234 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelfake
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Matcher matches query text.
+type Matcher interface {
+	// Match returns true if the query matches.
+	Match(query string) bool
+
+	fmt.Stringer
+}
+
+// Exact returns a Matcher for queries that are exactly equal to query.
+func Exact(query string) Matcher { return exact(query) }
+
+type exact string
+
+func (m exact) Match(query string) bool { return query == string(m) }
+
+func (m exact) String() string { return fmt.Sprintf("%q", string(m)) }
+
+// Regexp returns a Matcher for queries that match the regular expression
+// pattern. It panics if pattern can not be compiled.
+func Regexp(pattern string) Matcher {
+	return regexpMatcher{regexp.MustCompile(pattern)}
+}
+
+type regexpMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexpMatcher) Match(query string) bool {
+	return m.re.MatchString(query)
+}
+
+func (m regexpMatcher) String() string { return "/" + m.re.String() + "/" }
+
+// Normalized returns a Matcher for queries that are equal to query after
+// both are normalized. Normalizing removes comments and a trailing
+// semicolon and collapses whitespace. Whitespace is only kept between
+// identifiers and keywords. String literals and quoted identifiers are
+// compared as is.
+func Normalized(query string) Matcher {
+	return normalized{query: query, normalized: Normalize(query)}
+}
+
+type normalized struct {
+	query      string
+	normalized string
+}
+
+func (m normalized) Match(query string) bool {
+	return Normalize(query) == m.normalized
+}
+
+func (m normalized) String() string { return fmt.Sprintf("%q", m.query) }
+
+// Normalize returns the normalized form of an EdgeQL query as used by
+// [Normalized].
+func Normalize(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	space := false
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			space = true
+			continue
+		}
+
+		if space && b.Len() > 0 && needsSpace(b.String(), c) {
+			b.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			n := quoted(query[i:], c)
+			b.WriteString(query[i : i+n])
+			i += n
+		case isIdentByte(c):
+			n := i
+			for n < len(query) && isIdentByte(query[n]) {
+				n++
+			}
+			b.WriteString(query[i:n])
+			i = n
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return strings.TrimSuffix(strings.TrimSpace(b.String()), ";")
+}
+
+// needsSpace returns true if whitespace between the already written text and
+// the next byte is significant.
+func needsSpace(written string, next byte) bool {
+	return isIdentByte(written[len(written)-1]) && isIdentByte(next)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' ||
+		'a' <= c && c <= 'z' ||
+		'A' <= c && c <= 'Z' ||
+		'0' <= c && c <= '9'
+}
+
+// quoted returns the length of the quoted string at the start of s.
+func quoted(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+
+	return len(s)
+}
+
+// Expectation is an expected query. Use [Executor.Expect] to create one.
+type Expectation struct {
+	matcher Matcher
+	args    []any
+	hasArgs bool
+	result  any
+	err     error
+	times   int
+	calls   int
+}
+
+// WithArgs restricts the expectation to queries with arguments equal to
+// args. Arguments are compared with [reflect.DeepEqual]. Without WithArgs
+// any arguments are accepted.
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args = args
+	e.hasArgs = true
+	return e
+}
+
+// Return sets the result. For Query methods it must be a slice. Results are
+// decoded into the out argument using the same struct field rules as
+// [github.com/geldata/gel-go.Client]. Structs and map[string]any values can
+// be used for objects.
+func (e *Expectation) Return(result any) *Expectation {
+	e.result = result
+	return e
+}
+
+// ReturnError sets the error that is returned instead of a result. Use
+// [Error] or [TransactionConflictError] to create gel errors.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Times limits how often the expectation can be matched. After it has been
+// matched n times later queries are matched against the next expectations.
+// Without Times an expectation can be matched any number of times.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) matches(query string, args []any) bool {
+	if e.times > 0 && e.calls >= e.times {
+		return false
+	}
+
+	if !e.matcher.Match(query) {
+		return false
+	}
+
+	if !e.hasArgs {
+		return true
+	}
+
+	if len(args) == 0 && len(e.args) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(args, e.args)
+}
+
+func (e *Expectation) unmet() bool {
+	if e.times > 0 {
+		return e.calls < e.times
+	}
+
+	return e.calls == 0
+}
+
+func (e *Expectation) expectedCalls() string {
+	if e.times > 0 {
+		return fmt.Sprintf("%v times", e.times)
+	}
+
+	return "at least once"
+}
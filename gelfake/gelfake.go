@@ -0,0 +1,401 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelfake provides an in-memory [geltypes.Executor] for unit tests.
+//
+// An [Executor] doesn't connect to a database. Queries are matched against
+// expectations that return canned results or errors and every call is
+// recorded so that tests can assert on the queries that were run.
+//
+//	fake := gelfake.New()
+//	fake.Expect(gelfake.Normalized("SELECT User { name }")).
+//		Return([]User{{Name: "alice"}})
+//
+//	var users []User
+//	err := fake.Query(ctx, "SELECT User {\n\tname\n}", &users)
+package gelfake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/gelerr"
+	"github.com/geldata/gel-go/geltypes"
+	gelerrint "github.com/geldata/gel-go/internal/gelerr"
+	"github.com/geldata/gel-go/internal/introspect"
+)
+
+var (
+	_ geltypes.Tx               = (*Executor)(nil)
+	_ geltypes.SingleSQLQuerier = (*Executor)(nil)
+)
+
+// Call is a recorded query.
+type Call struct {
+	// Method is the name of the Executor method that was called,
+	// for example QuerySingle.
+	Method string
+
+	// Query is the query text.
+	Query string
+
+	// Args are the query arguments.
+	Args []any
+}
+
+// Executor is a fake [geltypes.Executor] and [geltypes.Tx].
+// It is safe for concurrent use.
+type Executor struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+	retryOptions gelcfg.RetryOptions
+}
+
+// New returns an Executor without any expectations.
+func New() *Executor {
+	return &Executor{retryOptions: gelcfg.NewRetryOptions()}
+}
+
+// WithRetryOptions sets the retry options used by [Executor.Tx].
+func (e *Executor) WithRetryOptions(opts gelcfg.RetryOptions) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.retryOptions = opts
+	return e
+}
+
+// Expect adds an expectation for queries matched by m. Expectations are
+// checked in the order they were added and the first one that matches the
+// query and its arguments is used.
+func (e *Executor) Expect(m Matcher) *Expectation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	exp := &Expectation{matcher: m}
+	e.expectations = append(e.expectations, exp)
+	return exp
+}
+
+// Calls returns the queries that have been run in the order they were run.
+func (e *Executor) Calls() []Call {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]Call(nil), e.calls...)
+}
+
+// Reset removes all expectations and recorded calls.
+func (e *Executor) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.expectations = nil
+	e.calls = nil
+}
+
+// ExpectationsWereMet returns an error if an expectation was called
+// fewer times than expected.
+func (e *Executor) ExpectationsWereMet() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for _, exp := range e.expectations {
+		if exp.unmet() {
+			errs = append(errs, fmt.Errorf(
+				"gelfake: expected %v to be called %v, called %v times",
+				exp.matcher, exp.expectedCalls(), exp.calls))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Tx runs action with the Executor as its transaction. If action returns an
+// error tagged with [gelerr.ShouldRetry], like a TransactionConflictError,
+// it is run again according to the Executor's retry options. The backoff
+// between attempts is skipped.
+func (e *Executor) Tx(ctx context.Context, action geltypes.TxBlock) error {
+	e.mu.Lock()
+	opts := e.retryOptions
+	e.mu.Unlock()
+
+	for i := 1; true; i++ {
+		err := action(ctx, e)
+
+		var gelErr gelerr.Error
+		if !errors.As(err, &gelErr) || !gelErr.HasTag(gelerr.ShouldRetry) {
+			return err
+		}
+
+		rule, ruleErr := opts.RuleForException(gelErr)
+		if ruleErr != nil {
+			return ruleErr
+		}
+
+		if i >= rule.Attempts() {
+			return err
+		}
+	}
+
+	return gelerrint.NewClientError("unreachable", nil)
+}
+
+// Execute runs a query that doesn't return results.
+func (e *Executor) Execute(
+	ctx context.Context,
+	cmd string,
+	args ...any,
+) error {
+	_, err := e.call(ctx, "Execute", cmd, args)
+	return err
+}
+
+// ExecuteSQL runs a SQL query that doesn't return results.
+func (e *Executor) ExecuteSQL(
+	ctx context.Context,
+	cmd string,
+	args ...any,
+) error {
+	_, err := e.call(ctx, "ExecuteSQL", cmd, args)
+	return err
+}
+
+// Query decodes the expected result into out which must be a pointer to a
+// slice.
+func (e *Executor) Query(
+	ctx context.Context,
+	cmd string,
+	out any,
+	args ...any,
+) error {
+	return e.query(ctx, "Query", cmd, out, args, introspect.StructField)
+}
+
+// QuerySQL decodes the expected result into out which must be a pointer to
+// a slice. Struct fields are matched like
+// [github.com/geldata/gel-go.Client.QuerySQL] matches columns.
+func (e *Executor) QuerySQL(
+	ctx context.Context,
+	cmd string,
+	out any,
+	args ...any,
+) error {
+	return e.query(ctx, "QuerySQL", cmd, out, args, introspect.SQLColumnField)
+}
+
+// QuerySingle decodes the expected result into out. If the result is nil a
+// [gelerr.NoDataError] is returned unless out is an optional type, in which
+// case out is set to missing.
+func (e *Executor) QuerySingle(
+	ctx context.Context,
+	cmd string,
+	out any,
+	args ...any,
+) error {
+	return e.querySingle(
+		ctx, "QuerySingle", cmd, out, args, introspect.StructField)
+}
+
+// QuerySingleSQL is like [Executor.QuerySingle] for SQL queries.
+func (e *Executor) QuerySingleSQL(
+	ctx context.Context,
+	cmd string,
+	out any,
+	args ...any,
+) error {
+	return e.querySingle(
+		ctx, "QuerySingleSQL", cmd, out, args, introspect.SQLColumnField)
+}
+
+// QueryJSON encodes the expected result as JSON into out. A nil result is
+// encoded as an empty array.
+func (e *Executor) QueryJSON(
+	ctx context.Context,
+	cmd string,
+	out *[]byte,
+	args ...any,
+) error {
+	result, err := e.call(ctx, "QueryJSON", cmd, args)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		result = []any{}
+	}
+
+	return encodeJSON(result, out)
+}
+
+// QuerySingleJSON encodes the expected result as JSON into out which must be
+// a *[]byte or a geltypes.OptionalBytes. A nil result returns a
+// [gelerr.NoDataError] unless out is optional.
+func (e *Executor) QuerySingleJSON(
+	ctx context.Context,
+	cmd string,
+	out any,
+	args ...any,
+) error {
+	result, err := e.call(ctx, "QuerySingleJSON", cmd, args)
+	if err != nil {
+		return err
+	}
+
+	switch out := out.(type) {
+	case *[]byte:
+		if result == nil {
+			return gelerrint.NewNoDataError("zero results", nil)
+		}
+		return encodeJSON(result, out)
+	case *geltypes.OptionalBytes:
+		if result == nil {
+			out.Unset()
+			return nil
+		}
+
+		var data []byte
+		if err := encodeJSON(result, &data); err != nil {
+			return err
+		}
+		out.Set(data)
+		return nil
+	default:
+		return gelerrint.NewInterfaceError(fmt.Sprintf(
+			"the \"out\" argument must be *[]byte or *OptionalBytes, got %T",
+			out), nil)
+	}
+}
+
+func encodeJSON(result any, out *[]byte) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return gelerrint.NewInvalidArgumentError(err.Error(), nil)
+	}
+
+	*out = data
+	return nil
+}
+
+func (e *Executor) query(
+	ctx context.Context,
+	method, cmd string,
+	out any,
+	args []any,
+	fieldFn fieldFunc,
+) error {
+	result, err := e.call(ctx, method, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	val, err := introspect.ValueOfSlice(out)
+	if err != nil {
+		return gelerrint.NewInterfaceError("", err)
+	}
+
+	if result == nil {
+		val.Set(reflect.MakeSlice(val.Type(), 0, 0))
+		return nil
+	}
+
+	src := reflect.ValueOf(result)
+	if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+		return gelerrint.NewInvalidArgumentError(fmt.Sprintf(
+			"gelfake: %v result must be a slice, got %T", method, result),
+			nil)
+	}
+
+	d := decoder{field: fieldFn}
+	if err := d.decode(val, src); err != nil {
+		return gelerrint.NewInvalidArgumentError(err.Error(), nil)
+	}
+
+	return nil
+}
+
+func (e *Executor) querySingle(
+	ctx context.Context,
+	method, cmd string,
+	out any,
+	args []any,
+	fieldFn fieldFunc,
+) error {
+	result, err := e.call(ctx, method, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	val, err := introspect.ValueOf(out)
+	if err != nil {
+		return gelerrint.NewInterfaceError("", err)
+	}
+
+	if result == nil {
+		if !setMissing(val) {
+			return gelerrint.NewNoDataError("zero results", nil)
+		}
+		return nil
+	}
+
+	d := decoder{field: fieldFn}
+	if err := d.decode(val, reflect.ValueOf(result)); err != nil {
+		return gelerrint.NewInvalidArgumentError(err.Error(), nil)
+	}
+
+	return nil
+}
+
+// call records the call and returns the result of the first matching
+// expectation.
+func (e *Executor) call(
+	ctx context.Context,
+	method, cmd string,
+	args []any,
+) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls = append(e.calls, Call{Method: method, Query: cmd, Args: args})
+
+	for _, exp := range e.expectations {
+		if !exp.matches(cmd, args) {
+			continue
+		}
+
+		exp.calls++
+		return exp.result, exp.err
+	}
+
+	return nil, fmt.Errorf(
+		"gelfake: unexpected %v call: %q with args %v",
+		method,
+		strings.TrimSpace(cmd),
+		args,
+	)
+}
@@ -0,0 +1,278 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelfake
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/gelerr"
+	"github.com/geldata/gel-go/geltypes"
+)
+
+type Named struct {
+	Name string `gel:"name"`
+}
+
+type User struct {
+	ID    geltypes.UUID `gel:"id"`
+	Named `gel:"$inline"`
+	Email geltypes.OptionalStr `gel:"email"`
+	Age   int64                `gel:"age"`
+}
+
+type OptionalUser struct {
+	geltypes.Optional
+	Name string `gel:"name"`
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT 1;", "SELECT 1"},
+		{
+			"select User {\n\tname, # the name\n\temail\n}\n",
+			"select User{name,email}",
+		},
+		{"SELECT 'a  b' ++ \"c  d\"", "SELECT'a  b'++\"c  d\""},
+		{"SELECT `a  b`", "SELECT`a  b`"},
+		{"SELECT '#' ++ 'it\\'s  #'", "SELECT'#'++'it\\'s  #'"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, Normalize(test.query), test.query)
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	assert.True(t, Exact("SELECT 1").Match("SELECT 1"))
+	assert.False(t, Exact("SELECT 1").Match("SELECT  1"))
+	assert.True(t, Regexp(`^SELECT \d$`).Match("SELECT 1"))
+	assert.False(t, Regexp(`^SELECT \d$`).Match("SELECT 12"))
+	assert.True(t, Normalized("SELECT User {name}").Match(
+		"SELECT User {\n  name\n};"))
+	assert.False(t, Normalized("SELECT User {name}").Match(
+		"SELECT user {name}"))
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+	fake.Expect(Normalized("SELECT User { name, email, age }")).Return(
+		[]map[string]any{
+			{"name": "alice", "email": "a@example.com", "age": 30},
+			{"name": "bob", "email": nil, "age": 40},
+		},
+	)
+
+	var users []User
+	err := fake.Query(ctx, "SELECT User {name, email, age}", &users)
+	require.NoError(t, err)
+
+	require.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].Name)
+	assert.Equal(t, geltypes.NewOptionalStr("a@example.com"), users[0].Email)
+	assert.Equal(t, int64(30), users[0].Age)
+	assert.Equal(t, "bob", users[1].Name)
+	assert.Equal(t, geltypes.OptionalStr{}, users[1].Email)
+}
+
+func TestQueryStructResult(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+
+	type row struct {
+		Named `gel:"$inline"`
+		Email string `gel:"email"`
+	}
+	fake.Expect(Exact("SELECT User")).Return([]row{
+		{Named: Named{Name: "alice"}, Email: "a@example.com"},
+	})
+
+	var users []User
+	require.NoError(t, fake.Query(ctx, "SELECT User", &users))
+	require.Len(t, users, 1)
+	assert.Equal(t, "alice", users[0].Name)
+	assert.Equal(t, geltypes.NewOptionalStr("a@example.com"), users[0].Email)
+}
+
+func TestQueryUnknownField(t *testing.T) {
+	fake := New()
+	fake.Expect(Exact("SELECT User")).
+		Return([]map[string]any{{"nick": "a"}})
+
+	var users []User
+	err := fake.Query(context.Background(), "SELECT User", &users)
+	assert.EqualError(t, err, "gel.InvalidArgumentError: [0]: "+
+		`the "out" argument does not have a field with name "nick"`)
+}
+
+func TestQuerySingle(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+	fake.Expect(Exact("SELECT 1")).Return(1)
+	fake.Expect(Exact("SELECT <str>{}")).Return(nil)
+	fake.Expect(Exact("SELECT User LIMIT 1")).Return(nil)
+
+	var n int64
+	require.NoError(t, fake.QuerySingle(ctx, "SELECT 1", &n))
+	assert.Equal(t, int64(1), n)
+
+	var s string
+	err := fake.QuerySingle(ctx, "SELECT <str>{}", &s)
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr))
+	assert.True(t, gelErr.Category(gelerr.NoDataError))
+
+	o := geltypes.NewOptionalStr("x")
+	require.NoError(t, fake.QuerySingle(ctx, "SELECT <str>{}", &o))
+	assert.Equal(t, geltypes.OptionalStr{}, o)
+
+	user := OptionalUser{Name: "x"}
+	user.SetMissing(false)
+	require.NoError(t, fake.QuerySingle(ctx, "SELECT User LIMIT 1", &user))
+	assert.True(t, user.Missing())
+	assert.Equal(t, "", user.Name)
+}
+
+func TestQuerySQL(t *testing.T) {
+	fake := New()
+	fake.Expect(Exact("SELECT user_id FROM t")).
+		Return([]map[string]any{{"user_id": 7}})
+
+	var rows []struct{ UserID int32 }
+	err := fake.QuerySQL(context.Background(), "SELECT user_id FROM t", &rows)
+	require.NoError(t, err)
+	assert.Equal(t, []struct{ UserID int32 }{{UserID: 7}}, rows)
+}
+
+func TestQueryJSON(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+	fake.Expect(Exact("SELECT User")).Return([]Named{{Name: "a"}})
+	fake.Expect(Exact("SELECT User LIMIT 1")).Return(nil)
+
+	var data []byte
+	require.NoError(t, fake.QueryJSON(ctx, "SELECT User", &data))
+	assert.Equal(t, `[{"Name":"a"}]`, string(data))
+
+	var single geltypes.OptionalBytes
+	err := fake.QuerySingleJSON(ctx, "SELECT User LIMIT 1", &single)
+	require.NoError(t, err)
+	_, ok := single.Get()
+	assert.False(t, ok)
+}
+
+func TestArgs(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+	fake.Expect(Exact("SELECT <str>$0")).WithArgs("a").Return("first")
+	fake.Expect(Exact("SELECT <str>$0")).Return("other")
+
+	var s string
+	require.NoError(t, fake.QuerySingle(ctx, "SELECT <str>$0", &s, "a"))
+	assert.Equal(t, "first", s)
+	require.NoError(t, fake.QuerySingle(ctx, "SELECT <str>$0", &s, "b"))
+	assert.Equal(t, "other", s)
+
+	assert.Equal(t, []Call{
+		{Method: "QuerySingle", Query: "SELECT <str>$0", Args: []any{"a"}},
+		{Method: "QuerySingle", Query: "SELECT <str>$0", Args: []any{"b"}},
+	}, fake.Calls())
+}
+
+func TestUnexpectedQuery(t *testing.T) {
+	fake := New()
+	err := fake.Execute(context.Background(), "DELETE User", "x")
+	assert.EqualError(t, err,
+		`gelfake: unexpected Execute call: "DELETE User" with args [x]`)
+}
+
+func TestTimesAndExpectationsWereMet(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+	fake.Expect(Exact("DELETE User")).Times(2)
+	fake.Expect(Exact("SELECT 1"))
+
+	require.NoError(t, fake.Execute(ctx, "DELETE User"))
+	assert.EqualError(t, fake.ExpectationsWereMet(),
+		`gelfake: expected "DELETE User" to be called 2 times, called 1 times`+
+			"\n"+`gelfake: expected "SELECT 1" to be called at least once, `+
+			"called 0 times")
+
+	require.NoError(t, fake.Execute(ctx, "DELETE User"))
+	require.Error(t, fake.Execute(ctx, "DELETE User"))
+	require.NoError(t, fake.Execute(ctx, "SELECT 1"))
+	assert.NoError(t, fake.ExpectationsWereMet())
+}
+
+func TestErrors(t *testing.T) {
+	err := Error(gelerr.ConstraintViolationError, "name violates exclusivity")
+	assert.EqualError(t, err,
+		"gel.ConstraintViolationError: name violates exclusivity")
+
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr))
+	assert.True(t, gelErr.Category(gelerr.ConstraintViolationError))
+	assert.True(t, gelErr.Category(gelerr.ExecutionError))
+	assert.False(t, gelErr.HasTag(gelerr.ShouldRetry))
+
+	err = TransactionConflictError("conflict")
+	require.True(t, errors.As(err, &gelErr))
+	assert.True(t, gelErr.Category(gelerr.TransactionConflictError))
+	assert.True(t, gelErr.HasTag(gelerr.ShouldRetry))
+}
+
+func TestTxRetries(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+	fake.Expect(Exact("UPDATE User")).
+		ReturnError(TransactionConflictError("conflict")).
+		Times(2)
+	fake.Expect(Exact("UPDATE User"))
+
+	attempts := 0
+	err := fake.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+		attempts++
+		return tx.Execute(ctx, "UPDATE User")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestTxGivesUp(t *testing.T) {
+	ctx := context.Background()
+	fake := New().WithRetryOptions(gelcfg.NewRetryOptions().
+		WithDefault(gelcfg.NewRetryRule().WithAttempts(1)))
+	fake.Expect(Exact("UPDATE User")).
+		ReturnError(TransactionConflictError("conflict"))
+
+	attempts := 0
+	err := fake.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+		attempts++
+		return tx.Execute(ctx, "UPDATE User")
+	})
+	assert.EqualError(t, err, "gel.TransactionConflictError: conflict")
+	assert.Equal(t, 1, attempts)
+}
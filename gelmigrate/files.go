@@ -0,0 +1,124 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelmigrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// fileName matches migration file names like 00001.edgeql or
+	// 00001-m1fqtauhtvc2.edgeql.
+	fileName = regexp.MustCompile(`^(\d+)(?:-[^.]*)?\.edgeql$`)
+
+	// header matches the start of a migration file.
+	header = regexp.MustCompile(
+		`(?i)^(?:\s+|#[^\n]*\n)*CREATE\s+MIGRATION\s+(\w+)\s+ONTO\s+(\w+)`)
+)
+
+// readMigrations reads the migration files in dir in order. The files must be
+// numbered consecutively starting at 1 and each migration must be onto the
+// previous one.
+func readMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type numbered struct {
+		num  int
+		name string
+	}
+
+	var files []numbered
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		num, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, numbered{num, entry.Name()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].num < files[j].num
+	})
+
+	migrations := make([]Migration, len(files))
+	parent := "initial"
+	for i, file := range files {
+		if file.num != i+1 {
+			return nil, fmt.Errorf(
+				"expected migration file number %v in %v, found %v",
+				i+1, dir, file.name,
+			)
+		}
+
+		path := filepath.Join(dir, file.name)
+		m, err := readMigration(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.EqualFold(m.Parent, parent) {
+			return nil, fmt.Errorf(
+				"migration %v in %v is onto %v, expected %v",
+				m.Name, path, m.Parent, parent,
+			)
+		}
+
+		migrations[i] = m
+		parent = m.Name
+	}
+
+	return migrations, nil
+}
+
+func readMigration(path string) (Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	match := header.FindSubmatch(data)
+	if match == nil {
+		return Migration{}, fmt.Errorf(
+			"%v does not start with CREATE MIGRATION", path)
+	}
+
+	return Migration{
+		Name:   string(match[1]),
+		Parent: string(match[2]),
+		File:   path,
+		ddl:    string(data),
+	}, nil
+}
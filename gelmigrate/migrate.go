@@ -0,0 +1,270 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelmigrate applies the migrations of a Gel project without the gel
+// CLI.
+//
+// Migrations are the .edgeql files created by gel migration create. By
+// default the migrations directory is found the same way the CLI and
+// edgeql-go find it: the closest gel.toml or edgedb.toml at or above the
+// current working directory is read and its schema-dir setting (dbschema by
+// default) is used.
+//
+// The client decides which instance and branch are migrated, so credentials
+// are resolved the same way as in [github.com/geldata/gel-go.CreateClient].
+//
+//	client, err := gel.CreateClient(gelcfg.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	_, err = gelmigrate.Apply(ctx, client, gelmigrate.Options{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Each migration is applied in its own transaction. A migration that was
+// applied concurrently, for example by another replica of the same service,
+// is skipped. Fixup migrations and dev mode migrations are not supported.
+package gelmigrate
+
+import (
+	"context"
+	"fmt"
+
+	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/project"
+)
+
+// Options configures which migrations are applied.
+type Options struct {
+	// Dir is the migrations directory. If it is empty the migrations
+	// directory of the project that contains the current working directory
+	// is used.
+	Dir string
+
+	// To is the name of the last migration to apply. All pending migrations
+	// are applied if it is empty.
+	To string
+
+	// DryRun makes Apply return the migrations it would apply without
+	// applying them.
+	DryRun bool
+}
+
+// Migration is a migration file.
+type Migration struct {
+	// Name is the migration's name, for example m1fqtauhtvc2w56wh....
+	Name string
+
+	// Parent is the name of the previous migration or initial.
+	Parent string
+
+	// File is the path of the migration file.
+	File string
+
+	// Applied is true if the migration is applied to the client's branch.
+	Applied bool
+
+	ddl string
+}
+
+// Status returns the migrations in order with their Applied field set.
+func Status(
+	ctx context.Context,
+	client *gel.Client,
+	opts Options,
+) ([]Migration, error) {
+	dir, err := migrationsDir(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := readMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	err = client.Query(ctx, `SELECT schema::Migration.name`, &applied)
+	if err != nil {
+		return nil, err
+	}
+
+	return markApplied(dir, migrations, applied)
+}
+
+// Pending returns the migrations that are not applied.
+func Pending(migrations []Migration) []Migration {
+	var pending []Migration
+	for _, m := range migrations {
+		if !m.Applied {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending
+}
+
+// Apply applies the pending migrations in order up to and including
+// opts.To. It returns the migrations that were applied or, if opts.DryRun
+// is set, the migrations that would be applied.
+func Apply(
+	ctx context.Context,
+	client *gel.Client,
+	opts Options,
+) ([]Migration, error) {
+	migrations, err := Status(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := planApply(migrations, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	var done []Migration
+	for _, m := range plan {
+		applied, err := apply(ctx, client, m)
+		if err != nil {
+			return done, fmt.Errorf(
+				"applying migration %v from %v: %w", m.Name, m.File, err)
+		}
+
+		if applied {
+			m.Applied = true
+			done = append(done, m)
+		}
+	}
+
+	return done, nil
+}
+
+// apply applies m unless it was applied concurrently.
+func apply(
+	ctx context.Context,
+	client *gel.Client,
+	m Migration,
+) (bool, error) {
+	var applied bool
+	err := client.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+		var exists bool
+		err := tx.QuerySingle(
+			ctx,
+			`SELECT EXISTS (
+				SELECT schema::Migration FILTER .name = <str>$0
+			)`,
+			&exists,
+			m.Name,
+		)
+		if err != nil || exists {
+			applied = false
+			return err
+		}
+
+		applied = true
+		return tx.Execute(ctx, m.ddl)
+	})
+
+	return applied, err
+}
+
+func migrationsDir(opts Options) (string, error) {
+	if opts.Dir != "" {
+		return opts.Dir, nil
+	}
+
+	p, err := project.Find(".")
+	if err != nil {
+		return "", err
+	}
+
+	return p.MigrationsDir, nil
+}
+
+// markApplied sets the Applied field of migrations. The applied migrations
+// must be a prefix of the migration files.
+func markApplied(
+	dir string,
+	migrations []Migration,
+	applied []string,
+) ([]Migration, error) {
+	isApplied := make(map[string]bool, len(applied))
+	for _, name := range applied {
+		isApplied[name] = true
+	}
+
+	count := 0
+	for i := range migrations {
+		if !isApplied[migrations[i].Name] {
+			continue
+		}
+
+		if i != count {
+			return nil, fmt.Errorf(
+				"migration %v is applied but %v before it is not",
+				migrations[i].Name, migrations[count].Name,
+			)
+		}
+
+		migrations[i].Applied = true
+		count++
+	}
+
+	if count != len(applied) {
+		for _, name := range applied {
+			found := false
+			for _, m := range migrations[:count] {
+				found = found || m.Name == name
+			}
+
+			if !found {
+				return nil, fmt.Errorf(
+					"the database has migration %v which is not in %v",
+					name, dir,
+				)
+			}
+		}
+	}
+
+	return migrations, nil
+}
+
+// planApply returns the pending migrations up to and including to.
+func planApply(migrations []Migration, to string) ([]Migration, error) {
+	end := len(migrations)
+	if to != "" {
+		end = -1
+		for i, m := range migrations {
+			if m.Name == to {
+				end = i + 1
+				break
+			}
+		}
+
+		if end == -1 {
+			return nil, fmt.Errorf("migration %v does not exist", to)
+		}
+	}
+
+	return Pending(migrations[:end]), nil
+}
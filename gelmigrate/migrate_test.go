@@ -0,0 +1,133 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelmigrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func migrationNames(migrations []Migration) []string {
+	names := make([]string, len(migrations))
+	for i, m := range migrations {
+		names[i] = m.Name
+	}
+
+	return names
+}
+
+var validFiles = map[string]string{
+	"00001-m1aaa.edgeql": "CREATE MIGRATION m1aaa\n    ONTO initial\n{};",
+	"00002.edgeql":       "# comment\nCREATE MIGRATION m1bbb ONTO m1aaa {};",
+	"00010-m1ccc.edgeql": "create migration m1ccc onto m1bbb {};",
+	"README.md":          "not a migration",
+}
+
+func TestReadMigrations(t *testing.T) {
+	files := map[string]string{}
+	for name, content := range validFiles {
+		files[name] = content
+	}
+	delete(files, "00010-m1ccc.edgeql")
+	files["00003-m1ccc.edgeql"] = validFiles["00010-m1ccc.edgeql"]
+	dir := writeMigrations(t, files)
+
+	migrations, err := readMigrations(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m1aaa", "m1bbb", "m1ccc"},
+		migrationNames(migrations))
+	assert.Equal(t, "initial", migrations[0].Parent)
+	assert.Equal(t, filepath.Join(dir, "00002.edgeql"), migrations[1].File)
+	assert.Equal(t, files["00002.edgeql"], migrations[1].ddl)
+}
+
+func TestReadMigrationsErrors(t *testing.T) {
+	dir := writeMigrations(t, validFiles)
+	_, err := readMigrations(dir)
+	assert.EqualError(t, err, "expected migration file number 3 in "+
+		dir+", found 00010-m1ccc.edgeql")
+
+	dir = writeMigrations(t, map[string]string{
+		"00001.edgeql": "CREATE MIGRATION m1aaa ONTO initial {};",
+		"00002.edgeql": "CREATE MIGRATION m1bbb ONTO m1xxx {};",
+	})
+	_, err = readMigrations(dir)
+	assert.EqualError(t, err, "migration m1bbb in "+
+		filepath.Join(dir, "00002.edgeql")+
+		" is onto m1xxx, expected m1aaa")
+
+	dir = writeMigrations(t, map[string]string{
+		"00001.edgeql": "CREATE TYPE User;",
+	})
+	_, err = readMigrations(dir)
+	assert.EqualError(t, err, filepath.Join(dir, "00001.edgeql")+
+		" does not start with CREATE MIGRATION")
+}
+
+func TestMarkApplied(t *testing.T) {
+	migrations := func() []Migration {
+		return []Migration{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	}
+
+	result, err := markApplied("dir", migrations(), []string{"b", "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, migrationNames(Pending(result)))
+
+	_, err = markApplied("dir", migrations(), []string{"a", "c"})
+	assert.EqualError(t, err, "migration c is applied but b before it is not")
+
+	_, err = markApplied("dir", migrations(), []string{"a", "x"})
+	assert.EqualError(t, err,
+		"the database has migration x which is not in dir")
+}
+
+func TestPlanApply(t *testing.T) {
+	migrations := []Migration{
+		{Name: "a", Applied: true},
+		{Name: "b"},
+		{Name: "c"},
+	}
+
+	plan, err := planApply(migrations, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, migrationNames(plan))
+
+	plan, err = planApply(migrations, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, migrationNames(plan))
+
+	plan, err = planApply(migrations, "a")
+	require.NoError(t, err)
+	assert.Empty(t, plan)
+
+	_, err = planApply(migrations, "x")
+	assert.EqualError(t, err, "migration x does not exist")
+}
@@ -0,0 +1,195 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelplan describes query plans returned by the analyze statement.
+//
+// Use [github.com/geldata/gel-go.Client.Analyze] to analyze a query.
+// [Plan.Render] writes a plan as text with the query source each node came
+// from highlighted, which is suitable for logs and slow query reports.
+package gelplan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	gel "github.com/geldata/gel-go/internal/client"
+)
+
+// Plan is a query plan.
+type Plan struct {
+	// Buffers are the source texts that spans refer to. The first buffer is
+	// the analyzed query.
+	Buffers []string `json:"buffers"`
+
+	// Root is the top node of the plan tree.
+	Root *Node `json:"fine_grained"`
+}
+
+// Node is a node in the plan tree. It consists of the steps that evaluate a
+// part of the query and the nodes for its subqueries.
+type Node struct {
+	// ID identifies the node.
+	ID string `json:"plan_id"`
+
+	// Steps are executed to produce the node's results. The first step is
+	// the outermost.
+	Steps []Step `json:"pipeline"`
+
+	// Children are the nodes for subqueries.
+	Children []*Node `json:"subplans"`
+
+	// Spans are the parts of the query source the node was compiled from.
+	Spans []Span `json:"contexts"`
+}
+
+// Step is a single step of a node as planned and executed by the database.
+// Costs are in the planner's arbitrary units and times are in milliseconds.
+type Step struct {
+	// Type is the kind of step, for example Seq Scan or Hash Join.
+	Type string `json:"plan_type"`
+
+	// StartupCost is the estimated cost before the first row is returned.
+	StartupCost float64 `json:"startup_cost"`
+
+	// TotalCost is the estimated cost of returning all rows.
+	TotalCost float64 `json:"total_cost"`
+
+	// EstimatedRows is the number of rows the planner expected.
+	EstimatedRows float64 `json:"plan_rows"`
+
+	// EstimatedWidth is the average row size in bytes the planner
+	// expected.
+	EstimatedWidth float64 `json:"plan_width"`
+
+	// ActualStartupTime is the time it took to return the first row.
+	ActualStartupTime float64 `json:"actual_startup_time"`
+
+	// ActualTotalTime is the time it took to return all rows.
+	ActualTotalTime float64 `json:"actual_total_time"`
+
+	// ActualRows is the average number of rows returned per loop.
+	ActualRows float64 `json:"actual_rows"`
+
+	// Loops is the number of times the step was executed.
+	Loops float64 `json:"actual_loops"`
+
+	// Properties are additional details about the step.
+	Properties []Property `json:"properties"`
+}
+
+// Property is a detail of a step, for example the relation that is scanned.
+type Property struct {
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	Value     any    `json:"value"`
+	Important bool   `json:"important"`
+}
+
+// Span is a part of a buffer. Start and End are byte offsets.
+type Span struct {
+	Buffer int    `json:"buffer_idx"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Text   string `json:"text"`
+}
+
+// Parse decodes the output of the analyze statement. data may be the plan
+// JSON object or a JSON string that contains it.
+func Parse(data []byte) (*Plan, error) {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		data = []byte(text)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("gelplan: invalid plan: %w", err)
+	}
+
+	if plan.Root == nil {
+		return nil, errors.New("gelplan: plan has no nodes")
+	}
+
+	return &plan, nil
+}
+
+// Walk calls fn for every node in depth first order. depth is 0 for the
+// root node. Walk stops when fn returns false.
+func (p *Plan) Walk(fn func(n *Node, depth int) bool) {
+	walk(p.Root, 0, fn)
+}
+
+func walk(n *Node, depth int, fn func(*Node, int) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	if !fn(n, depth) {
+		return false
+	}
+
+	for _, child := range n.Children {
+		if !walk(child, depth+1, fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Location is a span's position in its buffer.
+type Location struct {
+	// Line is the 1 based line the span starts on.
+	Line int
+	// Column is the 1 based column the span starts at counted in runes.
+	Column int
+	// Width is the number of runes of the span on its first line.
+	Width int
+	// Text is the span's first line with tabs replaced by spaces.
+	Text string
+}
+
+// Locate returns the position of s.
+func (p *Plan) Locate(s Span) (Location, bool) {
+	if s.Buffer < 0 || s.Buffer >= len(p.Buffers) {
+		return Location{}, false
+	}
+
+	buffer := p.Buffers[s.Buffer]
+	if s.End < s.Start || s.End > len(buffer) {
+		return Location{}, false
+	}
+
+	loc, ok := gel.LocateOffset(buffer, s.Start)
+	if !ok {
+		return Location{}, false
+	}
+
+	span := buffer[s.Start:s.End]
+	if i := strings.IndexByte(span, '\n'); i >= 0 {
+		span = span[:i]
+	}
+
+	return Location{
+		Line:   loc.Line,
+		Column: loc.Column,
+		Width:  max(1, utf8.RuneCountInString(span)),
+		Text:   loc.Text,
+	}, true
+}
@@ -0,0 +1,171 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelplan
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const planJSON = `{
+	"buffers": ["select User {\n\tname,\n\tfriends: { name }\n}"],
+	"fine_grained": {
+		"plan_id": "a",
+		"pipeline": [{
+			"plan_type": "SeqScan",
+			"startup_cost": 0,
+			"total_cost": 18.2,
+			"plan_rows": 820,
+			"plan_width": 32,
+			"actual_startup_time": 0.011,
+			"actual_total_time": 0.015,
+			"actual_rows": 3,
+			"actual_loops": 1,
+			"properties": [
+				{"title": "relation_name", "type": "relation",
+				 "value": "User", "important": true},
+				{"title": "alias", "type": "text",
+				 "value": "User~1", "important": false}
+			]
+		}],
+		"contexts": [{"buffer_idx": 0, "start": 7, "end": 41,
+			"text": "User"}],
+		"subplans": [{
+			"plan_id": "b",
+			"pipeline": [{
+				"plan_type": "IndexScan",
+				"startup_cost": 0.15,
+				"total_cost": 8.17,
+				"plan_rows": 1,
+				"plan_width": 32,
+				"actual_startup_time": 0.002,
+				"actual_total_time": 0.002,
+				"actual_rows": 0,
+				"actual_loops": 3
+			}],
+			"contexts": [{"buffer_idx": 0, "start": 22, "end": 38,
+				"text": "friends"}],
+			"subplans": []
+		}]
+	}
+}`
+
+func TestParse(t *testing.T) {
+	plan, err := Parse([]byte(planJSON))
+	require.NoError(t, err)
+
+	require.NotNil(t, plan.Root)
+	assert.Equal(t, "a", plan.Root.ID)
+	require.Len(t, plan.Root.Steps, 1)
+	assert.Equal(t, Step{
+		Type:              "SeqScan",
+		TotalCost:         18.2,
+		EstimatedRows:     820,
+		EstimatedWidth:    32,
+		ActualStartupTime: 0.011,
+		ActualTotalTime:   0.015,
+		ActualRows:        3,
+		Loops:             1,
+		Properties: []Property{
+			{
+				Title:     "relation_name",
+				Type:      "relation",
+				Value:     "User",
+				Important: true,
+			},
+			{Title: "alias", Type: "text", Value: "User~1"},
+		},
+	}, plan.Root.Steps[0])
+	require.Len(t, plan.Root.Children, 1)
+	assert.Equal(t, "b", plan.Root.Children[0].ID)
+
+	// The analyze statement may return the plan as a JSON string.
+	quoted, err := json.Marshal(planJSON)
+	require.NoError(t, err)
+	fromString, err := Parse(quoted)
+	require.NoError(t, err)
+	assert.Equal(t, plan, fromString)
+
+	_, err = Parse([]byte(`{"buffers": []}`))
+	assert.EqualError(t, err, "gelplan: plan has no nodes")
+
+	_, err = Parse([]byte(`[]`))
+	assert.Error(t, err)
+}
+
+func TestWalk(t *testing.T) {
+	plan, err := Parse([]byte(planJSON))
+	require.NoError(t, err)
+
+	var ids []string
+	var depths []int
+	plan.Walk(func(n *Node, depth int) bool {
+		ids = append(ids, n.ID)
+		depths = append(depths, depth)
+		return true
+	})
+	assert.Equal(t, []string{"a", "b"}, ids)
+	assert.Equal(t, []int{0, 1}, depths)
+
+	ids = nil
+	plan.Walk(func(n *Node, depth int) bool {
+		ids = append(ids, n.ID)
+		return false
+	})
+	assert.Equal(t, []string{"a"}, ids)
+}
+
+func TestLocate(t *testing.T) {
+	plan, err := Parse([]byte(planJSON))
+	require.NoError(t, err)
+
+	loc, ok := plan.Locate(plan.Root.Children[0].Spans[0])
+	require.True(t, ok)
+	assert.Equal(t, Location{
+		Line:   3,
+		Column: 2,
+		Width:  16,
+		Text:   " friends: { name }",
+	}, loc)
+
+	_, ok = plan.Locate(Span{Buffer: 1})
+	assert.False(t, ok)
+	_, ok = plan.Locate(Span{Start: 10, End: 5})
+	assert.False(t, ok)
+}
+
+func TestRender(t *testing.T) {
+	plan, err := Parse([]byte(planJSON))
+	require.NoError(t, err)
+
+	expected := `SeqScan (cost=0.00..18.20 rows=820 width=32) ` +
+		`(actual time=0.011..0.015 rows=3 loops=1)
+  relation_name: User
+  query:1:8
+  select User {
+         ^^^^^^
+  IndexScan (cost=0.15..8.17 rows=1 width=32) ` +
+		`(actual time=0.002..0.002 rows=0 loops=3)
+    query:3:2
+     friends: { name }
+     ^^^^^^^^^^^^^^^^
+`
+	assert.Equal(t, expected, plan.String())
+}
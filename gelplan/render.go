@@ -0,0 +1,95 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelplan
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Render writes the plan as indented text. Each node lists its steps with
+// estimated and actual costs followed by the query lines it was compiled
+// from with the span highlighted.
+//
+//	Seq Scan (cost=0.00..1.10 rows=10 width=32) (actual time=...)
+//	  relation_name: User
+//	  query:1:8
+//	  select User { name }
+//	         ^^^^^^^^^^^^^
+func (p *Plan) Render(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	p.Walk(func(n *Node, depth int) bool {
+		p.renderNode(b, n, strings.Repeat("  ", depth))
+		return true
+	})
+
+	return b.Flush()
+}
+
+// String returns the plan rendered as text.
+func (p *Plan) String() string {
+	var b strings.Builder
+	_ = p.Render(&b)
+	return b.String()
+}
+
+func (p *Plan) renderNode(w io.Writer, n *Node, indent string) {
+	for _, s := range n.Steps {
+		fmt.Fprintf(w,
+			"%s%s (cost=%.2f..%.2f rows=%.0f width=%.0f) "+
+				"(actual time=%.3f..%.3f rows=%.0f loops=%.0f)\n",
+			indent,
+			s.Type,
+			s.StartupCost,
+			s.TotalCost,
+			s.EstimatedRows,
+			s.EstimatedWidth,
+			s.ActualStartupTime,
+			s.ActualTotalTime,
+			s.ActualRows,
+			s.Loops,
+		)
+
+		for _, prop := range s.Properties {
+			if prop.Important {
+				fmt.Fprintf(w, "%s  %s: %v\n", indent, prop.Title, prop.Value)
+			}
+		}
+	}
+
+	for _, s := range n.Spans {
+		loc, ok := p.Locate(s)
+		if !ok {
+			continue
+		}
+
+		name := "query"
+		if s.Buffer > 0 {
+			name = fmt.Sprintf("buffer %v", s.Buffer)
+		}
+
+		fmt.Fprintf(w, "%s  %s:%v:%v\n%s  %s\n%s  %s%s\n",
+			indent, name, loc.Line, loc.Column,
+			indent, loc.Text,
+			indent,
+			strings.Repeat(" ", loc.Column-1),
+			strings.Repeat("^", loc.Width),
+		)
+	}
+}
@@ -0,0 +1,41 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelql_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/geldata/gel-go/gelql"
+)
+
+func ExampleSelect() {
+	query, args, err := gelql.Select(gelql.Ident("User")).
+		Shape(gelql.Fields("id")).
+		Filter(gelql.Path(".name").Like("a%")).
+		Limit(10).
+		Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(query)
+	fmt.Println(args...)
+	// Output:
+	// SELECT User { id } FILTER (.name LIKE <std::str>$0) LIMIT <std::int64>$1
+	// a% 10
+}
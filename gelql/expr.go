@@ -0,0 +1,291 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reserved are the EdgeQL reserved keywords. Identifiers that match one of
+// them must be quoted.
+var reserved = map[string]bool{
+	"__source__": true, "__std__": true, "__subject__": true,
+	"__type__": true, "abort": true, "alter": true, "analyze": true,
+	"and": true, "anyarray": true, "anyobject": true, "anytuple": true,
+	"anytype": true, "begin": true, "by": true, "case": true, "check": true,
+	"commit": true, "configure": true, "create": true, "deallocate": true,
+	"delete": true, "describe": true, "detached": true, "discard": true,
+	"distinct": true, "do": true, "drop": true, "else": true, "end": true,
+	"exists": true, "explain": true, "extending": true, "fetch": true,
+	"filter": true, "for": true, "get": true, "global": true, "grant": true,
+	"group": true, "if": true, "ilike": true, "import": true, "in": true,
+	"insert": true, "introspect": true, "is": true, "like": true,
+	"limit": true, "listen": true, "load": true, "lock": true, "match": true,
+	"module": true, "move": true, "never": true, "not": true, "notify": true,
+	"offset": true, "on": true, "optional": true, "or": true, "over": true,
+	"partition": true, "prepare": true, "raise": true, "refresh": true,
+	"reindex": true, "revoke": true, "rollback": true, "select": true,
+	"set": true, "single": true, "start": true, "typeof": true,
+	"union": true, "update": true, "variadic": true, "when": true,
+	"window": true, "with": true,
+}
+
+func isPlainIdent(name string) bool {
+	if name == "" || reserved[strings.ToLower(name)] {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeIdent writes name, quoting it if it isn't a plain identifier.
+func writeIdent(b *builder, name string) {
+	switch {
+	case name == "":
+		b.fail(fmt.Errorf("identifiers can not be empty"))
+	case isPlainIdent(name):
+		b.write(name)
+	default:
+		b.write("`" + strings.ReplaceAll(name, "`", "``") + "`")
+	}
+}
+
+// writeQualified writes a possibly module qualified name like default::User.
+func writeQualified(b *builder, name string) {
+	for i, part := range strings.Split(name, "::") {
+		if i > 0 {
+			b.write("::")
+		}
+		writeIdent(b, part)
+	}
+}
+
+type ident string
+
+func (i ident) render(b *builder) { writeQualified(b, string(i)) }
+
+// Ident returns an identifier like a type, alias or module qualified name.
+// Each part of the name is quoted if needed.
+func Ident(name string) Expr { return ident(name) }
+
+// PathExpr is a path like .name or User.friends.name. Its methods build
+// comparisons with the path on the left hand side.
+type PathExpr struct {
+	steps []string
+}
+
+// Path returns a path expression. A leading dot makes the path relative to
+// the enclosing statement's subject. The first step may be a module
+// qualified name and steps starting with @ are link properties. Each step is
+// quoted if needed.
+func Path(path string) PathExpr {
+	return PathExpr{steps: strings.Split(path, ".")}
+}
+
+func (p PathExpr) render(b *builder) {
+	for i, step := range p.steps {
+		if i > 0 {
+			b.write(".")
+		}
+
+		switch {
+		case i == 0 && step == "":
+			// relative path
+		case strings.HasPrefix(step, "@"):
+			b.write("@")
+			writeIdent(b, step[1:])
+		case i == 0:
+			writeQualified(b, step)
+		default:
+			writeIdent(b, step)
+		}
+	}
+}
+
+// Eq returns p = val.
+func (p PathExpr) Eq(val any) Expr { return Op(p, "=", value(val)) }
+
+// Neq returns p != val.
+func (p PathExpr) Neq(val any) Expr { return Op(p, "!=", value(val)) }
+
+// Lt returns p < val.
+func (p PathExpr) Lt(val any) Expr { return Op(p, "<", value(val)) }
+
+// Lte returns p <= val.
+func (p PathExpr) Lte(val any) Expr { return Op(p, "<=", value(val)) }
+
+// Gt returns p > val.
+func (p PathExpr) Gt(val any) Expr { return Op(p, ">", value(val)) }
+
+// Gte returns p >= val.
+func (p PathExpr) Gte(val any) Expr { return Op(p, ">=", value(val)) }
+
+// Like returns p LIKE pattern.
+func (p PathExpr) Like(pattern any) Expr {
+	return Op(p, "LIKE", value(pattern))
+}
+
+// ILike returns p ILIKE pattern.
+func (p PathExpr) ILike(pattern any) Expr {
+	return Op(p, "ILIKE", value(pattern))
+}
+
+// In returns p IN set. If set is a slice it is bound to an array parameter
+// and unpacked.
+func (p PathExpr) In(set any) Expr {
+	expr, ok := set.(Expr)
+	if !ok {
+		expr = Call("array_unpack", Param(set))
+	}
+
+	return Op(p, "IN", expr)
+}
+
+// Exists returns EXISTS p.
+func (p PathExpr) Exists() Expr { return Exists(p) }
+
+// operators are the binary operators accepted by [Op].
+var operators = map[string]bool{
+	"=": true, "!=": true, "?=": true, "?!=": true, "<": true, "<=": true,
+	">": true, ">=": true, "+": true, "-": true, "*": true, "/": true,
+	"//": true, "%": true, "^": true, "++": true, "??": true, "AND": true,
+	"OR": true, "LIKE": true, "ILIKE": true, "NOT LIKE": true,
+	"NOT ILIKE": true, "IN": true, "NOT IN": true, "UNION": true,
+	"INTERSECT": true, "EXCEPT": true, "IF": true,
+}
+
+type binary struct {
+	left  Expr
+	op    string
+	right Expr
+}
+
+func (o binary) render(b *builder) {
+	if !operators[o.op] {
+		b.fail(fmt.Errorf("unknown operator %q", o.op))
+		return
+	}
+
+	b.write("(")
+	o.left.render(b)
+	b.write(" " + o.op + " ")
+	o.right.render(b)
+	b.write(")")
+}
+
+// Op returns the binary operation left op right. op must be an EdgeQL
+// operator like "=", "<" or "++". The operation is parenthesized.
+func Op(left Expr, op string, right Expr) Expr {
+	return binary{left: left, op: strings.ToUpper(op), right: right}
+}
+
+type logical struct {
+	op    string
+	exprs []Expr
+}
+
+func (l logical) render(b *builder) {
+	switch len(l.exprs) {
+	case 0:
+		// An empty AND is true and an empty OR is false.
+		if l.op == "AND" {
+			b.write("true")
+		} else {
+			b.write("false")
+		}
+		return
+	case 1:
+		l.exprs[0].render(b)
+		return
+	}
+
+	b.write("(")
+	for i, expr := range l.exprs {
+		if i > 0 {
+			b.write(" " + l.op + " ")
+		}
+		expr.render(b)
+	}
+	b.write(")")
+}
+
+// And returns the conjunction of exprs.
+func And(exprs ...Expr) Expr { return logical{op: "AND", exprs: exprs} }
+
+// Or returns the disjunction of exprs.
+func Or(exprs ...Expr) Expr { return logical{op: "OR", exprs: exprs} }
+
+type prefix struct {
+	op   string
+	expr Expr
+}
+
+func (p prefix) render(b *builder) {
+	b.write("(" + p.op + " ")
+	p.expr.render(b)
+	b.write(")")
+}
+
+// Not returns NOT expr.
+func Not(expr Expr) Expr { return prefix{op: "NOT", expr: expr} }
+
+// Exists returns EXISTS expr.
+func Exists(expr Expr) Expr { return prefix{op: "EXISTS", expr: expr} }
+
+type call struct {
+	fn   string
+	args []Expr
+}
+
+func (c call) render(b *builder) {
+	writeQualified(b, c.fn)
+	b.write("(")
+	for i, arg := range c.args {
+		if i > 0 {
+			b.write(", ")
+		}
+		arg.render(b)
+	}
+	b.write(")")
+}
+
+// Call returns a function call like count(.friends). fn may be module
+// qualified.
+func Call(fn string, args ...Expr) Expr { return call{fn: fn, args: args} }
+
+type cast struct {
+	typ  string
+	expr Expr
+}
+
+func (c cast) render(b *builder) {
+	b.write("<" + c.typ + ">")
+	c.expr.render(b)
+}
+
+// Cast returns <typ>expr.
+func Cast(typ string, expr Expr) Expr { return cast{typ: typ, expr: expr} }
@@ -0,0 +1,255 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelql builds EdgeQL statements programmatically.
+//
+// Values are never interpolated into the statement text. Every value is bound
+// to a query parameter with a type cast inferred from its go type, so the
+// text of a statement only depends on its structure. Statements that differ
+// only in their values render to the same text and share the client's query
+// caches.
+//
+//	query, args, err := gelql.Select(gelql.Ident("default::User")).
+//		Shape(gelql.Fields("name", "email")).
+//		Filter(gelql.Path(".name").ILike(pattern)).
+//		OrderBy(gelql.Path(".name"), gelql.Asc).
+//		Limit(10).
+//		Build()
+//	if err != nil {
+//		return err
+//	}
+//
+//	err = client.Query(ctx, query, &users, args...)
+//
+// Parameters are positional ($0, $1, ...) unless [Named] is used, in which
+// case every parameter in the statement must be named and the arguments are
+// returned as a single map[string]any.
+package gelql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/geldata/gel-go/internal/introspect"
+)
+
+// Expr is an EdgeQL expression.
+type Expr interface {
+	render(b *builder)
+}
+
+// Statement is a complete EdgeQL statement.
+type Statement interface {
+	Expr
+
+	// Build returns the statement text and the arguments to pass to the
+	// client's query methods.
+	Build() (string, []any, error)
+}
+
+// builder accumulates the statement text and its arguments.
+type builder struct {
+	text  strings.Builder
+	args  []any
+	named map[string]any
+	err   error
+
+	// bare is set when the next statement is rendered in a context that
+	// doesn't need parentheses.
+	bare bool
+
+	// useNamed is set when the statement binds named parameters, so
+	// parameters the builder adds itself must be named too.
+	useNamed bool
+
+	// hasNamed records that a named parameter was bound.
+	hasNamed bool
+}
+
+func (b *builder) write(s string) { b.text.WriteString(s) }
+
+func (b *builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *builder) bind(typ string, val any) {
+	if b.named != nil {
+		b.fail(fmt.Errorf(
+			"positional and named parameters can not be mixed"))
+		return
+	}
+
+	b.write("<" + typ + ">$" + strconv.Itoa(len(b.args)))
+	b.args = append(b.args, val)
+}
+
+func (b *builder) bindNamed(name, typ string, val any) {
+	b.hasNamed = true
+	if b.args != nil {
+		b.fail(fmt.Errorf(
+			"positional and named parameters can not be mixed"))
+		return
+	}
+
+	if b.named == nil {
+		b.named = make(map[string]any)
+	}
+
+	if prev, ok := b.named[name]; ok &&
+		!reflect.DeepEqual(prev, val) {
+		b.fail(fmt.Errorf(
+			"parameter %q is bound to different values", name))
+		return
+	}
+
+	b.named[name] = val
+	b.write("<" + typ + ">$")
+	writeIdent(b, name)
+}
+
+// bindReserved binds a parameter the builder adds on its own, like the
+// value of a LIMIT clause. It is positional unless the statement uses
+// named parameters in which case it is named $__<kind>, with a numeric
+// suffix if the name is already taken.
+func (b *builder) bindReserved(kind, typ string, val any) {
+	if !b.useNamed {
+		b.bind(typ, val)
+		return
+	}
+
+	name := "__" + kind
+	for i := 2; ; i++ {
+		if _, ok := b.named[name]; !ok {
+			break
+		}
+		name = fmt.Sprintf("__%s%d", kind, i)
+	}
+
+	b.bindNamed(name, typ, val)
+}
+
+func build(stmt Expr) (string, []any, error) {
+	// Named parameters may be rendered after the parameters the builder
+	// adds itself, so find out which mode is in use first.
+	probe := &builder{}
+	stmt.render(probe)
+
+	b := &builder{useNamed: probe.hasNamed}
+	stmt.render(b)
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	if b.named != nil {
+		return b.text.String(), []any{b.named}, nil
+	}
+
+	return b.text.String(), b.args, nil
+}
+
+// Render returns the text of an expression and the arguments it binds.
+func Render(expr Expr) (string, []any, error) { return build(expr) }
+
+// paramType returns the cast for a parameter with the value val.
+func paramType(val any) (string, error) {
+	if val == nil {
+		return "", fmt.Errorf("can not infer the type of a nil parameter")
+	}
+
+	t := reflect.TypeOf(val)
+	if name, ok := introspect.ScalarTypeName(t); ok {
+		return name, nil
+	}
+
+	if inner, ok := introspect.OptionalType(t); ok {
+		if name, ok := introspect.ScalarTypeName(inner); ok {
+			return "OPTIONAL " + name, nil
+		}
+	}
+
+	if t.Kind() == reflect.Slice {
+		if name, ok := introspect.ScalarTypeName(t.Elem()); ok {
+			return "array<" + name + ">", nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"can not infer the type of a %T parameter, use TypedParam", val)
+}
+
+type param struct {
+	name string
+	typ  string
+	val  any
+}
+
+func (p param) render(b *builder) {
+	typ := p.typ
+	if typ == "" {
+		var err error
+		typ, err = paramType(p.val)
+		if err != nil {
+			b.fail(err)
+			return
+		}
+	}
+
+	if p.name == "" {
+		b.bind(typ, p.val)
+	} else {
+		b.bindNamed(p.name, typ, p.val)
+	}
+}
+
+// Param binds val to a positional parameter. The parameter's type is
+// inferred from val's go type, for example a string is cast to <std::str>
+// and a geltypes.OptionalInt64 is cast to <OPTIONAL std::int64>.
+func Param(val any) Expr { return param{val: val} }
+
+// TypedParam binds val to a positional parameter cast to typ. Use it for
+// types that can't be inferred, like enums or custom scalars.
+func TypedParam(typ string, val any) Expr { return param{typ: typ, val: val} }
+
+// Named binds val to the named parameter $name. The type is inferred like
+// in [Param]. Using the same name more than once binds the same value.
+func Named(name string, val any) Expr { return param{name: name, val: val} }
+
+// TypedNamed binds val to the named parameter $name cast to typ.
+func TypedNamed(name, typ string, val any) Expr {
+	return param{name: name, typ: typ, val: val}
+}
+
+// value converts val to an expression. Expressions are used as is and any
+// other value is bound to a parameter.
+func value(val any) Expr {
+	if expr, ok := val.(Expr); ok {
+		return expr
+	}
+
+	return Param(val)
+}
+
+type raw string
+
+func (r raw) render(b *builder) { b.write(string(r)) }
+
+// Raw returns an expression with the given text. The text is used verbatim
+// and must not contain untrusted input.
+func Raw(text string) Expr { return raw(text) }
@@ -0,0 +1,221 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelql
+
+import (
+	"testing"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelect(t *testing.T) {
+	query, args, err := Select(Ident("default::User")).
+		Shape(
+			Fields("id", "name", "select"),
+			Computed("friend_count", Call("count", Path(".friends"))),
+			Link("friends", Fields("name")).
+				Filter(Path(".name").Neq("Bob")).
+				OrderBy(Path(".name"), Asc).
+				Limit(3),
+		).
+		Filter(Path(".name").ILike("a%")).
+		Filter(Or(Path(".age").Gte(int64(18)), Not(Path(".minor").Exists()))).
+		OrderBy(Path(".name"), Asc).
+		OrderBy(Path(".@rank"), Desc).
+		Offset(20).
+		Limit(10).
+		Build()
+	require.NoError(t, err)
+
+	expected := "SELECT default::User { id, name, `select`, " +
+		"friend_count := count(.friends), " +
+		"friends: { name } FILTER (.name != <std::str>$0) " +
+		"ORDER BY .name ASC LIMIT <std::int64>$1 } " +
+		"FILTER ((.name ILIKE <std::str>$2) AND " +
+		"((.age >= <std::int64>$3) OR (NOT (EXISTS .minor)))) " +
+		"ORDER BY .name ASC THEN .@rank DESC " +
+		"OFFSET <std::int64>$4 LIMIT <std::int64>$5"
+	assert.Equal(t, expected, query)
+	assert.Equal(t, []any{
+		"Bob", int64(3), "a%", int64(18), int64(20), int64(10),
+	}, args)
+}
+
+func TestCanonicalText(t *testing.T) {
+	build := func(name string, limit int64) (string, []any) {
+		query, args, err := Select(Ident("User")).
+			Shape(Fields("name")).
+			Filter(Path(".name").Eq(name)).
+			Limit(limit).
+			Build()
+		require.NoError(t, err)
+		return query, args
+	}
+
+	query1, args1 := build("Alice", 1)
+	query2, args2 := build("Bob", 2)
+	assert.Equal(t, query1, query2)
+	assert.NotEqual(t, args1, args2)
+}
+
+func TestNamedParams(t *testing.T) {
+	query, args, err := Select(Ident("User")).
+		Filter(And(
+			Path(".name").Eq(Named("name", "Alice")),
+			Path(".nick").Eq(Named("name", "Alice")),
+			Path(".status").Eq(TypedNamed("status", "Status", "active")),
+		)).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "SELECT User FILTER ((.name = <std::str>$name) AND "+
+		"(.nick = <std::str>$name) AND (.status = <Status>$status))", query)
+	assert.Equal(t, []any{map[string]any{
+		"name":   "Alice",
+		"status": "active",
+	}}, args)
+
+	_, _, err = Select(Ident("User")).
+		Filter(Path(".name").Eq(Named("name", "Alice"))).
+		Filter(Path(".nick").Eq("Bob")).
+		Build()
+	assert.EqualError(t, err,
+		"positional and named parameters can not be mixed")
+
+	_, _, err = Select(Ident("User")).
+		Filter(Path(".name").Eq(Named("name", "Alice"))).
+		Filter(Path(".nick").Eq(Named("name", "Bob"))).
+		Build()
+	assert.EqualError(t, err,
+		`parameter "name" is bound to different values`)
+}
+
+func TestNamedParamsWithLimit(t *testing.T) {
+	query, args, err := Select(Ident("User")).
+		Shape(Link("friends", Fields("name")).Limit(3)).
+		Filter(Path(".name").Eq(Named("name", "Alice"))).
+		Offset(20).
+		Limit(10).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "SELECT User { friends: { name } "+
+		"LIMIT <std::int64>$__limit } "+
+		"FILTER (.name = <std::str>$name) "+
+		"OFFSET <std::int64>$__offset LIMIT <std::int64>$__limit2", query)
+	assert.Equal(t, []any{map[string]any{
+		"name":     "Alice",
+		"__limit":  int64(3),
+		"__offset": int64(20),
+		"__limit2": int64(10),
+	}}, args)
+}
+
+func TestParamTypes(t *testing.T) {
+	samples := []struct {
+		val      any
+		expected string
+	}{
+		{"a", "<std::str>$0"},
+		{int32(1), "<std::int32>$0"},
+		{types.UUID{}, "<std::uuid>$0"},
+		{types.NewOptionalInt64(1), "<OPTIONAL std::int64>$0"},
+		{[]string{"a"}, "<array<std::str>>$0"},
+		{[]byte("a"), "<std::bytes>$0"},
+		{types.Vector{1}, "<ext::pgvector::vector>$0"},
+		{
+			types.NewOptionalSparseVector(types.SparseVector{}),
+			"<OPTIONAL ext::pgvector::sparsevec>$0",
+		},
+	}
+
+	for _, sample := range samples {
+		text, args, err := Render(Param(sample.val))
+		require.NoError(t, err)
+		assert.Equal(t, sample.expected, text)
+		assert.Equal(t, []any{sample.val}, args)
+	}
+
+	_, _, err := Render(Param(1))
+	assert.EqualError(t, err,
+		"can not infer the type of a int parameter, use TypedParam")
+
+	_, _, err = Render(Param(nil))
+	assert.EqualError(t, err, "can not infer the type of a nil parameter")
+}
+
+func TestInsert(t *testing.T) {
+	query, args, err := Insert("User").
+		Set("name", "Alice").
+		Set("friends", Select(Ident("User")).
+			Filter(Path(".name").In([]string{"Bob", "Carol"}))).
+		UnlessConflict(Path(".name"), Select(Ident("User"))).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "INSERT User { name := <std::str>$0, friends := "+
+		"(SELECT User FILTER (.name IN "+
+		"array_unpack(<array<std::str>>$1))) } "+
+		"UNLESS CONFLICT ON .name ELSE (SELECT User)", query)
+	assert.Equal(t, []any{"Alice", []string{"Bob", "Carol"}}, args)
+}
+
+func TestUpdateDelete(t *testing.T) {
+	query, args, err := Update("User").
+		Filter(Path(".name").Eq("Alice")).
+		Set("name", Op(Path(".name"), "++", Param("!"))).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE User FILTER (.name = <std::str>$0) "+
+		"SET { name := (.name ++ <std::str>$1) }", query)
+	assert.Equal(t, []any{"Alice", "!"}, args)
+
+	_, _, err = Update("User").Build()
+	assert.EqualError(t, err,
+		"update statements must set at least one value")
+
+	query, args, err = Delete("User").
+		Filter(Path(".name").Like("test%")).
+		Limit(5).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE User FILTER (.name LIKE <std::str>$0) "+
+		"LIMIT <std::int64>$1", query)
+	assert.Equal(t, []any{"test%", int64(5)}, args)
+}
+
+func TestForWith(t *testing.T) {
+	query, args, err := For(
+		"name",
+		Call("array_unpack", Param([]string{"a", "b"})),
+		Insert("User").Set("name", Ident("name")),
+	).
+		With("prefix", Param("x")).
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, "WITH prefix := <std::str>$0 "+
+		"FOR name IN array_unpack(<array<std::str>>$1) "+
+		"UNION (INSERT User { name := name })", query)
+	assert.Equal(t, []any{"x", []string{"a", "b"}}, args)
+}
+
+func TestInvalidOperator(t *testing.T) {
+	_, _, err := Render(Op(Path(".a"), "; DROP", Param("x")))
+	assert.EqualError(t, err, `unknown operator "; DROP"`)
+}
@@ -0,0 +1,120 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelql
+
+// ShapeElement is an element of a shape.
+type ShapeElement interface {
+	renderElement(b *builder)
+}
+
+type fields []string
+
+func (f fields) renderElement(b *builder) {
+	for i, name := range f {
+		if i > 0 {
+			b.write(", ")
+		}
+		writeElementName(b, name)
+	}
+}
+
+// writeElementName writes a shape element name which may be a link
+// property.
+func writeElementName(b *builder, name string) {
+	if len(name) > 0 && name[0] == '@' {
+		b.write("@")
+		name = name[1:]
+	}
+
+	writeIdent(b, name)
+}
+
+// Fields returns shape elements that select the named properties or links.
+func Fields(names ...string) ShapeElement { return fields(names) }
+
+type computed struct {
+	name string
+	expr Expr
+}
+
+func (c computed) renderElement(b *builder) {
+	writeElementName(b, c.name)
+	b.write(" := ")
+	c.expr.render(b)
+}
+
+// Computed returns the shape element name := expr.
+func Computed(name string, expr Expr) ShapeElement {
+	return computed{name: name, expr: expr}
+}
+
+// LinkShape is a link with a nested shape. Like [SelectStatement] the linked
+// objects can be filtered, ordered and paginated.
+type LinkShape struct {
+	name  string
+	shape []ShapeElement
+	clauses
+}
+
+// Link returns a shape element that selects the link name with the nested
+// shape.
+func Link(name string, shape ...ShapeElement) *LinkShape {
+	return &LinkShape{name: name, shape: shape}
+}
+
+// Filter adds a filter to the linked objects. Multiple filters are combined
+// with AND.
+func (l *LinkShape) Filter(expr Expr) *LinkShape {
+	l.filters = append(l.filters, expr)
+	return l
+}
+
+// OrderBy adds an ordering to the linked objects.
+func (l *LinkShape) OrderBy(expr Expr, dir Direction) *LinkShape {
+	l.order = append(l.order, ordering{expr: expr, dir: dir})
+	return l
+}
+
+// Offset skips the first n linked objects.
+func (l *LinkShape) Offset(n int64) *LinkShape {
+	l.offset = &n
+	return l
+}
+
+// Limit limits the number of linked objects.
+func (l *LinkShape) Limit(n int64) *LinkShape {
+	l.limit = &n
+	return l
+}
+
+func (l *LinkShape) renderElement(b *builder) {
+	writeElementName(b, l.name)
+	b.write(": ")
+	renderShape(b, l.shape)
+	l.clauses.render(b)
+}
+
+func renderShape(b *builder, shape []ShapeElement) {
+	b.write("{ ")
+	for i, elem := range shape {
+		if i > 0 {
+			b.write(", ")
+		}
+		elem.renderElement(b)
+	}
+	b.write(" }")
+}
@@ -0,0 +1,418 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelql
+
+import "fmt"
+
+// Direction is the direction of an ORDER BY expression.
+type Direction string
+
+const (
+	// Asc orders from smallest to largest.
+	Asc Direction = "ASC"
+
+	// Desc orders from largest to smallest.
+	Desc Direction = "DESC"
+)
+
+type ordering struct {
+	expr Expr
+	dir  Direction
+}
+
+// clauses are the FILTER, ORDER BY, OFFSET and LIMIT clauses shared by
+// statements and link shapes.
+type clauses struct {
+	filters []Expr
+	order   []ordering
+	offset  *int64
+	limit   *int64
+}
+
+func (c *clauses) render(b *builder) {
+	if len(c.filters) > 0 {
+		b.write(" FILTER ")
+		And(c.filters...).render(b)
+	}
+
+	for i, o := range c.order {
+		if i == 0 {
+			b.write(" ORDER BY ")
+		} else {
+			b.write(" THEN ")
+		}
+
+		o.expr.render(b)
+		switch o.dir {
+		case Asc, Desc:
+			b.write(" " + string(o.dir))
+		default:
+			b.fail(fmt.Errorf("unknown order direction %q", o.dir))
+		}
+	}
+
+	if c.offset != nil {
+		b.write(" OFFSET ")
+		b.bindReserved("offset", "std::int64", *c.offset)
+	}
+
+	if c.limit != nil {
+		b.write(" LIMIT ")
+		b.bindReserved("limit", "std::int64", *c.limit)
+	}
+}
+
+type alias struct {
+	name string
+	expr Expr
+}
+
+// with holds the aliases of a WITH block.
+type with []alias
+
+func (w with) render(b *builder) {
+	if len(w) == 0 {
+		return
+	}
+
+	b.write("WITH ")
+	for i, a := range w {
+		if i > 0 {
+			b.write(", ")
+		}
+
+		writeIdent(b, a.name)
+		b.write(" := ")
+		a.expr.render(b)
+	}
+	b.write(" ")
+}
+
+// subquery renders a statement used as an expression inside another
+// statement.
+func subquery(b *builder, render func(b *builder)) {
+	if b.text.Len() == 0 || b.bare {
+		b.bare = false
+		render(b)
+		return
+	}
+
+	b.write("(")
+	render(b)
+	b.write(")")
+}
+
+// SelectStatement is a SELECT statement.
+type SelectStatement struct {
+	with    with
+	subject Expr
+	shape   []ShapeElement
+	clauses
+}
+
+// Select returns a statement that selects subject. subject is usually an
+// [Ident] naming an object type.
+func Select(subject Expr) *SelectStatement {
+	return &SelectStatement{subject: subject}
+}
+
+// With adds the alias name := expr to the statement's WITH block.
+func (s *SelectStatement) With(name string, expr Expr) *SelectStatement {
+	s.with = append(s.with, alias{name: name, expr: expr})
+	return s
+}
+
+// Shape appends elements to the statement's shape.
+func (s *SelectStatement) Shape(elems ...ShapeElement) *SelectStatement {
+	s.shape = append(s.shape, elems...)
+	return s
+}
+
+// Filter adds a filter. Multiple filters are combined with AND.
+func (s *SelectStatement) Filter(expr Expr) *SelectStatement {
+	s.filters = append(s.filters, expr)
+	return s
+}
+
+// OrderBy adds an ordering. Orderings are applied in the order they are
+// added.
+func (s *SelectStatement) OrderBy(expr Expr, dir Direction) *SelectStatement {
+	s.order = append(s.order, ordering{expr: expr, dir: dir})
+	return s
+}
+
+// Offset skips the first n results. n is bound to a parameter.
+func (s *SelectStatement) Offset(n int64) *SelectStatement {
+	s.offset = &n
+	return s
+}
+
+// Limit limits the number of results to n. n is bound to a parameter.
+func (s *SelectStatement) Limit(n int64) *SelectStatement {
+	s.limit = &n
+	return s
+}
+
+func (s *SelectStatement) render(b *builder) {
+	subquery(b, func(b *builder) {
+		s.with.render(b)
+		b.write("SELECT ")
+		s.subject.render(b)
+		if len(s.shape) > 0 {
+			b.write(" ")
+			renderShape(b, s.shape)
+		}
+		s.clauses.render(b)
+	})
+}
+
+// Build returns the statement text and its arguments.
+func (s *SelectStatement) Build() (string, []any, error) { return build(s) }
+
+type assignment struct {
+	name string
+	expr Expr
+}
+
+func renderAssignments(b *builder, set []assignment) {
+	b.write("{ ")
+	for i, a := range set {
+		if i > 0 {
+			b.write(", ")
+		}
+
+		writeIdent(b, a.name)
+		b.write(" := ")
+		a.expr.render(b)
+	}
+	b.write(" }")
+}
+
+// InsertStatement is an INSERT statement.
+type InsertStatement struct {
+	with     with
+	typeName string
+	set      []assignment
+
+	conflict   bool
+	conflictOn Expr
+	conflictEl Expr
+}
+
+// Insert returns a statement that inserts an object of type typeName.
+func Insert(typeName string) *InsertStatement {
+	return &InsertStatement{typeName: typeName}
+}
+
+// With adds the alias name := expr to the statement's WITH block.
+func (s *InsertStatement) With(name string, expr Expr) *InsertStatement {
+	s.with = append(s.with, alias{name: name, expr: expr})
+	return s
+}
+
+// Set assigns val to the property or link name. val is bound to a parameter
+// unless it is an [Expr].
+func (s *InsertStatement) Set(name string, val any) *InsertStatement {
+	s.set = append(s.set, assignment{name: name, expr: value(val)})
+	return s
+}
+
+// UnlessConflict adds an UNLESS CONFLICT clause. on may be nil to handle
+// conflicts on any exclusive constraint and otherwise may be nil to skip
+// conflicting objects.
+func (s *InsertStatement) UnlessConflict(on, otherwise Expr) *InsertStatement {
+	s.conflict = true
+	s.conflictOn = on
+	s.conflictEl = otherwise
+	return s
+}
+
+func (s *InsertStatement) render(b *builder) {
+	subquery(b, func(b *builder) {
+		s.with.render(b)
+		b.write("INSERT ")
+		writeQualified(b, s.typeName)
+		b.write(" ")
+		renderAssignments(b, s.set)
+
+		if !s.conflict {
+			return
+		}
+
+		b.write(" UNLESS CONFLICT")
+		if s.conflictOn != nil {
+			b.write(" ON ")
+			s.conflictOn.render(b)
+		}
+
+		if s.conflictEl != nil {
+			b.write(" ELSE ")
+			s.conflictEl.render(b)
+		}
+	})
+}
+
+// Build returns the statement text and its arguments.
+func (s *InsertStatement) Build() (string, []any, error) { return build(s) }
+
+// UpdateStatement is an UPDATE statement.
+type UpdateStatement struct {
+	with     with
+	typeName string
+	filters  []Expr
+	set      []assignment
+}
+
+// Update returns a statement that updates objects of type typeName.
+func Update(typeName string) *UpdateStatement {
+	return &UpdateStatement{typeName: typeName}
+}
+
+// With adds the alias name := expr to the statement's WITH block.
+func (s *UpdateStatement) With(name string, expr Expr) *UpdateStatement {
+	s.with = append(s.with, alias{name: name, expr: expr})
+	return s
+}
+
+// Filter adds a filter. Multiple filters are combined with AND.
+func (s *UpdateStatement) Filter(expr Expr) *UpdateStatement {
+	s.filters = append(s.filters, expr)
+	return s
+}
+
+// Set assigns val to the property or link name. val is bound to a parameter
+// unless it is an [Expr].
+func (s *UpdateStatement) Set(name string, val any) *UpdateStatement {
+	s.set = append(s.set, assignment{name: name, expr: value(val)})
+	return s
+}
+
+func (s *UpdateStatement) render(b *builder) {
+	subquery(b, func(b *builder) {
+		if len(s.set) == 0 {
+			b.fail(fmt.Errorf("update statements must set at least one value"))
+			return
+		}
+
+		s.with.render(b)
+		b.write("UPDATE ")
+		writeQualified(b, s.typeName)
+		if len(s.filters) > 0 {
+			b.write(" FILTER ")
+			And(s.filters...).render(b)
+		}
+		b.write(" SET ")
+		renderAssignments(b, s.set)
+	})
+}
+
+// Build returns the statement text and its arguments.
+func (s *UpdateStatement) Build() (string, []any, error) { return build(s) }
+
+// DeleteStatement is a DELETE statement.
+type DeleteStatement struct {
+	with     with
+	typeName string
+	clauses
+}
+
+// Delete returns a statement that deletes objects of type typeName.
+func Delete(typeName string) *DeleteStatement {
+	return &DeleteStatement{typeName: typeName}
+}
+
+// With adds the alias name := expr to the statement's WITH block.
+func (s *DeleteStatement) With(name string, expr Expr) *DeleteStatement {
+	s.with = append(s.with, alias{name: name, expr: expr})
+	return s
+}
+
+// Filter adds a filter. Multiple filters are combined with AND.
+func (s *DeleteStatement) Filter(expr Expr) *DeleteStatement {
+	s.filters = append(s.filters, expr)
+	return s
+}
+
+// OrderBy adds an ordering.
+func (s *DeleteStatement) OrderBy(expr Expr, dir Direction) *DeleteStatement {
+	s.order = append(s.order, ordering{expr: expr, dir: dir})
+	return s
+}
+
+// Offset skips the first n objects. n is bound to a parameter.
+func (s *DeleteStatement) Offset(n int64) *DeleteStatement {
+	s.offset = &n
+	return s
+}
+
+// Limit limits the number of deleted objects to n. n is bound to a
+// parameter.
+func (s *DeleteStatement) Limit(n int64) *DeleteStatement {
+	s.limit = &n
+	return s
+}
+
+func (s *DeleteStatement) render(b *builder) {
+	subquery(b, func(b *builder) {
+		s.with.render(b)
+		b.write("DELETE ")
+		writeQualified(b, s.typeName)
+		s.clauses.render(b)
+	})
+}
+
+// Build returns the statement text and its arguments.
+func (s *DeleteStatement) Build() (string, []any, error) { return build(s) }
+
+// ForStatement is a FOR statement.
+type ForStatement struct {
+	with     with
+	variable string
+	iterable Expr
+	body     Expr
+}
+
+// For returns the statement FOR variable IN iterable UNION (body).
+func For(variable string, iterable Expr, body Expr) *ForStatement {
+	return &ForStatement{variable: variable, iterable: iterable, body: body}
+}
+
+// With adds the alias name := expr to the statement's WITH block.
+func (s *ForStatement) With(name string, expr Expr) *ForStatement {
+	s.with = append(s.with, alias{name: name, expr: expr})
+	return s
+}
+
+func (s *ForStatement) render(b *builder) {
+	subquery(b, func(b *builder) {
+		s.with.render(b)
+		b.write("FOR ")
+		writeIdent(b, s.variable)
+		b.write(" IN ")
+		s.iterable.render(b)
+		b.write(" UNION (")
+
+		// The body is already parenthesized.
+		b.bare = true
+		s.body.render(b)
+		b.bare = false
+		b.write(")")
+	})
+}
+
+// Build returns the statement text and its arguments.
+func (s *ForStatement) Build() (string, []any, error) { return build(s) }
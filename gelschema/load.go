@@ -0,0 +1,349 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelschema
+
+import (
+	"context"
+
+	"github.com/geldata/gel-go/geltypes"
+)
+
+const constraintShape = `{
+		name,
+		expr,
+		subjectexpr,
+		params: {
+			num,
+			value := @value,
+		} FILTER .name != '__subject__' ORDER BY .num,
+	}`
+
+const pointerFields = `
+		name,
+		target_name := .target.name,
+		cardinality,
+		required,
+		readonly,
+		expr,
+		default,
+		constraints: ` + constraintShape
+
+const objectTypesQuery = `
+	WITH MODULE schema
+	SELECT ObjectType {
+		name,
+		abstract,
+		bases: { name } ORDER BY @index,
+		properties: {` + pointerFields + `,
+		} ORDER BY .name,
+		links: {` + pointerFields + `,
+			on_target_delete,
+			properties: {` + pointerFields + `,
+			} FILTER .name NOT IN {'source', 'target'} ORDER BY .name,
+		} FILTER .name != '__type__' ORDER BY .name,
+		constraints: ` + constraintShape + `,
+		indexes: { expr },
+	}
+	FILTER NOT .builtin
+	ORDER BY .name`
+
+const scalarTypesQuery = `
+	WITH MODULE schema
+	SELECT ScalarType {
+		name,
+		abstract,
+		enum_values,
+		bases: { name } ORDER BY @index,
+		constraints: ` + constraintShape + `,
+	}
+	FILTER NOT .builtin
+	ORDER BY .name`
+
+const functionsQuery = `
+	WITH MODULE schema
+	SELECT Function {
+		name,
+		params: {
+			name,
+			kind,
+			num,
+			typemod,
+			default,
+			type_name := .type.name,
+		} ORDER BY .num,
+		return_type_name := .return_type.name,
+		return_typemod,
+		volatility,
+	}
+	FILTER NOT .builtin
+	ORDER BY .name`
+
+type named struct {
+	ID   geltypes.UUID `gel:"id"`
+	Name string        `gel:"name"`
+}
+
+type constraintRow struct {
+	ID          geltypes.UUID        `gel:"id"`
+	Name        string               `gel:"name"`
+	Expr        geltypes.OptionalStr `gel:"expr"`
+	SubjectExpr geltypes.OptionalStr `gel:"subjectexpr"`
+	Params      []struct {
+		ID    geltypes.UUID          `gel:"id"`
+		Num   geltypes.OptionalInt64 `gel:"num"`
+		Value geltypes.OptionalStr   `gel:"value"`
+	} `gel:"params"`
+}
+
+type pointerRow struct {
+	ID          geltypes.UUID         `gel:"id"`
+	Name        string                `gel:"name"`
+	Target      geltypes.OptionalStr  `gel:"target_name"`
+	Cardinality geltypes.OptionalStr  `gel:"cardinality"`
+	Required    geltypes.OptionalBool `gel:"required"`
+	ReadOnly    geltypes.OptionalBool `gel:"readonly"`
+	Expr        geltypes.OptionalStr  `gel:"expr"`
+	Default     geltypes.OptionalStr  `gel:"default"`
+	Constraints []constraintRow       `gel:"constraints"`
+}
+
+type linkRow struct {
+	pointerRow     `gel:"$inline"`
+	OnTargetDelete geltypes.OptionalStr `gel:"on_target_delete"`
+	Properties     []pointerRow         `gel:"properties"`
+}
+
+type objectTypeRow struct {
+	ID          geltypes.UUID         `gel:"id"`
+	Name        string                `gel:"name"`
+	Abstract    geltypes.OptionalBool `gel:"abstract"`
+	Bases       []named               `gel:"bases"`
+	Properties  []pointerRow          `gel:"properties"`
+	Links       []linkRow             `gel:"links"`
+	Constraints []constraintRow       `gel:"constraints"`
+	Indexes     []struct {
+		ID   geltypes.UUID        `gel:"id"`
+		Expr geltypes.OptionalStr `gel:"expr"`
+	} `gel:"indexes"`
+}
+
+type scalarTypeRow struct {
+	ID          geltypes.UUID         `gel:"id"`
+	Name        string                `gel:"name"`
+	Abstract    geltypes.OptionalBool `gel:"abstract"`
+	EnumValues  []string              `gel:"enum_values"`
+	Bases       []named               `gel:"bases"`
+	Constraints []constraintRow       `gel:"constraints"`
+}
+
+type functionRow struct {
+	ID     geltypes.UUID `gel:"id"`
+	Name   string        `gel:"name"`
+	Params []struct {
+		ID           geltypes.UUID          `gel:"id"`
+		Name         string                 `gel:"name"`
+		Kind         geltypes.OptionalStr   `gel:"kind"`
+		Num          geltypes.OptionalInt64 `gel:"num"`
+		TypeModifier geltypes.OptionalStr   `gel:"typemod"`
+		Default      geltypes.OptionalStr   `gel:"default"`
+		Type         geltypes.OptionalStr   `gel:"type_name"`
+	} `gel:"params"`
+	ReturnType         geltypes.OptionalStr `gel:"return_type_name"`
+	ReturnTypeModifier geltypes.OptionalStr `gel:"return_typemod"`
+	Volatility         geltypes.OptionalStr `gel:"volatility"`
+}
+
+// Load introspects the schema using q. Run it in a transaction to get a
+// consistent view of the schema.
+func Load(ctx context.Context, q geltypes.Executor) (*Schema, error) {
+	var objectTypes []objectTypeRow
+	if err := q.Query(ctx, objectTypesQuery, &objectTypes); err != nil {
+		return nil, err
+	}
+
+	var scalarTypes []scalarTypeRow
+	if err := q.Query(ctx, scalarTypesQuery, &scalarTypes); err != nil {
+		return nil, err
+	}
+
+	var functions []functionRow
+	if err := q.Query(ctx, functionsQuery, &functions); err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{
+		ObjectTypes: make([]ObjectType, len(objectTypes)),
+		ScalarTypes: make([]ScalarType, len(scalarTypes)),
+		Functions:   make([]Function, len(functions)),
+	}
+
+	for i, row := range objectTypes {
+		schema.ObjectTypes[i] = newObjectType(row)
+	}
+
+	for i, row := range scalarTypes {
+		schema.ScalarTypes[i] = ScalarType{
+			ID:          row.ID,
+			Name:        row.Name,
+			Abstract:    boolean(row.Abstract),
+			Bases:       names(row.Bases),
+			EnumValues:  row.EnumValues,
+			Constraints: newConstraints(row.Constraints),
+		}
+	}
+
+	for i, row := range functions {
+		schema.Functions[i] = newFunction(row)
+	}
+
+	return schema, nil
+}
+
+func newObjectType(row objectTypeRow) ObjectType {
+	t := ObjectType{
+		ID:          row.ID,
+		Name:        row.Name,
+		Abstract:    boolean(row.Abstract),
+		Bases:       names(row.Bases),
+		Properties:  newProperties(row.Properties),
+		Links:       make([]Link, len(row.Links)),
+		Constraints: newConstraints(row.Constraints),
+		Indexes:     make([]Index, len(row.Indexes)),
+	}
+
+	for i, link := range row.Links {
+		p := newProperty(link.pointerRow)
+		t.Links[i] = Link{
+			Name:           p.Name,
+			Target:         p.Target,
+			Cardinality:    p.Cardinality,
+			ReadOnly:       p.ReadOnly,
+			Computed:       p.Computed,
+			Expr:           p.Expr,
+			Default:        p.Default,
+			OnTargetDelete: str(link.OnTargetDelete),
+			Properties:     newProperties(link.Properties),
+			Constraints:    p.Constraints,
+		}
+	}
+
+	for i, index := range row.Indexes {
+		t.Indexes[i] = Index{Expr: str(index.Expr)}
+	}
+
+	return t
+}
+
+func newProperties(rows []pointerRow) []Property {
+	properties := make([]Property, len(rows))
+	for i, row := range rows {
+		properties[i] = newProperty(row)
+	}
+
+	return properties
+}
+
+func newProperty(row pointerRow) Property {
+	expr, computed := row.Expr.Get()
+	return Property{
+		Name:        row.Name,
+		Target:      str(row.Target),
+		Cardinality: cardinality(row.Cardinality, row.Required),
+		ReadOnly:    boolean(row.ReadOnly),
+		Computed:    computed,
+		Expr:        expr,
+		Default:     str(row.Default),
+		Constraints: newConstraints(row.Constraints),
+	}
+}
+
+func newConstraints(rows []constraintRow) []Constraint {
+	constraints := make([]Constraint, len(rows))
+	for i, row := range rows {
+		args := make([]string, len(row.Params))
+		for j, param := range row.Params {
+			args[j] = str(param.Value)
+		}
+
+		constraints[i] = Constraint{
+			Name:        row.Name,
+			Expr:        str(row.Expr),
+			SubjectExpr: str(row.SubjectExpr),
+			Args:        args,
+		}
+	}
+
+	return constraints
+}
+
+func newFunction(row functionRow) Function {
+	params := make([]Parameter, len(row.Params))
+	for i, param := range row.Params {
+		params[i] = Parameter{
+			Name:         param.Name,
+			Type:         str(param.Type),
+			Kind:         str(param.Kind),
+			TypeModifier: str(param.TypeModifier),
+			Default:      str(param.Default),
+		}
+	}
+
+	return Function{
+		ID:                 row.ID,
+		Name:               row.Name,
+		Params:             params,
+		ReturnType:         str(row.ReturnType),
+		ReturnTypeModifier: str(row.ReturnTypeModifier),
+		Volatility:         str(row.Volatility),
+	}
+}
+
+func cardinality(
+	card geltypes.OptionalStr,
+	required geltypes.OptionalBool,
+) Cardinality {
+	multi := str(card) == "Many"
+	switch {
+	case multi && boolean(required):
+		return AtLeastOne
+	case multi:
+		return Many
+	case boolean(required):
+		return One
+	default:
+		return AtMostOne
+	}
+}
+
+func names(rows []named) []string {
+	result := make([]string, len(rows))
+	for i, row := range rows {
+		result[i] = row.Name
+	}
+
+	return result
+}
+
+func str(o geltypes.OptionalStr) string {
+	val, _ := o.Get()
+	return val
+}
+
+func boolean(o geltypes.OptionalBool) bool {
+	val, _ := o.Get()
+	return val
+}
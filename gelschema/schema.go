@@ -0,0 +1,244 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelschema describes the user defined schema of a Gel branch.
+//
+// Use [github.com/geldata/gel-go.Client.Schema] to get the schema of the
+// client's branch. Schemas are cached by the client until it observes a DDL
+// statement, so they should be treated as read only.
+package gelschema
+
+import (
+	"github.com/geldata/gel-go/geltypes"
+)
+
+// Cardinality is the number of values a pointer can have.
+type Cardinality string
+
+const (
+	// One is a required single pointer.
+	One Cardinality = "One"
+
+	// AtMostOne is an optional single pointer.
+	AtMostOne Cardinality = "AtMostOne"
+
+	// AtLeastOne is a required multi pointer.
+	AtLeastOne Cardinality = "AtLeastOne"
+
+	// Many is an optional multi pointer.
+	Many Cardinality = "Many"
+)
+
+// Schema is the user defined part of a branch's schema. Types and functions
+// from the standard library and extensions are not included.
+type Schema struct {
+	ObjectTypes []ObjectType
+	ScalarTypes []ScalarType
+	Functions   []Function
+}
+
+// ObjectType returns the object type with the fully qualified name.
+func (s *Schema) ObjectType(name string) (ObjectType, bool) {
+	for _, t := range s.ObjectTypes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return ObjectType{}, false
+}
+
+// ScalarType returns the scalar type with the fully qualified name.
+func (s *Schema) ScalarType(name string) (ScalarType, bool) {
+	for _, t := range s.ScalarTypes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return ScalarType{}, false
+}
+
+// FunctionOverloads returns all functions with the fully qualified name.
+func (s *Schema) FunctionOverloads(name string) []Function {
+	var functions []Function
+	for _, f := range s.Functions {
+		if f.Name == name {
+			functions = append(functions, f)
+		}
+	}
+
+	return functions
+}
+
+// ObjectType is an object type.
+type ObjectType struct {
+	ID       geltypes.UUID
+	Name     string
+	Abstract bool
+
+	// Bases are the names of the types this type directly extends.
+	Bases []string
+
+	Properties  []Property
+	Links       []Link
+	Constraints []Constraint
+	Indexes     []Index
+}
+
+// Property returns the property with the given name.
+func (t *ObjectType) Property(name string) (Property, bool) {
+	for _, p := range t.Properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return Property{}, false
+}
+
+// Link returns the link with the given name.
+func (t *ObjectType) Link(name string) (Link, bool) {
+	for _, l := range t.Links {
+		if l.Name == name {
+			return l, true
+		}
+	}
+
+	return Link{}, false
+}
+
+// Property is a property of an object type or a link.
+type Property struct {
+	Name string
+
+	// Target is the name of the property's type.
+	Target string
+
+	Cardinality Cardinality
+	ReadOnly    bool
+
+	// Computed is true if the property is computed from Expr.
+	Computed bool
+	Expr     string
+
+	// Default is the default value expression or "" if there is none.
+	Default string
+
+	Constraints []Constraint
+}
+
+// Link is a link of an object type.
+type Link struct {
+	Name string
+
+	// Target is the name of the linked object type.
+	Target string
+
+	Cardinality Cardinality
+	ReadOnly    bool
+
+	// Computed is true if the link is computed from Expr.
+	Computed bool
+	Expr     string
+
+	// Default is the default value expression or "" if there is none.
+	Default string
+
+	// OnTargetDelete is the action taken when a target is deleted, for
+	// example "Restrict" or "DeleteSource".
+	OnTargetDelete string
+
+	// Properties are the link's properties.
+	Properties []Property
+
+	Constraints []Constraint
+}
+
+// Constraint is a constraint on a type or pointer.
+type Constraint struct {
+	// Name is the fully qualified constraint name, for example
+	// std::exclusive.
+	Name string
+
+	// Expr is the constraint's expression.
+	Expr string
+
+	// SubjectExpr is the expression the constraint is applied to or "" if
+	// it is applied to the subject itself.
+	SubjectExpr string
+
+	// Args are the constraint's arguments, for example the maximum length
+	// of std::max_len_value.
+	Args []string
+}
+
+// Index is an index on an object type.
+type Index struct {
+	Expr string
+}
+
+// ScalarType is a scalar type.
+type ScalarType struct {
+	ID       geltypes.UUID
+	Name     string
+	Abstract bool
+
+	// Bases are the names of the types this type directly extends.
+	Bases []string
+
+	// EnumValues are the values of an enum type in order.
+	EnumValues []string
+
+	Constraints []Constraint
+}
+
+// IsEnum returns true if the scalar type is an enum.
+func (t *ScalarType) IsEnum() bool { return len(t.EnumValues) > 0 }
+
+// Function is a user defined function.
+type Function struct {
+	ID   geltypes.UUID
+	Name string
+
+	Params []Parameter
+
+	// ReturnType is the name of the returned type.
+	ReturnType string
+
+	// ReturnTypeModifier is SingletonType, OptionalType or SetOfType.
+	ReturnTypeModifier string
+
+	// Volatility is Immutable, Stable, Volatile or Modifying.
+	Volatility string
+}
+
+// Parameter is a function parameter.
+type Parameter struct {
+	Name string
+
+	// Type is the name of the parameter's type.
+	Type string
+
+	// Kind is PositionalParam, NamedOnlyParam or VariadicParam.
+	Kind string
+
+	// TypeModifier is SingletonType, OptionalType or SetOfType.
+	TypeModifier string
+
+	// Default is the default value expression or "" if there is none.
+	Default string
+}
@@ -0,0 +1,184 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelsql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bindArgs returns the query with @name references to named arguments
+// replaced by positional parameters and the argument values in parameter
+// order. Positional arguments are placed by their ordinal and named arguments
+// are numbered in the order they first appear in the query. Named and
+// positional arguments can't be mixed.
+func bindArgs(
+	query string,
+	args []driver.NamedValue,
+) (string, []any, error) {
+	named := make(map[string]any)
+	positional := 0
+
+	for _, arg := range args {
+		if arg.Name == "" {
+			positional++
+		} else {
+			named[arg.Name] = arg.Value
+		}
+	}
+
+	if positional > 0 && len(named) > 0 {
+		return "", nil, errors.New(
+			"named and positional arguments can't be mixed")
+	}
+
+	if len(named) == 0 {
+		values := make([]any, len(args))
+		for _, arg := range args {
+			if arg.Ordinal < 1 || arg.Ordinal > len(args) {
+				return "", nil, fmt.Errorf(
+					"invalid argument ordinal %v", arg.Ordinal)
+			}
+
+			values[arg.Ordinal-1] = arg.Value
+		}
+
+		return query, values, nil
+	}
+
+	values := make([]any, 0, len(args))
+	positions := make(map[string]int, len(named))
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); {
+		if n := skipLiteral(query[i:]); n > 0 {
+			b.WriteString(query[i : i+n])
+			i += n
+			continue
+		}
+
+		if query[i] != '@' {
+			b.WriteByte(query[i])
+			i++
+			continue
+		}
+
+		name := identifier(query[i+1:])
+		val, ok := named[name]
+		if !ok {
+			b.WriteByte(query[i])
+			i++
+			continue
+		}
+
+		pos, ok := positions[name]
+		if !ok {
+			values = append(values, val)
+			pos = len(values)
+			positions[name] = pos
+		}
+
+		b.WriteString("$" + strconv.Itoa(pos))
+		i += 1 + len(name)
+	}
+
+	for name := range named {
+		if _, ok := positions[name]; !ok {
+			return "", nil, fmt.Errorf(
+				"named argument %q is not used in the query", name)
+		}
+	}
+
+	return b.String(), values, nil
+}
+
+// skipLiteral returns the length of the string literal, quoted identifier or
+// comment at the start of s or 0 if s doesn't start with one.
+func skipLiteral(s string) int {
+	switch {
+	case strings.HasPrefix(s, "--"):
+		if n := strings.IndexByte(s, '\n'); n >= 0 {
+			return n + 1
+		}
+		return len(s)
+	case strings.HasPrefix(s, "/*"):
+		if n := strings.Index(s[2:], "*/"); n >= 0 {
+			return n + 4
+		}
+		return len(s)
+	case s[0] == '\'' || s[0] == '"':
+		return skipQuoted(s, s[0])
+	case s[0] == '$':
+		return skipDollarQuoted(s)
+	default:
+		return 0
+	}
+}
+
+// skipQuoted returns the length of a quoted string where the quote character
+// is escaped by doubling it.
+func skipQuoted(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+
+		return i + 1
+	}
+
+	return len(s)
+}
+
+// skipDollarQuoted returns the length of a dollar quoted string like
+// $tag$text$tag$ or 0 if s starts with a positional parameter.
+func skipDollarQuoted(s string) int {
+	tag := identifier(s[1:])
+	if len(s) < len(tag)+2 || s[len(tag)+1] != '$' {
+		return 0
+	}
+
+	delimiter := s[:len(tag)+2]
+	if n := strings.Index(s[len(delimiter):], delimiter); n >= 0 {
+		return len(delimiter)*2 + n
+	}
+
+	return len(s)
+}
+
+// identifier returns the identifier at the start of s.
+func identifier(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return s[:i]
+		}
+	}
+
+	return s
+}
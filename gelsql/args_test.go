@@ -0,0 +1,123 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelsql
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		args     []driver.NamedValue
+		expected string
+		values   []any
+	}{
+		{
+			name:  "positional",
+			query: "SELECT $1, $2",
+			args: []driver.NamedValue{
+				{Ordinal: 1, Value: 1},
+				{Ordinal: 2, Value: "a"},
+			},
+			expected: "SELECT $1, $2",
+			values:   []any{1, "a"},
+		},
+		{
+			name:  "ordinal",
+			query: "SELECT $1, $2",
+			args: []driver.NamedValue{
+				{Ordinal: 2, Value: "a"},
+				{Ordinal: 1, Value: 1},
+			},
+			expected: "SELECT $1, $2",
+			values:   []any{1, "a"},
+		},
+		{
+			name:  "named",
+			query: "SELECT @b, @a, @b",
+			args: []driver.NamedValue{
+				{Name: "a", Value: 1},
+				{Name: "b", Value: 2},
+			},
+			expected: "SELECT $1, $2, $1",
+			values:   []any{2, 1},
+		},
+		{
+			name: "literals",
+			query: `SELECT '@a', "@a", $$@a$$, $t$ @a $t$, ` +
+				"'it''s @a' -- @a\n/* @a */ @a",
+			args: []driver.NamedValue{{Name: "a", Value: 1}},
+			expected: `SELECT '@a', "@a", $$@a$$, $t$ @a $t$, ` +
+				"'it''s @a' -- @a\n/* @a */ $1",
+			values: []any{1},
+		},
+		{
+			name:     "unknown name",
+			query:    "SELECT @ab, @a",
+			args:     []driver.NamedValue{{Name: "a", Value: 1}},
+			expected: "SELECT @ab, $1",
+			values:   []any{1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, values, err := bindArgs(test.query, test.args)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, query)
+			assert.Equal(t, test.values, values)
+		})
+	}
+}
+
+func TestBindArgsUnusedName(t *testing.T) {
+	_, _, err := bindArgs(
+		"SELECT '@a'",
+		[]driver.NamedValue{{Name: "a", Value: 1}},
+	)
+	assert.EqualError(t, err, `named argument "a" is not used in the query`)
+}
+
+func TestBindArgsMixed(t *testing.T) {
+	_, _, err := bindArgs(
+		"SELECT $1, @name",
+		[]driver.NamedValue{
+			{Ordinal: 1, Value: 1},
+			{Name: "name", Ordinal: 2, Value: "x"},
+		},
+	)
+	assert.EqualError(t, err, "named and positional arguments can't be mixed")
+}
+
+func TestResultRowsAffected(t *testing.T) {
+	n, err := result("INSERT 0 3").RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	n, err = result("UPDATE 2").RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	_, err = result("CREATE TABLE").RowsAffected()
+	assert.Error(t, err)
+}
@@ -0,0 +1,317 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/gelerr"
+	gelint "github.com/geldata/gel-go/internal/client"
+	gelerrint "github.com/geldata/gel-go/internal/gelerr"
+)
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+
+	rowType = reflect.TypeOf([]any{})
+
+	// noRetries makes queries fail on their first error. Rows are streamed
+	// to the caller so a retried query could return rows twice.
+	noRetries = gelcfg.NewRetryOptions().
+			WithDefault(gelcfg.NewRetryRule().WithAttempts(1))
+)
+
+type conn struct {
+	pool *gelint.Pool
+	raw  *gelint.Conn
+	cfg  gelint.QueryConfig
+	tx   *tx
+
+	// bad is the connection error that made the connection unusable.
+	bad error
+}
+
+func newConn(pool *gelint.Pool, raw *gelint.Conn) *conn {
+	cfg := pool.QueryConfig
+	cfg.RetryOptions = noRetries
+
+	return &conn{pool: pool, raw: raw, cfg: cfg}
+}
+
+// stream runs a SQL statement on the connection or on the active
+// transaction.
+func (c *conn) stream(
+	ctx context.Context,
+	method, cmd string,
+	typ reflect.Type,
+	args []driver.NamedValue,
+	handler gelint.RowHandler,
+) (string, error) {
+	if c.bad != nil {
+		return "", driver.ErrBadConn
+	}
+
+	cmd, values, err := bindArgs(cmd, args)
+	if err != nil {
+		return "", gelerrint.NewInvalidArgumentError(err.Error(), nil)
+	}
+
+	var status string
+	if c.tx != nil {
+		status, err = gelint.StreamQuery(
+			ctx,
+			c.tx.tx,
+			method,
+			cmd,
+			typ,
+			values,
+			c.pool.State,
+			&c.tx.cfg,
+			true,
+			handler,
+		)
+	} else {
+		status, err = gelint.StreamQuery(
+			ctx,
+			c.raw,
+			method,
+			cmd,
+			typ,
+			values,
+			c.pool.State,
+			&c.cfg,
+			false,
+			handler,
+		)
+	}
+
+	c.track(err)
+	return status, err
+}
+
+// track marks the connection as bad if err is a connection error.
+func (c *conn) track(err error) {
+	var gelErr gelerr.Error
+	if errors.As(err, &gelErr) &&
+		gelErr.Category(gelerr.ClientConnectionError) {
+		c.bad = err
+	}
+}
+
+// Prepare returns a prepared statement.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext returns a prepared statement. The statement is parsed by
+// the server the first time it is executed and the parse results are cached
+// by the connection's pool.
+func (c *conn) PrepareContext(
+	_ context.Context,
+	query string,
+) (driver.Stmt, error) {
+	if c.bad != nil {
+		return nil, driver.ErrBadConn
+	}
+
+	return &stmt{conn: c, query: query}, nil
+}
+
+// Close releases the connection back to the pool.
+func (c *conn) Close() error {
+	if c.tx != nil {
+		_ = c.tx.Rollback()
+	}
+
+	return c.pool.Release(c.raw, c.bad)
+}
+
+// Begin starts a transaction.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// ExecContext executes a statement that doesn't return rows.
+func (c *conn) ExecContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	status, err := c.stream(ctx, "ExecuteSQL", query, nil, args, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result(status), nil
+}
+
+// QueryContext executes a statement that returns rows. Rows are streamed
+// from the server as they are read with [driver.Rows.Next].
+func (c *conn) QueryContext(
+	ctx context.Context,
+	query string,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	if c.bad != nil {
+		return nil, driver.ErrBadConn
+	}
+
+	r := newRows(ctx)
+	go func() {
+		_, err := c.stream(ctx, "QuerySQL", query, rowType, args, r)
+		r.finish(err)
+	}()
+
+	if err := r.wait(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// CheckNamedValue accepts every argument value. Values are validated when
+// they are encoded using the parameter types reported by the server.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch v := nv.Value.(type) {
+	case driver.Valuer:
+		val, err := v.Value()
+		if err != nil {
+			return err
+		}
+		nv.Value = val
+	case int:
+		nv.Value = int64(v)
+	}
+
+	return nil
+}
+
+// Ping checks that the connection is usable.
+func (c *conn) Ping(ctx context.Context) error {
+	_, err := c.ExecContext(ctx, "SELECT 1", nil)
+	if c.bad != nil {
+		return driver.ErrBadConn
+	}
+
+	return err
+}
+
+// ResetSession is called before a connection is reused.
+func (c *conn) ResetSession(_ context.Context) error {
+	if c.bad != nil {
+		return driver.ErrBadConn
+	}
+
+	return nil
+}
+
+// IsValid reports whether the connection can be returned to the
+// [database/sql] pool.
+func (c *conn) IsValid() bool { return c.bad == nil }
+
+// result is the command status sent by the server, for example "INSERT 0 3".
+type result string
+
+// LastInsertId is not supported.
+func (r result) LastInsertId() (int64, error) {
+	return 0, gelerrint.NewUnsupportedFeatureError(
+		"LastInsertId is not supported, use RETURNING instead", nil)
+}
+
+// RowsAffected returns the row count from the command status.
+func (r result) RowsAffected() (int64, error) {
+	fields := strings.Fields(string(r))
+	if len(fields) < 2 {
+		return 0, gelerrint.NewInterfaceError(
+			"the number of affected rows is not available", nil)
+	}
+
+	n, err := strconv.ParseInt(fields[len(fields)-1], 10, 64)
+	if err != nil {
+		return 0, gelerrint.NewInterfaceError(
+			"the number of affected rows is not available", err)
+	}
+
+	return n, nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+var (
+	_ driver.Stmt             = (*stmt)(nil)
+	_ driver.StmtExecContext  = (*stmt)(nil)
+	_ driver.StmtQueryContext = (*stmt)(nil)
+)
+
+// Close closes the statement.
+func (s *stmt) Close() error { return nil }
+
+// NumInput returns -1 because the number of parameters is not known until
+// the statement is parsed by the server.
+func (s *stmt) NumInput() int { return -1 }
+
+// Exec executes the statement.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query executes the statement.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// ExecContext executes the statement.
+func (s *stmt) ExecContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+// QueryContext executes the statement.
+func (s *stmt) QueryContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return named
+}
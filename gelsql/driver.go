@@ -0,0 +1,124 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelsql is a [database/sql] driver for Gel. Statements are sent to
+// the server as SQL using the same binary protocol connection as
+// [github.com/geldata/gel-go.Client.QuerySQL], so it requires Gel 6.0 or
+// newer.
+//
+// Importing the package registers a driver named "gel". The data source name
+// is either an instance name or a [DSN]. An empty data source name resolves
+// the connection from the environment or the current project like
+// [github.com/geldata/gel-go.CreateClient] does.
+//
+//	db, err := sql.Open("gel", "")
+//
+// Use [NewConnector] and [database/sql.OpenDB] to pass [gelcfg.Options].
+//
+//	connector, err := gelsql.NewConnector("", gelcfg.Options{})
+//	db := sql.OpenDB(connector)
+//
+// Each [database/sql] connection holds a connection from the connector's
+// pool, so [database/sql.DB.SetMaxOpenConns] should not be larger than
+// [gelcfg.Options.Concurrency].
+//
+// Arguments are positional ($1, $2, ...). Named arguments passed with
+// [database/sql.Named] are referenced as @name in the statement text and are
+// converted to positional arguments before the statement is sent. Named and
+// positional arguments can't be used in the same statement. Arguments
+// must have the go type that corresponds to the parameter's Gel type, for
+// example int32 for an int4 parameter. Values of type int are sent as int64.
+//
+// Queries and transactions are not retried.
+//
+// [DSN]: https://docs.geldata.com/reference/reference/dsn#ref-dsn
+package gelsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/geldata/gel-go/gelcfg"
+	gelint "github.com/geldata/gel-go/internal/client"
+)
+
+// DriverName is the name the driver is registered with.
+const DriverName = "gel"
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+var (
+	_ driver.Driver        = (*Driver)(nil)
+	_ driver.DriverContext = (*Driver)(nil)
+	_ driver.Connector     = (*Connector)(nil)
+)
+
+// Driver is the [database/sql/driver.Driver] for Gel.
+type Driver struct{}
+
+// Open returns a new connection. dsn is either an instance name or a DSN.
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	connector, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return connector.Connect(context.Background())
+}
+
+// OpenConnector returns a connector for dsn. dsn is either an instance name
+// or a DSN.
+func (d *Driver) OpenConnector(dsn string) (driver.Connector, error) {
+	return NewConnector(dsn, gelcfg.Options{})
+}
+
+// NewConnector returns a connector that can be passed to
+// [database/sql.OpenDB]. dsn and opts are resolved the same way as in
+// [github.com/geldata/gel-go.CreateClientDSN].
+func NewConnector(dsn string, opts gelcfg.Options) (*Connector, error) { // nolint:gocritic,lll
+	pool, err := gelint.NewPool(dsn, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Connector{pool: pool}, nil
+}
+
+// Connector is a [database/sql/driver.Connector] that holds a pool of
+// connections to a Gel instance.
+type Connector struct {
+	pool *gelint.Pool
+}
+
+// Connect acquires a connection from the connector's pool.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	raw, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConn(c.pool, raw), nil
+}
+
+// Driver returns the underlying driver.
+func (c *Connector) Driver() driver.Driver { return &Driver{} }
+
+// Close closes the connector's pool. It is called by
+// [database/sql.DB.Close].
+func (c *Connector) Close() error { return c.pool.Close() }
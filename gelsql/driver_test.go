@@ -0,0 +1,327 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/geldata/gel-go/gelerr"
+	"github.com/geldata/gel-go/internal/codecs"
+	fs "github.com/geldata/gel-go/internal/fakeserver"
+)
+
+const seriesQuery = "SELECT n FROM generate_series(1, 3) AS n"
+
+var int64Desc = fs.ScalarDescriptor(codecs.Int64ID, "std::int64")
+
+func start(t *testing.T) (*fs.Server, *sql.DB) {
+	server, err := fs.Start(fs.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, server.Close()) })
+
+	connector, err := NewConnector("", server.ClientOptions())
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { assert.NoError(t, db.Close()) })
+
+	return server, db
+}
+
+// handleSeries responds to seriesQuery with the rows 1, 2 and 3.
+func handleSeries(server *fs.Server, responses ...*fs.Response) {
+	b := fs.NewBuilder()
+	n := b.Scalar(codecs.Int64ID, "std::int64")
+	b.SQLRecord(fs.Element{Name: "n", Type: n})
+
+	if len(responses) == 0 {
+		responses = []*fs.Response{{}}
+	}
+
+	for _, resp := range responses {
+		resp.Output = b.Build()
+		resp.Data = [][]byte{
+			fs.Object(fs.Int64(1)),
+			fs.Object(fs.Int64(2)),
+			fs.Object(fs.Int64(3)),
+		}
+	}
+
+	server.Handle(seriesQuery, responses...)
+}
+
+func scanAll(t *testing.T, rows *sql.Rows) []int64 {
+	var result []int64
+	for rows.Next() {
+		var n int64
+		require.NoError(t, rows.Scan(&n))
+		result = append(result, n)
+	}
+
+	return result
+}
+
+func TestQueryRows(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	handleSeries(server)
+
+	rows, err := db.QueryContext(ctx, seriesQuery)
+	require.NoError(t, err)
+
+	columns, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	require.Len(t, columns, 1)
+	assert.Equal(t, "n", columns[0].Name())
+	assert.Equal(t, "std::int64", columns[0].DatabaseTypeName())
+	assert.Equal(t, reflect.TypeOf(int64(0)), columns[0].ScanType())
+
+	assert.Equal(t, []int64{1, 2, 3}, scanAll(t, rows))
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+}
+
+func TestQueryRowsEarlyClose(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	handleSeries(server)
+	db.SetMaxOpenConns(1)
+
+	rows, err := db.QueryContext(ctx, seriesQuery)
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Close())
+
+	// The connection is usable after the remaining rows were discarded.
+	rows, err = db.QueryContext(ctx, seriesQuery)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, scanAll(t, rows))
+	require.NoError(t, rows.Close())
+	assert.Equal(t, 1, server.Accepted())
+}
+
+func TestQueryRowsCancel(t *testing.T) {
+	server, db := start(t)
+	handleSeries(server,
+		// The rows after the first one arrive after ctx is canceled.
+		&fs.Response{Fault: fs.Fault{
+			ChunkSize:  64,
+			ChunkDelay: 50 * time.Millisecond,
+		}},
+		&fs.Response{},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, seriesQuery)
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+
+	cancel()
+	assert.False(t, rows.Next())
+	assert.ErrorIs(t, rows.Err(), context.Canceled)
+	require.NoError(t, rows.Close())
+
+	rows, err = db.QueryContext(context.Background(), seriesQuery)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, scanAll(t, rows))
+	require.NoError(t, rows.Close())
+}
+
+func TestQueryError(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	server.Handle("SELECT missing", &fs.Response{Error: &fs.Error{
+		Code:    0x04_03_00_00, // InvalidReferenceError
+		Message: `column "missing" does not exist`,
+	}})
+
+	_, err := db.QueryContext(ctx, "SELECT missing")
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.InvalidReferenceError))
+}
+
+func TestExecRowsAffected(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	server.Handle(`UPDATE "User" SET n = $1`, &fs.Response{
+		Input:  fs.ArgsDescriptor(int64Desc),
+		Status: "UPDATE 2",
+	})
+	server.Handle(`INSERT INTO "User" DEFAULT VALUES`, &fs.Response{
+		Status: "INSERT 0 1",
+	})
+	server.Handle("SET search_path TO public", &fs.Response{
+		Status: "SET",
+	})
+
+	res, err := db.ExecContext(ctx, `UPDATE "User" SET n = $1`, 5)
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	queries := server.Queries()
+	assert.Equal(t, fs.Object(fs.Int64(5)), queries[len(queries)-1].Args)
+
+	res, err = db.ExecContext(ctx, `INSERT INTO "User" DEFAULT VALUES`)
+	require.NoError(t, err)
+	n, err = res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = res.LastInsertId()
+	assert.Error(t, err)
+
+	res, err = db.ExecContext(ctx, "SET search_path TO public")
+	require.NoError(t, err)
+	_, err = res.RowsAffected()
+	assert.Error(t, err)
+}
+
+func TestTxCommit(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	server.Handle(`DELETE FROM "User"`, &fs.Response{Status: "DELETE 3"})
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM "User"`)
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	require.NoError(t, tx.Commit())
+	assert.ErrorIs(t, tx.Commit(), sql.ErrTxDone)
+
+	executed := server.Executed()
+	require.Len(t, executed, 3)
+	assert.Contains(t, executed[0], "START TRANSACTION")
+	assert.Equal(t, `DELETE FROM "User"`, executed[1])
+	assert.Equal(t, "COMMIT;", executed[2])
+
+	for _, q := range server.Queries() {
+		if q.Text == `DELETE FROM "User"` {
+			assert.True(t, q.InTx)
+		}
+	}
+}
+
+func TestTxRollback(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	server.Handle(`DELETE FROM "User"`, &fs.Response{Status: "DELETE 3"})
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, `DELETE FROM "User"`)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	executed := server.Executed()
+	require.Len(t, executed, 3)
+	assert.Equal(t, "ROLLBACK;", executed[2])
+
+	// The connection can start a new transaction.
+	tx, err = db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+		ReadOnly:  true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+}
+
+func TestTxError(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	server.Handle(`DELETE FROM "User"`, &fs.Response{Error: &fs.Error{
+		Code:    0x05_03_01_00, // TransactionConflictError
+		Message: "could not serialize access",
+	}})
+
+	_, err := db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+	})
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.UnsupportedFeatureError))
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+
+	// Queries are not retried in database/sql transactions.
+	_, err = tx.ExecContext(ctx, `DELETE FROM "User"`)
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.TransactionConflictError))
+
+	assert.Error(t, tx.Commit())
+	assert.Equal(t, 1, countExecuted(server, `DELETE FROM "User"`))
+}
+
+func countExecuted(server *fs.Server, query string) int {
+	n := 0
+	for _, q := range server.Executed() {
+		if q == query {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestBadConn(t *testing.T) {
+	ctx := context.Background()
+	server, db := start(t)
+	server.Handle(`DELETE FROM "User"`, &fs.Response{
+		Status: "DELETE 1",
+		Fault:  fs.Fault{Disconnect: true, DisconnectAfter: 3},
+	})
+	db.SetMaxOpenConns(1)
+
+	c, err := db.Conn(ctx)
+	require.NoError(t, err)
+
+	_, err = c.ExecContext(ctx, `DELETE FROM "User"`)
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.ClientConnectionError))
+
+	err = c.Raw(func(raw any) error {
+		assert.False(t, raw.(driver.Validator).IsValid())
+		_, err := raw.(driver.ExecerContext).ExecContext(ctx, "SELECT 1", nil)
+		assert.ErrorIs(t, err, driver.ErrBadConn)
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+
+	// The bad connection was discarded and a new one is opened.
+	require.NoError(t, db.PingContext(ctx))
+	assert.Equal(t, 2, server.Accepted())
+}
@@ -0,0 +1,179 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"sync"
+
+	gelint "github.com/geldata/gel-go/internal/client"
+	"github.com/geldata/gel-go/internal/codecs"
+)
+
+var (
+	_ driver.Rows                           = (*rows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*rows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+	_ gelint.RowHandler                     = (*rows)(nil)
+)
+
+// rows streams query results from the goroutine reading the connection to
+// the caller. The reading goroutine blocks until the caller asks for the next
+// row, so only one row is held in memory at a time.
+type rows struct {
+	ctx     context.Context
+	mu      sync.Mutex
+	columns []codecs.SQLColumn
+
+	values    chan []any
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// pending is a row that was received before rows was returned to the
+	// caller.
+	pending []any
+
+	// err is set before values is closed.
+	err error
+}
+
+func newRows(ctx context.Context) *rows {
+	return &rows{
+		ctx:    ctx,
+		values: make(chan []any),
+		closed: make(chan struct{}),
+	}
+}
+
+// Describe implements gelint.RowHandler.
+func (r *rows) Describe(decoder codecs.Decoder) {
+	if d, ok := decoder.(codecs.SQLColumnDecoder); ok {
+		r.mu.Lock()
+		r.columns = d.Columns()
+		r.mu.Unlock()
+	}
+}
+
+// Row implements gelint.RowHandler. Rows are discarded once the caller has
+// closed r.
+func (r *rows) Row(val reflect.Value) error {
+	select {
+	case r.values <- val.Interface().([]any):
+	case <-r.closed:
+	}
+
+	return nil
+}
+
+// finish is called when the query has completed.
+func (r *rows) finish(err error) {
+	r.err = err
+	close(r.values)
+}
+
+// wait blocks until the first row is available or the query has completed.
+// It returns the query's error if the query failed before returning any
+// rows.
+func (r *rows) wait() error {
+	row, ok := <-r.values
+	if !ok {
+		return r.err
+	}
+
+	r.pending = row
+	return nil
+}
+
+// Columns returns the column names.
+func (r *rows) Columns() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		names[i] = column.Name
+	}
+
+	return names
+}
+
+// ColumnTypeScanType returns the go type that values in the column have.
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.columns[index].Type
+}
+
+// ColumnTypeDatabaseTypeName returns the name of the column's Gel type,
+// for example std::int64.
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.columns[index].TypeName
+}
+
+// Next reads the next row into dest.
+func (r *rows) Next(dest []driver.Value) error {
+	row := r.pending
+	r.pending = nil
+
+	if row == nil {
+		// The query may still be running when ctx is done,
+		// it is stopped by Close.
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+
+		var ok bool
+		select {
+		case row, ok = <-r.values:
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		}
+
+		if !ok {
+			if r.err != nil {
+				return r.err
+			}
+			return io.EOF
+		}
+	}
+
+	for i := range dest {
+		dest[i] = row[i]
+	}
+
+	return nil
+}
+
+// Close discards the remaining rows and waits for the query to complete.
+func (r *rows) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	r.pending = nil
+
+	for range r.values {
+		// values are not sent once closed is closed,
+		// wait for the channel to be closed.
+	}
+
+	return r.err
+}
@@ -0,0 +1,159 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/geltypes"
+	gelint "github.com/geldata/gel-go/internal/client"
+	gelerrint "github.com/geldata/gel-go/internal/gelerr"
+)
+
+var _ driver.Tx = (*tx)(nil)
+
+// errRollback is returned from the transaction block to roll back the
+// transaction.
+var errRollback = errors.New("rollback")
+
+// tx adapts the block based transactions of the binary protocol client to
+// the begin/commit/rollback API of database/sql. The transaction block runs
+// in its own goroutine and waits for Commit or Rollback to be called.
+type tx struct {
+	conn *conn
+	tx   *gelint.Tx
+	cfg  gelint.QueryConfig
+
+	started chan *gelint.Tx
+	outcome chan error
+	done    chan error
+}
+
+// BeginTx starts a transaction. Only the default, repeatable read and
+// serializable isolation levels are supported.
+func (c *conn) BeginTx(
+	ctx context.Context,
+	opts driver.TxOptions,
+) (driver.Tx, error) {
+	if c.bad != nil {
+		return nil, driver.ErrBadConn
+	}
+
+	if c.tx != nil {
+		return nil, gelerrint.NewInterfaceError(
+			"a transaction is already in progress", nil)
+	}
+
+	txOpts, err := txOptions(c.cfg.TxOptions, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tx{
+		conn:    c,
+		cfg:     c.cfg,
+		started: make(chan *gelint.Tx),
+		outcome: make(chan error),
+		done:    make(chan error, 1),
+	}
+	t.cfg.TxOptions = txOpts
+
+	// The transaction is ended by Commit or Rollback. database/sql calls
+	// Rollback when ctx is canceled which must not fail because of ctx.
+	go func() {
+		t.done <- c.raw.Tx(
+			context.WithoutCancel(ctx),
+			func(_ context.Context, gtx geltypes.Tx) error {
+				t.started <- gtx.(*gelint.Tx)
+				return <-t.outcome
+			},
+			c.pool.State,
+			&t.cfg,
+		)
+	}()
+
+	select {
+	case t.tx = <-t.started:
+		c.tx = t
+		return t, nil
+	case err := <-t.done:
+		c.track(err)
+		return nil, err
+	}
+}
+
+func txOptions(
+	base gelcfg.TxOptions,
+	opts driver.TxOptions,
+) (gelcfg.TxOptions, error) {
+	switch sql.IsolationLevel(opts.Isolation) {
+	case sql.LevelDefault:
+		// PreferRepeatableRead can restart the transaction block which
+		// database/sql transactions don't support.
+		if base.IsolationLevel() == gelcfg.PreferRepeatableRead {
+			base = base.WithIsolation(gelcfg.Serializable)
+		}
+	case sql.LevelRepeatableRead, sql.LevelSnapshot:
+		base = base.WithIsolation(gelcfg.RepeatableRead)
+	case sql.LevelSerializable:
+		base = base.WithIsolation(gelcfg.Serializable)
+	default:
+		return base, gelerrint.NewUnsupportedFeatureError(fmt.Sprintf(
+			"isolation level %v is not supported",
+			sql.IsolationLevel(opts.Isolation),
+		), nil)
+	}
+
+	if opts.ReadOnly {
+		base = base.WithReadOnly(true)
+	}
+
+	return base, nil
+}
+
+// Commit commits the transaction.
+func (t *tx) Commit() error {
+	return t.end(nil)
+}
+
+// Rollback rolls back the transaction.
+func (t *tx) Rollback() error {
+	err := t.end(errRollback)
+	if errors.Is(err, errRollback) {
+		return nil
+	}
+
+	return err
+}
+
+func (t *tx) end(outcome error) error {
+	if t.conn.tx != t {
+		return gelerrint.NewInterfaceError(
+			"the transaction is already done", nil)
+	}
+
+	t.conn.tx = nil
+	t.outcome <- outcome
+	err := <-t.done
+	t.conn.track(err)
+	return err
+}
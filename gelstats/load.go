@@ -0,0 +1,158 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelstats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/geldata/gel-go/geltypes"
+)
+
+const statsQuery = `
+	SELECT {
+		taken := datetime_of_statement(),
+		stats := (
+			SELECT sys::QueryStats {
+				query,
+				query_type := <str>.query_type,
+				tag,
+				branch_name := .branch.name,
+				calls,
+				rows,
+				total_exec_time,
+				mean_exec_time,
+				min_exec_time,
+				max_exec_time,
+				stddev_exec_time,
+				plans,
+				total_plan_time,
+				mean_plan_time,
+				min_plan_time,
+				max_plan_time,
+				stats_since,
+			}
+			%v
+			ORDER BY .id
+		),
+	}`
+
+type statsRow struct {
+	ID             geltypes.UUID             `gel:"id"`
+	Query          geltypes.OptionalStr      `gel:"query"`
+	QueryType      geltypes.OptionalStr      `gel:"query_type"`
+	Tag            geltypes.OptionalStr      `gel:"tag"`
+	Branch         geltypes.OptionalStr      `gel:"branch_name"`
+	Calls          geltypes.OptionalInt64    `gel:"calls"`
+	Rows           geltypes.OptionalInt64    `gel:"rows"`
+	TotalExecTime  geltypes.OptionalDuration `gel:"total_exec_time"`
+	MeanExecTime   geltypes.OptionalDuration `gel:"mean_exec_time"`
+	MinExecTime    geltypes.OptionalDuration `gel:"min_exec_time"`
+	MaxExecTime    geltypes.OptionalDuration `gel:"max_exec_time"`
+	StddevExecTime geltypes.OptionalDuration `gel:"stddev_exec_time"`
+	Plans          geltypes.OptionalInt64    `gel:"plans"`
+	TotalPlanTime  geltypes.OptionalDuration `gel:"total_plan_time"`
+	MeanPlanTime   geltypes.OptionalDuration `gel:"mean_plan_time"`
+	MinPlanTime    geltypes.OptionalDuration `gel:"min_plan_time"`
+	MaxPlanTime    geltypes.OptionalDuration `gel:"max_plan_time"`
+	StatsSince     geltypes.OptionalDateTime `gel:"stats_since"`
+}
+
+type snapshotRow struct {
+	Taken time.Time  `gel:"taken"`
+	Stats []statsRow `gel:"stats"`
+}
+
+// Load returns a snapshot of the statistics matched by filter.
+func Load(
+	ctx context.Context,
+	q geltypes.Executor,
+	filter Filter,
+) (*Snapshot, error) {
+	var conditions []string
+	var args []any
+	add := func(condition, value string) {
+		conditions = append(conditions,
+			fmt.Sprintf(condition, len(args)))
+		args = append(args, value)
+	}
+
+	if filter.Tag != "" {
+		add(".tag = <str>$%v", filter.Tag)
+	}
+
+	if filter.Branch != "" {
+		add(".branch.name = <str>$%v", filter.Branch)
+	}
+
+	if filter.Query != "" {
+		add("contains(.query, <str>$%v)", filter.Query)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "FILTER " + strings.Join(conditions, " AND ")
+	}
+
+	var row snapshotRow
+	err := q.QuerySingle(ctx, fmt.Sprintf(statsQuery, where), &row, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Taken: row.Taken,
+		Stats: make([]QueryStats, len(row.Stats)),
+	}
+	for i, r := range row.Stats {
+		snapshot.Stats[i] = newQueryStats(r)
+	}
+
+	return snapshot, nil
+}
+
+func newQueryStats(r statsRow) QueryStats {
+	s := QueryStats{
+		ID:             r.ID,
+		TotalExecTime:  duration(r.TotalExecTime),
+		MeanExecTime:   duration(r.MeanExecTime),
+		MinExecTime:    duration(r.MinExecTime),
+		MaxExecTime:    duration(r.MaxExecTime),
+		StddevExecTime: duration(r.StddevExecTime),
+		TotalPlanTime:  duration(r.TotalPlanTime),
+		MeanPlanTime:   duration(r.MeanPlanTime),
+		MinPlanTime:    duration(r.MinPlanTime),
+		MaxPlanTime:    duration(r.MaxPlanTime),
+	}
+	s.Query, _ = r.Query.Get()
+	s.QueryType, _ = r.QueryType.Get()
+	s.Tag, _ = r.Tag.Get()
+	s.Branch, _ = r.Branch.Get()
+	s.Calls, _ = r.Calls.Get()
+	s.Rows, _ = r.Rows.Get()
+	s.Plans, _ = r.Plans.Get()
+	s.StatsSince, _ = r.StatsSince.Get()
+	return s
+}
+
+// duration converts a Gel duration in microseconds to a time.Duration.
+func duration(d geltypes.OptionalDuration) time.Duration {
+	val, _ := d.Get()
+	return time.Duration(val) * time.Microsecond
+}
@@ -0,0 +1,190 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelstats reads and compares query statistics from sys::QueryStats.
+//
+// Use [github.com/geldata/gel-go.Client.QueryStats] to take a [Snapshot] and
+// [Diff] to attribute the load between two snapshots to query tags. Queries
+// are tagged with [github.com/geldata/gel-go.Client.WithQueryTag].
+//
+//	before, err := client.QueryStats(ctx, gelstats.Filter{})
+//	...
+//	after, err := client.QueryStats(ctx, gelstats.Filter{})
+//	for _, load := range gelstats.Diff(before, after).Tags {
+//		fmt.Println(load.Tag, load.Calls, load.ExecTime)
+//	}
+package gelstats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/geldata/gel-go/geltypes"
+)
+
+// Filter selects the statistics that are loaded. Zero fields match all
+// statistics.
+type Filter struct {
+	// Tag matches statistics for queries with the tag.
+	Tag string
+
+	// Branch matches statistics for queries run on the branch.
+	Branch string
+
+	// Query matches statistics for queries that contain the text.
+	Query string
+}
+
+// QueryStats are the statistics of a single query.
+type QueryStats struct {
+	ID geltypes.UUID
+
+	// Query is the text of the query.
+	Query string
+
+	// QueryType is the query language, for example EdgeQL or SQL.
+	QueryType string
+
+	// Tag is the tag the query was first run with. It is empty for
+	// untagged queries.
+	Tag string
+
+	// Branch is the branch the query was run on.
+	Branch string
+
+	// Calls is the number of times the query was executed.
+	Calls int64
+
+	// Rows is the total number of rows returned or affected.
+	Rows int64
+
+	TotalExecTime  time.Duration
+	MeanExecTime   time.Duration
+	MinExecTime    time.Duration
+	MaxExecTime    time.Duration
+	StddevExecTime time.Duration
+
+	// Plans is the number of times the query was planned.
+	Plans int64
+
+	TotalPlanTime time.Duration
+	MeanPlanTime  time.Duration
+	MinPlanTime   time.Duration
+	MaxPlanTime   time.Duration
+
+	// StatsSince is when collecting the statistics started. It changes when
+	// the statistics are reset.
+	StatsSince time.Time
+}
+
+// Snapshot is the statistics at a point in time.
+type Snapshot struct {
+	// Taken is the server time the snapshot was taken at.
+	Taken time.Time
+
+	Stats []QueryStats
+}
+
+// TagLoad is the work done for queries with a tag.
+type TagLoad struct {
+	// Tag is empty for untagged queries.
+	Tag string
+
+	// Queries is the number of distinct queries that were executed.
+	Queries int
+
+	Calls    int64
+	Rows     int64
+	ExecTime time.Duration
+	Plans    int64
+	PlanTime time.Duration
+}
+
+// MeanExecTime returns the average execution time per call.
+func (l *TagLoad) MeanExecTime() time.Duration {
+	if l.Calls == 0 {
+		return 0
+	}
+
+	return l.ExecTime / time.Duration(l.Calls)
+}
+
+// Report is the load between two snapshots.
+type Report struct {
+	Start time.Time
+	End   time.Time
+
+	// Tags are ordered by execution time with the most expensive tag
+	// first.
+	Tags []TagLoad
+}
+
+// Diff returns the load that happened between the before and after
+// snapshots grouped by tag. Statistics that were reset between the
+// snapshots are counted from the reset.
+func Diff(before, after *Snapshot) *Report {
+	previous := make(map[geltypes.UUID]*QueryStats, len(before.Stats))
+	for i := range before.Stats {
+		previous[before.Stats[i].ID] = &before.Stats[i]
+	}
+
+	index := make(map[string]int)
+	report := &Report{Start: before.Taken, End: after.Taken}
+	for _, s := range after.Stats {
+		delta := s
+		if p, ok := previous[s.ID]; ok && !wasReset(p, &s) {
+			delta.Calls -= p.Calls
+			delta.Rows -= p.Rows
+			delta.TotalExecTime -= p.TotalExecTime
+			delta.Plans -= p.Plans
+			delta.TotalPlanTime -= p.TotalPlanTime
+		}
+
+		if delta.Calls == 0 && delta.Plans == 0 {
+			continue
+		}
+
+		i, ok := index[s.Tag]
+		if !ok {
+			i = len(report.Tags)
+			index[s.Tag] = i
+			report.Tags = append(report.Tags, TagLoad{Tag: s.Tag})
+		}
+
+		load := &report.Tags[i]
+		load.Queries++
+		load.Calls += delta.Calls
+		load.Rows += delta.Rows
+		load.ExecTime += delta.TotalExecTime
+		load.Plans += delta.Plans
+		load.PlanTime += delta.TotalPlanTime
+	}
+
+	slices.SortStableFunc(report.Tags, func(a, b TagLoad) int {
+		return cmp.Or(
+			cmp.Compare(b.ExecTime, a.ExecTime),
+			cmp.Compare(a.Tag, b.Tag),
+		)
+	})
+
+	return report
+}
+
+func wasReset(before, after *QueryStats) bool {
+	return !before.StatsSince.Equal(after.StatsSince) ||
+		after.Calls < before.Calls
+}
@@ -0,0 +1,175 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelstats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/geldata/gel-go/gelfake"
+	"github.com/geldata/gel-go/gelstats"
+	"github.com/geldata/gel-go/geltypes"
+)
+
+func TestLoad(t *testing.T) {
+	taken := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := gelfake.New()
+	fake.Expect(gelfake.Regexp(
+		`FILTER \.tag = <str>\$0 AND contains\(\.query, <str>\$1\)`,
+	)).WithArgs("api", "User").Return(map[string]any{
+		"taken": taken,
+		"stats": []map[string]any{{
+			"id":              geltypes.UUID{1},
+			"query":           "SELECT User",
+			"query_type":      "EdgeQL",
+			"tag":             "api",
+			"branch_name":     "main",
+			"calls":           int64(4),
+			"rows":            int64(8),
+			"total_exec_time": geltypes.Duration(2_000),
+			"max_exec_time":   geltypes.Duration(1_500),
+			"stats_since":     taken.Add(-time.Hour),
+		}},
+	})
+
+	snapshot, err := gelstats.Load(
+		context.Background(),
+		fake,
+		gelstats.Filter{Tag: "api", Query: "User"},
+	)
+	require.NoError(t, err)
+	require.NoError(t, fake.ExpectationsWereMet())
+	assert.Equal(t, &gelstats.Snapshot{
+		Taken: taken,
+		Stats: []gelstats.QueryStats{{
+			ID:            geltypes.UUID{1},
+			Query:         "SELECT User",
+			QueryType:     "EdgeQL",
+			Tag:           "api",
+			Branch:        "main",
+			Calls:         4,
+			Rows:          8,
+			TotalExecTime: 2 * time.Millisecond,
+			MaxExecTime:   1500 * time.Microsecond,
+			StatsSince:    taken.Add(-time.Hour),
+		}},
+	}, snapshot)
+}
+
+func TestDiff(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	since := start.Add(-time.Hour)
+
+	before := &gelstats.Snapshot{
+		Taken: start,
+		Stats: []gelstats.QueryStats{
+			{
+				ID:            geltypes.UUID{1},
+				Tag:           "api",
+				Calls:         10,
+				Rows:          10,
+				TotalExecTime: 10 * time.Millisecond,
+				StatsSince:    since,
+			},
+			{
+				ID:            geltypes.UUID{2},
+				Tag:           "worker",
+				Calls:         5,
+				TotalExecTime: time.Second,
+				StatsSince:    since,
+			},
+			{
+				ID:            geltypes.UUID{3},
+				Tag:           "cron",
+				Calls:         7,
+				TotalExecTime: time.Second,
+				StatsSince:    since,
+			},
+		},
+	}
+
+	after := &gelstats.Snapshot{
+		Taken: end,
+		Stats: []gelstats.QueryStats{
+			{
+				ID:            geltypes.UUID{1},
+				Tag:           "api",
+				Calls:         14,
+				Rows:          18,
+				TotalExecTime: 30 * time.Millisecond,
+				Plans:         1,
+				TotalPlanTime: time.Millisecond,
+				StatsSince:    since,
+			},
+			{
+				// The statistics were reset.
+				ID:            geltypes.UUID{2},
+				Tag:           "worker",
+				Calls:         2,
+				TotalExecTime: 500 * time.Millisecond,
+				StatsSince:    start.Add(time.Second),
+			},
+			{
+				// Not executed between the snapshots.
+				ID:            geltypes.UUID{3},
+				Tag:           "cron",
+				Calls:         7,
+				TotalExecTime: time.Second,
+				StatsSince:    since,
+			},
+			{
+				// New query.
+				ID:            geltypes.UUID{4},
+				Tag:           "api",
+				Calls:         1,
+				Rows:          1,
+				TotalExecTime: 5 * time.Millisecond,
+				StatsSince:    start.Add(time.Second),
+			},
+		},
+	}
+
+	report := gelstats.Diff(before, after)
+	assert.Equal(t, &gelstats.Report{
+		Start: start,
+		End:   end,
+		Tags: []gelstats.TagLoad{
+			{
+				Tag:      "worker",
+				Queries:  1,
+				Calls:    2,
+				ExecTime: 500 * time.Millisecond,
+			},
+			{
+				Tag:      "api",
+				Queries:  2,
+				Calls:    5,
+				Rows:     9,
+				ExecTime: 25 * time.Millisecond,
+				Plans:    1,
+				PlanTime: time.Millisecond,
+			},
+		},
+	}, report)
+	assert.Equal(t, 5*time.Millisecond, report.Tags[1].MeanExecTime())
+	assert.Equal(t, time.Duration(0), (&gelstats.TagLoad{}).MeanExecTime())
+}
@@ -0,0 +1,219 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geltest isolates tests that use a Gel database.
+//
+// Create an [Env] once, usually in TestMain, and use it to give each test
+// its own branch or its own transaction. Both are cleaned up when the test
+// finishes and both can be used by parallel tests.
+//
+//	var env *geltest.Env
+//
+//	func TestMain(m *testing.M) {
+//		var err error
+//		env, err = geltest.New(geltest.Options{Template: "test_template"})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		code := m.Run()
+//		_ = env.Close()
+//		os.Exit(code)
+//	}
+//
+//	func TestCreateUser(t *testing.T) {
+//		t.Parallel()
+//		tx := env.Tx(t)
+//		err := tx.Execute(ctx, `INSERT User { name := 'Alice' }`)
+//		...
+//	}
+//
+// Transactions are cheaper than branches but can't be used to test code that
+// runs its own transactions or executes DDL.
+package geltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/testserver"
+)
+
+// Options configures an [Env].
+type Options struct {
+	// DSN and Connect configure the connection like in
+	// [github.com/geldata/gel-go.CreateClientDSN]. If both are empty the
+	// connection is resolved from the environment or the current project.
+	DSN     string
+	Connect gelcfg.Options
+
+	// StartServer starts a throwaway server, or reuses the one started by a
+	// previous test run, instead of connecting to the configured instance.
+	// The server is started the same way the gel-go tests start it and the
+	// server binary is read from the EDGEDB_SERVER_BIN environment variable.
+	// StartServer can't be combined with DSN and replaces Connect with the
+	// server's connection options.
+	StartServer bool
+
+	// Template is the branch that test branches are created from. If it is
+	// empty the branch the client connects to is used. Tests must not
+	// modify the template.
+	Template string
+
+	// CopyData makes test branches copy the template's data. By default
+	// only the schema is copied.
+	CopyData bool
+}
+
+// Env creates isolated branches and transactions for tests.
+type Env struct {
+	client   *gel.Client
+	template string
+	typ      gelcfg.BranchType
+}
+
+// New connects to the template branch and returns an Env. Call [Env.Close]
+// when all tests are done. It returns an error if both opts.StartServer and
+// opts.DSN are set.
+func New(opts Options) (*Env, error) { // nolint:gocritic
+	if opts.StartServer && opts.DSN != "" {
+		return nil, errors.New("geltest: StartServer can't be used with DSN")
+	}
+
+	connect := opts.Connect
+	if opts.StartServer {
+		var err error
+		connect, err = testserver.StartServer()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := gel.CreateClientDSN(opts.DSN, connect)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	template := opts.Template
+	if template == "" {
+		err = client.QuerySingle(
+			ctx,
+			"SELECT sys::get_current_branch()",
+			&template,
+		)
+		if err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	} else {
+		base := client
+		client = base.WithBranch(template)
+		_ = base.Close()
+	}
+
+	typ := gelcfg.SchemaBranch
+	if opts.CopyData {
+		typ = gelcfg.DataBranch
+	}
+
+	return &Env{client: client, template: template, typ: typ}, nil
+}
+
+// Client returns the client for the template branch.
+func (e *Env) Client() *gel.Client { return e.client }
+
+// Close closes the template branch's client.
+func (e *Env) Close() error { return e.client.Close() }
+
+// Branch creates a branch from the template and returns a client for it.
+// The client is closed and the branch is dropped when t finishes.
+func (e *Env) Branch(t testing.TB) *gel.Client {
+	t.Helper()
+	ctx := context.Background()
+	name := fmt.Sprintf("geltest_%v", rand.Uint64())
+
+	err := e.client.CreateBranch(ctx, name, e.typ, e.template)
+	if err != nil {
+		t.Fatalf("creating test branch: %v", err)
+	}
+
+	client := e.client.WithBranch(name)
+	t.Cleanup(func() {
+		err := errors.Join(
+			client.Close(),
+			e.client.DropBranch(ctx, name, true),
+		)
+		if err != nil {
+			t.Errorf("dropping test branch %v: %v", name, err)
+		}
+	})
+
+	return client
+}
+
+// errRollback ends a test transaction.
+var errRollback = errors.New("rollback")
+
+// noRetries makes the test transaction fail on its first error. Retrying
+// would run queries that the test already saw fail a second time.
+var noRetries = gelcfg.NewRetryOptions().
+	WithDefault(gelcfg.NewRetryRule().WithAttempts(1))
+
+// Tx starts a transaction on the template branch that is rolled back when t
+// finishes. The transaction is not retried, so queries fail on their first
+// error. Like any transaction it must not be used concurrently.
+func (e *Env) Tx(t testing.TB) geltypes.Executor {
+	t.Helper()
+
+	started := make(chan geltypes.Tx)
+	finish := make(chan struct{})
+	done := make(chan error, 1)
+
+	client := e.client.WithRetryOptions(noRetries)
+	go func() {
+		done <- client.Tx(
+			context.Background(),
+			func(_ context.Context, tx geltypes.Tx) error {
+				started <- tx
+				<-finish
+				return errRollback
+			},
+		)
+	}()
+
+	var tx geltypes.Tx
+	select {
+	case tx = <-started:
+	case err := <-done:
+		t.Fatalf("starting test transaction: %v", err)
+	}
+
+	t.Cleanup(func() {
+		close(finish)
+		if err := <-done; !errors.Is(err, errRollback) {
+			t.Errorf("rolling back test transaction: %v", err)
+		}
+	})
+
+	return tx
+}
@@ -0,0 +1,109 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geltest
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var env *Env
+
+func TestMain(m *testing.M) {
+	var err error
+	env, err = New(Options{StartServer: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = env.Client().Execute(context.Background(), `
+		CREATE TYPE GelTestItem { CREATE PROPERTY name -> str; };
+	`)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+	err = env.Client().Execute(
+		context.Background(),
+		`DROP TYPE GelTestItem;`,
+	)
+	if err != nil {
+		log.Println(err)
+	}
+
+	_ = env.Close()
+	os.Exit(code)
+}
+
+func countItems(t *testing.T, q geltypes.Executor) int64 {
+	var count int64
+	query := "SELECT count(GelTestItem)"
+	err := q.QuerySingle(context.Background(), query, &count)
+	require.NoError(t, err)
+	return count
+}
+
+func TestTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("insert", func(t *testing.T) {
+		t.Parallel()
+		tx := env.Tx(t)
+		err := tx.Execute(ctx, "INSERT GelTestItem { name := 'a' }")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), countItems(t, tx))
+	})
+
+	t.Run("parallel insert", func(t *testing.T) {
+		t.Parallel()
+		tx := env.Tx(t)
+		err := tx.Execute(ctx, "INSERT GelTestItem { name := 'b' }")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), countItems(t, tx))
+	})
+
+	t.Cleanup(func() {
+		assert.Equal(t, int64(0), countItems(t, env.Client()))
+	})
+}
+
+func TestBranch(t *testing.T) {
+	ctx := context.Background()
+
+	client := env.Branch(t)
+	err := client.Execute(ctx, "INSERT GelTestItem { name := 'a' }")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), countItems(t, client))
+
+	// DDL is isolated too.
+	err = client.Execute(ctx, "DROP TYPE GelTestItem")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(0), countItems(t, env.Client()))
+}
+
+func TestNewStartServerWithDSN(t *testing.T) {
+	_, err := New(Options{StartServer: true, DSN: "gel://localhost"})
+	assert.EqualError(t, err, "geltest: StartServer can't be used with DSN")
+}
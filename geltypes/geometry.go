@@ -0,0 +1,571 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geltypes
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Layout is the dimensions of the coordinates of a [Geometry].
+type Layout uint8
+
+const (
+	// XY coordinates have two dimensions.
+	XY Layout = iota
+	// XYZ coordinates have an elevation.
+	XYZ
+	// XYM coordinates have a measure.
+	XYM
+	// XYZM coordinates have an elevation and a measure.
+	XYZM
+)
+
+func (l Layout) hasZ() bool { return l == XYZ || l == XYZM }
+
+func (l Layout) hasM() bool { return l == XYM || l == XYZM }
+
+// stride is the number of values in a coordinate.
+func (l Layout) stride() int {
+	switch l {
+	case XY:
+		return 2
+	case XYZM:
+		return 4
+	default:
+		return 3
+	}
+}
+
+func (l Layout) String() string {
+	switch l {
+	case XY:
+		return "XY"
+	case XYZ:
+		return "XYZ"
+	case XYM:
+		return "XYM"
+	case XYZM:
+		return "XYZM"
+	default:
+		return fmt.Sprintf("Layout(%d)", uint8(l))
+	}
+}
+
+// Geometry is an ext::postgis::geometry or ext::postgis::geography value.
+//
+// Geometries are sent as extended well-known binary, marshaled as text in
+// the extended well-known text format, for example SRID=4326;POINT(1 2),
+// and marshaled as JSON in the GeoJSON format.
+type Geometry struct {
+	// SRID is the spatial reference system identifier, for example 4326
+	// for WGS 84. Zero means the reference system is unknown.
+	SRID int32
+
+	// Layout applies to all coordinates of the shape.
+	Layout Layout
+
+	Shape Shape
+}
+
+// Shape is one of [Point], [LineString], [Polygon], [MultiPoint],
+// [MultiLineString], [MultiPolygon] or [GeometryCollection].
+type Shape interface {
+	wkbType() uint32
+}
+
+// Point is a position. Z and M are ignored unless the layout of the
+// geometry has them. A point with NaN coordinates is empty.
+type Point struct {
+	X, Y, Z, M float64
+}
+
+// LineString is a sequence of points.
+type LineString []Point
+
+// Polygon is a list of rings. The first ring is the exterior of the polygon
+// and the remaining rings are holes.
+type Polygon []LineString
+
+// MultiPoint is a collection of points.
+type MultiPoint []Point
+
+// MultiLineString is a collection of line strings.
+type MultiLineString []LineString
+
+// MultiPolygon is a collection of polygons.
+type MultiPolygon []Polygon
+
+// GeometryCollection is a collection of shapes of any kind.
+type GeometryCollection []Shape
+
+// well-known binary geometry types
+const (
+	wkbPoint uint32 = iota + 1
+	wkbLineString
+	wkbPolygon
+	wkbMultiPoint
+	wkbMultiLineString
+	wkbMultiPolygon
+	wkbGeometryCollection
+)
+
+// extended well-known binary flags
+const (
+	ewkbZ    uint32 = 0x80000000
+	ewkbM    uint32 = 0x40000000
+	ewkbSRID uint32 = 0x20000000
+)
+
+func (Point) wkbType() uint32              { return wkbPoint }
+func (LineString) wkbType() uint32         { return wkbLineString }
+func (Polygon) wkbType() uint32            { return wkbPolygon }
+func (MultiPoint) wkbType() uint32         { return wkbMultiPoint }
+func (MultiLineString) wkbType() uint32    { return wkbMultiLineString }
+func (MultiPolygon) wkbType() uint32       { return wkbMultiPolygon }
+func (GeometryCollection) wkbType() uint32 { return wkbGeometryCollection }
+
+func emptyPoint() Point {
+	nan := math.NaN()
+	return Point{nan, nan, nan, nan}
+}
+
+func (p Point) empty() bool { return math.IsNaN(p.X) && math.IsNaN(p.Y) }
+
+var errNoShape = errors.New("geltypes.Geometry has no shape")
+
+// MarshalBinary returns g in the extended well-known binary format.
+func (g Geometry) MarshalBinary() ([]byte, error) {
+	w := wkbWriter{layout: g.Layout}
+	if err := w.geometry(g.Shape, g.SRID); err != nil {
+		return nil, err
+	}
+
+	return w.buf, nil
+}
+
+// UnmarshalBinary unmarshals well-known binary or extended well-known
+// binary into *g.
+func (g *Geometry) UnmarshalBinary(data []byte) error {
+	r := wkbReader{data: data}
+	shape, srid, err := r.geometry()
+	if err != nil {
+		return fmt.Errorf("malformed geltypes.Geometry: %w", err)
+	}
+
+	if len(r.data) != 0 {
+		return fmt.Errorf("malformed geltypes.Geometry: "+
+			"%v unexpected trailing bytes", len(r.data))
+	}
+
+	*g = Geometry{SRID: srid, Layout: r.layout, Shape: shape}
+	return nil
+}
+
+type wkbWriter struct {
+	buf    []byte
+	layout Layout
+}
+
+func (w *wkbWriter) uint32(v uint32) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+func (w *wkbWriter) count(n int) { w.uint32(uint32(n)) }
+
+func (w *wkbWriter) point(p Point) {
+	w.float(p.X)
+	w.float(p.Y)
+	if w.layout.hasZ() {
+		w.float(p.Z)
+	}
+	if w.layout.hasM() {
+		w.float(p.M)
+	}
+}
+
+func (w *wkbWriter) float(f float64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(f))
+}
+
+func (w *wkbWriter) points(points []Point) {
+	w.count(len(points))
+	for _, p := range points {
+		w.point(p)
+	}
+}
+
+func (w *wkbWriter) rings(rings []LineString) {
+	w.count(len(rings))
+	for _, ring := range rings {
+		w.points(ring)
+	}
+}
+
+func (w *wkbWriter) geometry(shape Shape, srid int32) error {
+	if shape == nil {
+		return errNoShape
+	}
+
+	typ := shape.wkbType()
+	if w.layout.hasZ() {
+		typ |= ewkbZ
+	}
+	if w.layout.hasM() {
+		typ |= ewkbM
+	}
+	if srid != 0 {
+		typ |= ewkbSRID
+	}
+
+	w.buf = append(w.buf, 1) // little endian
+	w.uint32(typ)
+	if srid != 0 {
+		w.uint32(uint32(srid))
+	}
+
+	switch s := shape.(type) {
+	case Point:
+		w.point(s)
+	case LineString:
+		w.points(s)
+	case Polygon:
+		w.rings(s)
+	case MultiPoint:
+		w.count(len(s))
+		for _, p := range s {
+			_ = w.geometry(p, 0)
+		}
+	case MultiLineString:
+		w.count(len(s))
+		for _, l := range s {
+			_ = w.geometry(l, 0)
+		}
+	case MultiPolygon:
+		w.count(len(s))
+		for _, p := range s {
+			_ = w.geometry(p, 0)
+		}
+	case GeometryCollection:
+		w.count(len(s))
+		for _, child := range s {
+			if err := w.geometry(child, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type wkbReader struct {
+	data      []byte
+	order     binary.ByteOrder
+	layout    Layout
+	layoutSet bool
+}
+
+var errShortWKB = errors.New("unexpected end of data")
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.data) < 4 {
+		return 0, errShortWKB
+	}
+
+	v := r.order.Uint32(r.data)
+	r.data = r.data[4:]
+	return v, nil
+}
+
+// count reads the number of elements that follow and checks that the data
+// is long enough to hold them.
+func (r *wkbReader) count(minSize int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(n)*uint64(minSize) > uint64(len(r.data)) {
+		return 0, errShortWKB
+	}
+
+	return int(n), nil
+}
+
+func (r *wkbReader) float() float64 {
+	v := math.Float64frombits(r.order.Uint64(r.data))
+	r.data = r.data[8:]
+	return v
+}
+
+func (r *wkbReader) point() (Point, error) {
+	if len(r.data) < 8*r.layout.stride() {
+		return Point{}, errShortWKB
+	}
+
+	p := Point{X: r.float(), Y: r.float()}
+	if r.layout.hasZ() {
+		p.Z = r.float()
+	}
+	if r.layout.hasM() {
+		p.M = r.float()
+	}
+
+	return p, nil
+}
+
+func (r *wkbReader) points() ([]Point, error) {
+	n, err := r.count(8 * r.layout.stride())
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]Point, n)
+	for i := range points {
+		if points[i], err = r.point(); err != nil {
+			return nil, err
+		}
+	}
+
+	return points, nil
+}
+
+func (r *wkbReader) rings() ([]LineString, error) {
+	n, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+
+	rings := make([]LineString, n)
+	for i := range rings {
+		if rings[i], err = r.points(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rings, nil
+}
+
+// header reads the byte order, type and SRID of a geometry.
+func (r *wkbReader) header() (uint32, int32, error) {
+	if len(r.data) < 1 {
+		return 0, 0, errShortWKB
+	}
+
+	switch r.data[0] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return 0, 0, fmt.Errorf("invalid byte order %v", r.data[0])
+	}
+	r.data = r.data[1:]
+
+	typ, err := r.uint32()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var srid int32
+	if typ&ewkbSRID != 0 {
+		v, err := r.uint32()
+		if err != nil {
+			return 0, 0, err
+		}
+		srid = int32(v)
+	}
+
+	hasZ := typ&ewkbZ != 0
+	hasM := typ&ewkbM != 0
+	typ &^= ewkbZ | ewkbM | ewkbSRID
+
+	// ISO well-known binary adds 1000 for Z, 2000 for M and 3000 for ZM.
+	switch typ / 1000 {
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ, hasM = true, true
+	}
+	typ %= 1000
+
+	layout := XY
+	switch {
+	case hasZ && hasM:
+		layout = XYZM
+	case hasZ:
+		layout = XYZ
+	case hasM:
+		layout = XYM
+	}
+
+	if r.layoutSet && layout != r.layout {
+		return 0, 0, fmt.Errorf("mixed %v and %v coordinates",
+			r.layout, layout)
+	}
+	r.layout = layout
+	r.layoutSet = true
+
+	return typ, srid, nil
+}
+
+// child reads a geometry nested in a multi geometry.
+func (r *wkbReader) child(expected uint32) (Shape, error) {
+	shape, _, err := r.geometry()
+	if err != nil {
+		return nil, err
+	}
+
+	if shape.wkbType() != expected {
+		return nil, fmt.Errorf("unexpected geometry type %v in %v",
+			shape.wkbType(), expected)
+	}
+
+	return shape, nil
+}
+
+func (r *wkbReader) geometry() (Shape, int32, error) {
+	typ, srid, err := r.header()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var shape Shape
+	switch typ {
+	case wkbPoint:
+		shape, err = r.point()
+	case wkbLineString:
+		var points []Point
+		points, err = r.points()
+		shape = LineString(points)
+	case wkbPolygon:
+		var rings []LineString
+		rings, err = r.rings()
+		shape = Polygon(rings)
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon,
+		wkbGeometryCollection:
+		shape, err = r.collection(typ)
+	default:
+		return nil, 0, fmt.Errorf("unknown geometry type %v", typ)
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return shape, srid, nil
+}
+
+func (r *wkbReader) collection(typ uint32) (Shape, error) {
+	n, err := r.count(5)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]Shape, n)
+	for i := range children {
+		if typ == wkbGeometryCollection {
+			children[i], _, err = r.geometry()
+		} else {
+			children[i], err = r.child(typ - 3)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch typ {
+	case wkbMultiPoint:
+		points := make(MultiPoint, n)
+		for i, child := range children {
+			points[i] = child.(Point)
+		}
+		return points, nil
+	case wkbMultiLineString:
+		lines := make(MultiLineString, n)
+		for i, child := range children {
+			lines[i] = child.(LineString)
+		}
+		return lines, nil
+	case wkbMultiPolygon:
+		polygons := make(MultiPolygon, n)
+		for i, child := range children {
+			polygons[i] = child.(Polygon)
+		}
+		return polygons, nil
+	default:
+		return GeometryCollection(children), nil
+	}
+}
+
+// NewOptionalGeometry is a convenience function for creating an
+// OptionalGeometry with its value set to v.
+func NewOptionalGeometry(v Geometry) OptionalGeometry {
+	o := OptionalGeometry{}
+	o.Set(v)
+	return o
+}
+
+// OptionalGeometry is an optional Geometry. Optional types must be used for
+// out parameters when a shape field is not required.
+type OptionalGeometry struct {
+	val   Geometry
+	isSet bool
+}
+
+// Get returns the value and a boolean indicating if the value is present.
+func (o OptionalGeometry) Get() (Geometry, bool) { return o.val, o.isSet }
+
+// Set sets the value.
+func (o *OptionalGeometry) Set(val Geometry) {
+	o.val = val
+	o.isSet = true
+}
+
+// Unset marks the value as missing.
+func (o *OptionalGeometry) Unset() {
+	o.val = Geometry{}
+	o.isSet = false
+}
+
+// MarshalJSON returns o marshaled as json.
+func (o OptionalGeometry) MarshalJSON() ([]byte, error) {
+	if o.isSet {
+		return json.Marshal(o.val)
+	}
+	return json.Marshal(nil)
+}
+
+// UnmarshalJSON unmarshals bytes into *o.
+func (o *OptionalGeometry) UnmarshalJSON(bytes []byte) error {
+	if bytes[0] == 0x6e { // null
+		o.Unset()
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, &o.val); err != nil {
+		return err
+	}
+	o.isSet = true
+
+	return nil
+}
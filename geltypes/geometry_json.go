@@ -0,0 +1,281 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geltypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var geoJSONNames = map[uint32]string{
+	wkbPoint:              "Point",
+	wkbLineString:         "LineString",
+	wkbPolygon:            "Polygon",
+	wkbMultiPoint:         "MultiPoint",
+	wkbMultiLineString:    "MultiLineString",
+	wkbMultiPolygon:       "MultiPolygon",
+	wkbGeometryCollection: "GeometryCollection",
+}
+
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []geoJSON       `json:"geometries,omitempty"`
+	CRS         *geoJSONCRS     `json:"crs,omitempty"`
+}
+
+type geoJSONCRS struct {
+	Type       string `json:"type"`
+	Properties struct {
+		Name string `json:"name"`
+	} `json:"properties"`
+}
+
+// MarshalJSON returns g as a GeoJSON geometry. The SRID is included as a
+// named crs member if it is not zero. GeoJSON has no measures so M values
+// are omitted.
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	obj, err := toGeoJSON(g.Shape, g.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.SRID != 0 {
+		obj.CRS = &geoJSONCRS{Type: "name"}
+		obj.CRS.Properties.Name = fmt.Sprintf("EPSG:%d", g.SRID)
+	}
+
+	return json.Marshal(obj)
+}
+
+// UnmarshalJSON unmarshals a GeoJSON geometry into *g.
+func (g *Geometry) UnmarshalJSON(b []byte) error {
+	var obj geoJSON
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+
+	p := geoJSONParser{}
+	shape, err := p.geometry(&obj)
+	if err != nil {
+		return fmt.Errorf("malformed geltypes.Geometry: %w", err)
+	}
+
+	var srid int64
+	if obj.CRS != nil {
+		name := obj.CRS.Properties.Name
+		code := name[strings.LastIndex(name, ":")+1:]
+		srid, err = strconv.ParseInt(code, 10, 32)
+		if err != nil {
+			return fmt.Errorf(
+				"malformed geltypes.Geometry: unsupported crs %q", name)
+		}
+	}
+
+	*g = Geometry{SRID: int32(srid), Layout: p.layout, Shape: shape}
+	return nil
+}
+
+func toGeoJSON(shape Shape, layout Layout) (geoJSON, error) {
+	if shape == nil {
+		return geoJSON{}, errNoShape
+	}
+
+	obj := geoJSON{Type: geoJSONNames[shape.wkbType()]}
+	var coords any
+	switch s := shape.(type) {
+	case Point:
+		if s.empty() {
+			coords = []float64{}
+		} else {
+			coords = position(s, layout)
+		}
+	case LineString:
+		coords = positions(s, layout)
+	case Polygon:
+		coords = ringPositions(s, layout)
+	case MultiPoint:
+		coords = positions(s, layout)
+	case MultiLineString:
+		coords = ringPositions(s, layout)
+	case MultiPolygon:
+		polygons := make([][][][]float64, len(s))
+		for i, p := range s {
+			polygons[i] = ringPositions(p, layout)
+		}
+		coords = polygons
+	case GeometryCollection:
+		obj.Geometries = make([]geoJSON, len(s))
+		for i, child := range s {
+			var err error
+			if obj.Geometries[i], err = toGeoJSON(child, layout); err != nil {
+				return geoJSON{}, err
+			}
+		}
+		return obj, nil
+	}
+
+	var err error
+	obj.Coordinates, err = json.Marshal(coords)
+	return obj, err
+}
+
+func position(p Point, layout Layout) []float64 {
+	if layout.hasZ() {
+		return []float64{p.X, p.Y, p.Z}
+	}
+
+	return []float64{p.X, p.Y}
+}
+
+func positions(points []Point, layout Layout) [][]float64 {
+	coords := make([][]float64, len(points))
+	for i, p := range points {
+		coords[i] = position(p, layout)
+	}
+
+	return coords
+}
+
+func ringPositions(rings []LineString, layout Layout) [][][]float64 {
+	coords := make([][][]float64, len(rings))
+	for i, ring := range rings {
+		coords[i] = positions(ring, layout)
+	}
+
+	return coords
+}
+
+type geoJSONParser struct {
+	layout    Layout
+	layoutSet bool
+}
+
+func (p *geoJSONParser) point(position []float64) (Point, error) {
+	layout := XY
+	switch len(position) {
+	case 2:
+	case 3:
+		layout = XYZ
+	default:
+		return Point{}, fmt.Errorf("position with %v values", len(position))
+	}
+
+	if p.layoutSet && p.layout != layout {
+		return Point{}, fmt.Errorf("mixed %v and %v coordinates",
+			p.layout, layout)
+	}
+	p.layout = layout
+	p.layoutSet = true
+
+	point := Point{X: position[0], Y: position[1]}
+	if layout == XYZ {
+		point.Z = position[2]
+	}
+
+	return point, nil
+}
+
+func (p *geoJSONParser) points(positions [][]float64) ([]Point, error) {
+	points := make([]Point, len(positions))
+	for i, position := range positions {
+		var err error
+		if points[i], err = p.point(position); err != nil {
+			return nil, err
+		}
+	}
+
+	return points, nil
+}
+
+func (p *geoJSONParser) rings(positions [][][]float64) ([]LineString, error) {
+	rings := make([]LineString, len(positions))
+	for i, ring := range positions {
+		var err error
+		if rings[i], err = p.points(ring); err != nil {
+			return nil, err
+		}
+	}
+
+	return rings, nil
+}
+
+func (p *geoJSONParser) geometry(obj *geoJSON) (Shape, error) {
+	if obj.Type == "GeometryCollection" {
+		shapes := make(GeometryCollection, len(obj.Geometries))
+		for i := range obj.Geometries {
+			var err error
+			if shapes[i], err = p.geometry(&obj.Geometries[i]); err != nil {
+				return nil, err
+			}
+		}
+		return shapes, nil
+	}
+
+	if obj.Coordinates == nil {
+		return nil, fmt.Errorf("%v has no coordinates", obj.Type)
+	}
+
+	switch obj.Type {
+	case "Point":
+		var position []float64
+		if err := json.Unmarshal(obj.Coordinates, &position); err != nil {
+			return nil, err
+		}
+		if len(position) == 0 {
+			return emptyPoint(), nil
+		}
+		return p.point(position)
+	case "LineString", "MultiPoint":
+		var positions [][]float64
+		if err := json.Unmarshal(obj.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		points, err := p.points(positions)
+		if obj.Type == "MultiPoint" {
+			return MultiPoint(points), err
+		}
+		return LineString(points), err
+	case "Polygon", "MultiLineString":
+		var positions [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		rings, err := p.rings(positions)
+		if obj.Type == "MultiLineString" {
+			return MultiLineString(rings), err
+		}
+		return Polygon(rings), err
+	case "MultiPolygon":
+		var positions [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		polygons := make(MultiPolygon, len(positions))
+		for i, polygon := range positions {
+			var err error
+			if polygons[i], err = p.rings(polygon); err != nil {
+				return nil, err
+			}
+		}
+		return polygons, nil
+	default:
+		return nil, fmt.Errorf("unknown geometry type %q", obj.Type)
+	}
+}
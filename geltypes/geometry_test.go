@@ -0,0 +1,260 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geltypes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var geometrySamples = []struct {
+	geometry Geometry
+	wkt      string
+	wkb      string
+}{
+	{
+		Geometry{SRID: 4326, Shape: Point{X: 1, Y: 2}},
+		"SRID=4326;POINT(1 2)",
+		"0101000020e6100000000000000000f03f0000000000000040",
+	},
+	{
+		Geometry{Layout: XYZ, Shape: LineString{
+			{X: 1, Y: 2, Z: 3},
+			{X: 4, Y: 5, Z: 6},
+		}},
+		"LINESTRING Z (1 2 3,4 5 6)",
+		"010200008002000000000000000000f03f000000000000004000000000000008" +
+			"4000000000000010400000000000001440" + "0000000000001840",
+	},
+	{
+		Geometry{Shape: Polygon{
+			{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 4}, {X: 0, Y: 0}},
+			{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 1}},
+		}},
+		"POLYGON((0 0,4 0,0 4,0 0),(1 1,2 1,1 2,1 1))",
+		"",
+	},
+	{
+		Geometry{Layout: XYM, Shape: MultiPoint{
+			{X: 1, Y: 2, M: 3},
+			{X: -1.5, Y: 0.25, M: 0},
+		}},
+		"MULTIPOINT M ((1 2 3),(-1.5 0.25 0))",
+		"",
+	},
+	{
+		Geometry{Shape: MultiLineString{
+			{{X: 0, Y: 0}, {X: 1, Y: 1}},
+			{{X: 2, Y: 2}, {X: 3, Y: 3}},
+		}},
+		"MULTILINESTRING((0 0,1 1),(2 2,3 3))",
+		"",
+	},
+	{
+		Geometry{SRID: 3857, Shape: MultiPolygon{
+			{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 0}}},
+		}},
+		"SRID=3857;MULTIPOLYGON(((0 0,1 0,0 1,0 0)))",
+		"",
+	},
+	{
+		Geometry{Layout: XYZM, Shape: GeometryCollection{
+			Point{X: 1, Y: 2, Z: 3, M: 4},
+			LineString{},
+		}},
+		"GEOMETRYCOLLECTION ZM (POINT ZM (1 2 3 4),LINESTRING ZM EMPTY)",
+		"",
+	},
+	{
+		Geometry{Shape: GeometryCollection{}},
+		"GEOMETRYCOLLECTION EMPTY",
+		"",
+	},
+}
+
+func TestGeometryWKB(t *testing.T) {
+	for _, sample := range geometrySamples {
+		t.Run(sample.wkt, func(t *testing.T) {
+			data, err := sample.geometry.MarshalBinary()
+			require.NoError(t, err)
+			if sample.wkb != "" {
+				assert.Equal(t, sample.wkb, hex.EncodeToString(data))
+			}
+
+			var g Geometry
+			require.NoError(t, g.UnmarshalBinary(data))
+			assert.Equal(t, sample.geometry, g)
+		})
+	}
+}
+
+func TestGeometryWKBBigEndianISO(t *testing.T) {
+	// POINT Z (1 2 3) in big endian ISO well-known binary
+	data, err := hex.DecodeString("00000003e9" +
+		"3ff0000000000000" + "4000000000000000" + "4008000000000000")
+	require.NoError(t, err)
+
+	var g Geometry
+	require.NoError(t, g.UnmarshalBinary(data))
+	assert.Equal(t, Geometry{Layout: XYZ, Shape: Point{1, 2, 3, 0}}, g)
+}
+
+func TestGeometryWKBInvalid(t *testing.T) {
+	var g Geometry
+	assert.EqualError(t, g.UnmarshalBinary([]byte{1, 2, 0, 0, 0, 1}),
+		"malformed geltypes.Geometry: unexpected end of data")
+
+	// A line string claiming a huge number of points.
+	data, err := hex.DecodeString("0102000000ffffffff")
+	require.NoError(t, err)
+	assert.EqualError(t, g.UnmarshalBinary(data),
+		"malformed geltypes.Geometry: unexpected end of data")
+
+	_, err = Geometry{}.MarshalBinary()
+	assert.EqualError(t, err, "geltypes.Geometry has no shape")
+}
+
+func TestGeometryWKT(t *testing.T) {
+	for _, sample := range geometrySamples {
+		t.Run(sample.wkt, func(t *testing.T) {
+			assert.Equal(t, sample.wkt, sample.geometry.String())
+
+			var g Geometry
+			require.NoError(t, g.UnmarshalText([]byte(sample.wkt)))
+			assert.Equal(t, sample.geometry, g)
+		})
+	}
+}
+
+func TestGeometryWKTVariants(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected Geometry
+	}{
+		{
+			"srid=4326; point ( 1 2 )",
+			Geometry{SRID: 4326, Shape: Point{X: 1, Y: 2}},
+		},
+		{"POINT(1 2 3)", Geometry{Layout: XYZ, Shape: Point{1, 2, 3, 0}}},
+		{"POINTM(1 2 3)", Geometry{Layout: XYM, Shape: Point{1, 2, 0, 3}}},
+		{
+			"MULTIPOINT(1 2, 3 4)",
+			Geometry{Shape: MultiPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		},
+		{"LINESTRING EMPTY", Geometry{Shape: LineString{}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			var g Geometry
+			require.NoError(t, g.UnmarshalText([]byte(c.text)))
+			assert.Equal(t, c.expected, g)
+		})
+	}
+
+	var g Geometry
+	require.NoError(t, g.UnmarshalText([]byte("POINT EMPTY")))
+	assert.True(t, math.IsNaN(g.Shape.(Point).X))
+	assert.Equal(t, "POINT EMPTY", g.String())
+
+	errors := map[string]string{
+		"CIRCLE(1 2)":           `unknown geometry type "CIRCLE"`,
+		"POINT(1)":              "coordinate with 1 values",
+		"LINESTRING(1 2,1 2 3)": "mixed XY and XYZ coordinates",
+		"POINT Z (1 2)":         "mixed XYZ and XY coordinates",
+		"POINT(1 2":             `expected ')', got end of text`,
+		"POINT(1 2) x":          `unexpected "x"`,
+		"SRID=x;POINT(1 2)": `invalid SRID: strconv.ParseInt: ` +
+			`parsing "x": invalid syntax`,
+	}
+
+	for text, msg := range errors {
+		err := g.UnmarshalText([]byte(text))
+		assert.EqualError(t, err, "malformed geltypes.Geometry: "+msg, text)
+	}
+}
+
+func TestGeometryGeoJSON(t *testing.T) {
+	cases := []struct {
+		geometry Geometry
+		json     string
+	}{
+		{
+			Geometry{SRID: 4326, Shape: Point{X: 1, Y: 2}},
+			`{"type":"Point","coordinates":[1,2],` +
+				`"crs":{"type":"name","properties":{"name":"EPSG:4326"}}}`,
+		},
+		{
+			Geometry{Layout: XYZ, Shape: LineString{{1, 2, 3, 0}}},
+			`{"type":"LineString","coordinates":[[1,2,3]]}`,
+		},
+		{
+			Geometry{Shape: MultiPolygon{
+				{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}}},
+			}},
+			`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,0]]]]}`,
+		},
+		{
+			Geometry{Shape: GeometryCollection{
+				Point{X: 1, Y: 2},
+				MultiLineString{{{X: 3, Y: 4}}},
+			}},
+			`{"type":"GeometryCollection","geometries":[` +
+				`{"type":"Point","coordinates":[1,2]},` +
+				`{"type":"MultiLineString","coordinates":[[[3,4]]]}]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.json, func(t *testing.T) {
+			data, err := json.Marshal(c.geometry)
+			require.NoError(t, err)
+			assert.Equal(t, c.json, string(data))
+
+			var g Geometry
+			require.NoError(t, json.Unmarshal(data, &g))
+			assert.Equal(t, c.geometry, g)
+		})
+	}
+
+	var g Geometry
+	err := json.Unmarshal([]byte(`{"type":"Point","coordinates":[1,2],`+
+		`"crs":{"type":"name",`+
+		`"properties":{"name":"urn:ogc:def:crs:EPSG::4326"}}}`), &g)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4326), g.SRID)
+}
+
+func TestOptionalGeometryJSON(t *testing.T) {
+	b, err := json.Marshal(OptionalGeometry{})
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(b))
+
+	o := NewOptionalGeometry(Geometry{Shape: Point{X: 1, Y: 2}})
+	b, err = json.Marshal(o)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"Point","coordinates":[1,2]}`, string(b))
+
+	var out OptionalGeometry
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, o, out)
+}
@@ -0,0 +1,510 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geltypes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var wktNames = map[uint32]string{
+	wkbPoint:              "POINT",
+	wkbLineString:         "LINESTRING",
+	wkbPolygon:            "POLYGON",
+	wkbMultiPoint:         "MULTIPOINT",
+	wkbMultiLineString:    "MULTILINESTRING",
+	wkbMultiPolygon:       "MULTIPOLYGON",
+	wkbGeometryCollection: "GEOMETRYCOLLECTION",
+}
+
+// String returns g in the extended well-known text format.
+func (g Geometry) String() string {
+	text, err := g.MarshalText()
+	if err != nil {
+		return fmt.Sprintf("invalid geltypes.Geometry: %v", err)
+	}
+
+	return string(text)
+}
+
+// MarshalText returns g in the extended well-known text format, for
+// example SRID=4326;POINT(1 2).
+func (g Geometry) MarshalText() ([]byte, error) {
+	var b []byte
+	if g.SRID != 0 {
+		b = fmt.Appendf(b, "SRID=%d;", g.SRID)
+	}
+
+	return appendWKT(b, g.Shape, g.Layout)
+}
+
+// UnmarshalText unmarshals well-known text or extended well-known text
+// into *g.
+func (g *Geometry) UnmarshalText(b []byte) error {
+	p := wktParser{s: string(b)}
+	geometry, err := p.parse()
+	if err != nil {
+		return fmt.Errorf("malformed geltypes.Geometry: %w", err)
+	}
+
+	*g = geometry
+	return nil
+}
+
+func appendWKT(b []byte, shape Shape, layout Layout) ([]byte, error) {
+	if shape == nil {
+		return nil, errNoShape
+	}
+
+	b = append(b, wktNames[shape.wkbType()]...)
+	switch layout {
+	case XYZ:
+		b = append(b, " Z "...)
+	case XYM:
+		b = append(b, " M "...)
+	case XYZM:
+		b = append(b, " ZM "...)
+	}
+
+	if isEmpty(shape) {
+		if layout == XY {
+			b = append(b, ' ')
+		}
+		return append(b, "EMPTY"...), nil
+	}
+
+	switch s := shape.(type) {
+	case Point:
+		b = appendWKTPoints(b, layout, s)
+	case LineString:
+		b = appendWKTPoints(b, layout, s...)
+	case Polygon:
+		b = appendWKTRings(b, layout, s)
+	case MultiPoint:
+		b = append(b, '(')
+		for i, p := range s {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendWKTPoints(b, layout, p)
+		}
+		b = append(b, ')')
+	case MultiLineString:
+		b = appendWKTRings(b, layout, s)
+	case MultiPolygon:
+		b = append(b, '(')
+		for i, p := range s {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendWKTRings(b, layout, p)
+		}
+		b = append(b, ')')
+	case GeometryCollection:
+		b = append(b, '(')
+		for i, child := range s {
+			if i > 0 {
+				b = append(b, ',')
+			}
+
+			var err error
+			if b, err = appendWKT(b, child, layout); err != nil {
+				return nil, err
+			}
+		}
+		b = append(b, ')')
+	}
+
+	return b, nil
+}
+
+func isEmpty(shape Shape) bool {
+	switch s := shape.(type) {
+	case Point:
+		return s.empty()
+	case LineString:
+		return len(s) == 0
+	case Polygon:
+		return len(s) == 0
+	case MultiPoint:
+		return len(s) == 0
+	case MultiLineString:
+		return len(s) == 0
+	case MultiPolygon:
+		return len(s) == 0
+	case GeometryCollection:
+		return len(s) == 0
+	default:
+		return false
+	}
+}
+
+func appendFloat(b []byte, f float64) []byte {
+	return strconv.AppendFloat(b, f, 'f', -1, 64)
+}
+
+func appendWKTPoints(b []byte, layout Layout, points ...Point) []byte {
+	b = append(b, '(')
+	for i, p := range points {
+		if i > 0 {
+			b = append(b, ',')
+		}
+
+		b = appendFloat(b, p.X)
+		b = append(b, ' ')
+		b = appendFloat(b, p.Y)
+		if layout.hasZ() {
+			b = append(b, ' ')
+			b = appendFloat(b, p.Z)
+		}
+		if layout.hasM() {
+			b = append(b, ' ')
+			b = appendFloat(b, p.M)
+		}
+	}
+
+	return append(b, ')')
+}
+
+func appendWKTRings(b []byte, layout Layout, rings []LineString) []byte {
+	b = append(b, '(')
+	for i, ring := range rings {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendWKTPoints(b, layout, ring...)
+	}
+
+	return append(b, ')')
+}
+
+type wktParser struct {
+	s         string
+	layout    Layout
+	layoutSet bool
+}
+
+func (p *wktParser) parse() (Geometry, error) {
+	var g Geometry
+	p.s = strings.TrimSpace(p.s)
+	if len(p.s) >= 5 && strings.EqualFold(p.s[:5], "SRID=") {
+		srid, rest, ok := strings.Cut(p.s[5:], ";")
+		if !ok {
+			return Geometry{}, fmt.Errorf("missing ; after SRID")
+		}
+
+		v, err := strconv.ParseInt(strings.TrimSpace(srid), 10, 32)
+		if err != nil {
+			return Geometry{}, fmt.Errorf("invalid SRID: %w", err)
+		}
+
+		g.SRID = int32(v)
+		p.s = rest
+	}
+
+	shape, err := p.geometry()
+	if err != nil {
+		return Geometry{}, err
+	}
+
+	p.skipSpace()
+	if p.s != "" {
+		return Geometry{}, fmt.Errorf("unexpected %q", p.s)
+	}
+
+	g.Layout = p.layout
+	g.Shape = shape
+	return g, nil
+}
+
+func (p *wktParser) skipSpace() {
+	p.s = strings.TrimLeft(p.s, " \t\r\n")
+}
+
+// word reads letters and returns them in upper case.
+func (p *wktParser) word() string {
+	p.skipSpace()
+	i := 0
+	for i < len(p.s) && (p.s[i]|0x20 >= 'a' && p.s[i]|0x20 <= 'z') {
+		i++
+	}
+
+	w := strings.ToUpper(p.s[:i])
+	p.s = p.s[i:]
+	return w
+}
+
+// peek returns the next character that is not a space.
+func (p *wktParser) peek() byte {
+	p.skipSpace()
+	if p.s == "" {
+		return 0
+	}
+
+	return p.s[0]
+}
+
+func (p *wktParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.s == "" {
+			return fmt.Errorf("expected %q, got end of text", c)
+		}
+		return fmt.Errorf("expected %q at %q", c, p.s)
+	}
+
+	p.s = p.s[1:]
+	return nil
+}
+
+func (p *wktParser) setLayout(layout Layout) error {
+	if p.layoutSet && p.layout != layout {
+		return fmt.Errorf("mixed %v and %v coordinates", p.layout, layout)
+	}
+
+	p.layout = layout
+	p.layoutSet = true
+	return nil
+}
+
+// typeName reads a geometry type and its optional dimension suffix, for
+// example POINT, POINT Z, or POINTM.
+func (p *wktParser) typeName() (uint32, error) {
+	word := p.word()
+	for typ, name := range wktNames {
+		if !strings.HasPrefix(word, name) {
+			continue
+		}
+
+		suffix := word[len(name):]
+		if suffix == "" {
+			rest := p.s
+			suffix = p.word()
+			if suffix == "EMPTY" {
+				p.s = rest
+				suffix = ""
+			}
+		}
+
+		switch suffix {
+		case "":
+			return typ, nil
+		case "Z":
+			return typ, p.setLayout(XYZ)
+		case "M":
+			return typ, p.setLayout(XYM)
+		case "ZM":
+			return typ, p.setLayout(XYZM)
+		}
+	}
+
+	if word == "" {
+		return 0, fmt.Errorf("expected a geometry type at %q", p.s)
+	}
+
+	return 0, fmt.Errorf("unknown geometry type %q", word)
+}
+
+func (p *wktParser) geometry() (Shape, error) {
+	typ, err := p.typeName()
+	if err != nil {
+		return nil, err
+	}
+
+	rest := p.s
+	if p.word() == "EMPTY" {
+		switch typ {
+		case wkbPoint:
+			return emptyPoint(), nil
+		case wkbLineString:
+			return LineString{}, nil
+		case wkbPolygon:
+			return Polygon{}, nil
+		case wkbMultiPoint:
+			return MultiPoint{}, nil
+		case wkbMultiLineString:
+			return MultiLineString{}, nil
+		case wkbMultiPolygon:
+			return MultiPolygon{}, nil
+		default:
+			return GeometryCollection{}, nil
+		}
+	}
+	p.s = rest
+
+	switch typ {
+	case wkbPoint:
+		points, err := p.points()
+		if err != nil {
+			return nil, err
+		}
+		if len(points) != 1 {
+			return nil, fmt.Errorf("point with %v coordinates",
+				len(points))
+		}
+		return points[0], nil
+	case wkbLineString:
+		points, err := p.points()
+		return LineString(points), err
+	case wkbPolygon:
+		rings, err := p.rings()
+		return Polygon(rings), err
+	case wkbMultiPoint:
+		points, err := p.multiPoint()
+		return MultiPoint(points), err
+	case wkbMultiLineString:
+		rings, err := p.rings()
+		return MultiLineString(rings), err
+	case wkbMultiPolygon:
+		var polygons MultiPolygon
+		err := p.list(func() error {
+			rings, err := p.rings()
+			polygons = append(polygons, rings)
+			return err
+		})
+		return polygons, err
+	default:
+		var shapes GeometryCollection
+		err := p.list(func() error {
+			shape, err := p.geometry()
+			shapes = append(shapes, shape)
+			return err
+		})
+		return shapes, err
+	}
+}
+
+// list reads a parenthesized comma separated list.
+func (p *wktParser) list(item func() error) error {
+	if err := p.expect('('); err != nil {
+		return err
+	}
+
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+
+		if p.peek() != ',' {
+			return p.expect(')')
+		}
+		p.s = p.s[1:]
+	}
+}
+
+func (p *wktParser) points() ([]Point, error) {
+	var points []Point
+	err := p.list(func() error {
+		point, err := p.point()
+		points = append(points, point)
+		return err
+	})
+
+	return points, err
+}
+
+func (p *wktParser) rings() ([]LineString, error) {
+	var rings []LineString
+	err := p.list(func() error {
+		points, err := p.points()
+		rings = append(rings, points)
+		return err
+	})
+
+	return rings, err
+}
+
+// multiPoint reads points with or without parentheses around each point.
+func (p *wktParser) multiPoint() ([]Point, error) {
+	var points []Point
+	err := p.list(func() error {
+		var point Point
+		var err error
+		if p.peek() == '(' {
+			var inner []Point
+			inner, err = p.points()
+			if err == nil && len(inner) != 1 {
+				err = fmt.Errorf("point with %v coordinates", len(inner))
+			}
+			if err == nil {
+				point = inner[0]
+			}
+		} else {
+			point, err = p.point()
+		}
+
+		points = append(points, point)
+		return err
+	})
+
+	return points, err
+}
+
+// point reads the space separated values of a coordinate.
+func (p *wktParser) point() (Point, error) {
+	var values []float64
+	for {
+		p.skipSpace()
+		i := strings.IndexAny(p.s, " \t\r\n,)")
+		if i < 0 {
+			i = len(p.s)
+		}
+		if i == 0 {
+			break
+		}
+
+		v, err := strconv.ParseFloat(p.s[:i], 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("invalid coordinate %q", p.s[:i])
+		}
+
+		values = append(values, v)
+		p.s = p.s[i:]
+	}
+
+	var layout Layout
+	switch len(values) {
+	case 2:
+		layout = XY
+	case 3:
+		layout = XYZ
+		if p.layout == XYM {
+			layout = XYM
+		}
+	case 4:
+		layout = XYZM
+	default:
+		return Point{}, fmt.Errorf("coordinate with %v values", len(values))
+	}
+
+	if err := p.setLayout(layout); err != nil {
+		return Point{}, err
+	}
+
+	point := Point{X: values[0], Y: values[1]}
+	switch layout {
+	case XYZ:
+		point.Z = values[2]
+	case XYM:
+		point.M = values[2]
+	case XYZM:
+		point.Z = values[2]
+		point.M = values[3]
+	}
+
+	return point, nil
+}
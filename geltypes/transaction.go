@@ -33,6 +33,17 @@ type Executor interface {
 	QuerySingleJSON(context.Context, string, any, ...any) error
 }
 
+// SingleSQLQuerier is implemented by *gel.Client and Tx. It is not part of
+// [Executor] so that existing implementations of Executor don't need to
+// change. Use a type assertion to check for it:
+//
+//	if q, ok := executor.(geltypes.SingleSQLQuerier); ok {
+//		err = q.QuerySingleSQL(ctx, "SELECT 1 AS one", &row)
+//	}
+type SingleSQLQuerier interface {
+	QuerySingleSQL(context.Context, string, any, ...any) error
+}
+
 // Tx is a transaction. Use [github.com/geldata/gel-go.Client.Tx] to get a
 // transaction.
 type Tx interface {
@@ -0,0 +1,232 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geltypes
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Vector is an ext::pgvector::vector.
+type Vector []float32
+
+// HalfVector is an ext::pgvector::halfvec. Values are stored with half
+// precision on the server and are rounded when they are sent.
+type HalfVector []float32
+
+// SparseVector is an ext::pgvector::sparsevec. Only elements that are not
+// zero are stored.
+type SparseVector struct {
+	// Dim is the number of dimensions.
+	Dim int32 `json:"dim"`
+
+	// Indices are the zero based positions of the elements in Values in
+	// ascending order.
+	Indices []int32 `json:"indices"`
+
+	Values []float32 `json:"values"`
+}
+
+// NewSparseVector returns a SparseVector with the elements of v that are
+// not zero.
+func NewSparseVector(v []float32) SparseVector {
+	s := SparseVector{Dim: int32(len(v))}
+	for i, val := range v {
+		if val != 0 {
+			s.Indices = append(s.Indices, int32(i))
+			s.Values = append(s.Values, val)
+		}
+	}
+
+	return s
+}
+
+// Slice returns s with the zero elements filled in.
+func (s SparseVector) Slice() []float32 {
+	v := make([]float32, s.Dim)
+	for i, idx := range s.Indices {
+		v[idx] = s.Values[i]
+	}
+
+	return v
+}
+
+func (s SparseVector) String() string {
+	return fmt.Sprintf("SparseVector{Dim: %v, Indices: %v, Values: %v}",
+		s.Dim, s.Indices, s.Values)
+}
+
+// NewOptionalVector is a convenience function for creating an
+// OptionalVector with its value set to v.
+func NewOptionalVector(v Vector) OptionalVector {
+	o := OptionalVector{}
+	o.Set(v)
+	return o
+}
+
+// OptionalVector is an optional Vector. Optional types must be used for
+// out parameters when a shape field is not required.
+type OptionalVector struct {
+	val   Vector
+	isSet bool
+}
+
+// Get returns the value and a boolean indicating if the value is present.
+func (o OptionalVector) Get() (Vector, bool) { return o.val, o.isSet }
+
+// Set sets the value.
+func (o *OptionalVector) Set(val Vector) {
+	o.val = val
+	o.isSet = true
+}
+
+// Unset marks the value as missing.
+func (o *OptionalVector) Unset() {
+	o.val = nil
+	o.isSet = false
+}
+
+// MarshalJSON returns o marshaled as json.
+func (o OptionalVector) MarshalJSON() ([]byte, error) {
+	if o.isSet {
+		return json.Marshal(o.val)
+	}
+	return json.Marshal(nil)
+}
+
+// UnmarshalJSON unmarshals bytes into *o.
+func (o *OptionalVector) UnmarshalJSON(bytes []byte) error {
+	if bytes[0] == 0x6e { // null
+		o.Unset()
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, &o.val); err != nil {
+		return err
+	}
+	o.isSet = true
+
+	return nil
+}
+
+// NewOptionalHalfVector is a convenience function for creating an
+// OptionalHalfVector with its value set to v.
+func NewOptionalHalfVector(v HalfVector) OptionalHalfVector {
+	o := OptionalHalfVector{}
+	o.Set(v)
+	return o
+}
+
+// OptionalHalfVector is an optional HalfVector. Optional types must be used
+// for out parameters when a shape field is not required.
+type OptionalHalfVector struct {
+	val   HalfVector
+	isSet bool
+}
+
+// Get returns the value and a boolean indicating if the value is present.
+func (o OptionalHalfVector) Get() (HalfVector, bool) { return o.val, o.isSet }
+
+// Set sets the value.
+func (o *OptionalHalfVector) Set(val HalfVector) {
+	o.val = val
+	o.isSet = true
+}
+
+// Unset marks the value as missing.
+func (o *OptionalHalfVector) Unset() {
+	o.val = nil
+	o.isSet = false
+}
+
+// MarshalJSON returns o marshaled as json.
+func (o OptionalHalfVector) MarshalJSON() ([]byte, error) {
+	if o.isSet {
+		return json.Marshal(o.val)
+	}
+	return json.Marshal(nil)
+}
+
+// UnmarshalJSON unmarshals bytes into *o.
+func (o *OptionalHalfVector) UnmarshalJSON(bytes []byte) error {
+	if bytes[0] == 0x6e { // null
+		o.Unset()
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, &o.val); err != nil {
+		return err
+	}
+	o.isSet = true
+
+	return nil
+}
+
+// NewOptionalSparseVector is a convenience function for creating an
+// OptionalSparseVector with its value set to v.
+func NewOptionalSparseVector(v SparseVector) OptionalSparseVector {
+	o := OptionalSparseVector{}
+	o.Set(v)
+	return o
+}
+
+// OptionalSparseVector is an optional SparseVector. Optional types must be
+// used for out parameters when a shape field is not required.
+type OptionalSparseVector struct {
+	val   SparseVector
+	isSet bool
+}
+
+// Get returns the value and a boolean indicating if the value is present.
+func (o OptionalSparseVector) Get() (SparseVector, bool) {
+	return o.val, o.isSet
+}
+
+// Set sets the value.
+func (o *OptionalSparseVector) Set(val SparseVector) {
+	o.val = val
+	o.isSet = true
+}
+
+// Unset marks the value as missing.
+func (o *OptionalSparseVector) Unset() {
+	o.val = SparseVector{}
+	o.isSet = false
+}
+
+// MarshalJSON returns o marshaled as json.
+func (o OptionalSparseVector) MarshalJSON() ([]byte, error) {
+	if o.isSet {
+		return json.Marshal(o.val)
+	}
+	return json.Marshal(nil)
+}
+
+// UnmarshalJSON unmarshals bytes into *o.
+func (o *OptionalSparseVector) UnmarshalJSON(bytes []byte) error {
+	if bytes[0] == 0x6e { // null
+		o.Unset()
+		return nil
+	}
+
+	if err := json.Unmarshal(bytes, &o.val); err != nil {
+		return err
+	}
+	o.isSet = true
+
+	return nil
+}
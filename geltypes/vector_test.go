@@ -0,0 +1,63 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geltypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseVector(t *testing.T) {
+	v := NewSparseVector([]float32{0, 1.5, 0, 0, -2})
+	assert.Equal(t, SparseVector{
+		Dim:     5,
+		Indices: []int32{1, 4},
+		Values:  []float32{1.5, -2},
+	}, v)
+	assert.Equal(t, []float32{0, 1.5, 0, 0, -2}, v.Slice())
+}
+
+func TestOptionalVectorJSON(t *testing.T) {
+	b, err := json.Marshal(OptionalVector{})
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(b))
+
+	b, err = json.Marshal(NewOptionalVector(Vector{1, 2.5}))
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2.5]", string(b))
+
+	var v OptionalVector
+	require.NoError(t, json.Unmarshal(b, &v))
+	assert.Equal(t, NewOptionalVector(Vector{1, 2.5}), v)
+
+	require.NoError(t, json.Unmarshal([]byte("null"), &v))
+	assert.Equal(t, OptionalVector{}, v)
+}
+
+func TestOptionalSparseVectorJSON(t *testing.T) {
+	s := NewOptionalSparseVector(NewSparseVector([]float32{0, 3}))
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"dim":2,"indices":[1],"values":[3]}`, string(b))
+
+	var v OptionalSparseVector
+	require.NoError(t, json.Unmarshal(b, &v))
+	assert.Equal(t, s, v)
+}
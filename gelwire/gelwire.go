@@ -0,0 +1,84 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gelwire records and replays the protocol traffic between a client
+// and a Gel server.
+//
+// Set [github.com/geldata/gel-go/gelcfg.Options.WireTrace] to record every
+// frame a client's connections send and receive. The trace can be attached
+// to a bug report and served back to a client with [Replay] without access
+// to the original database.
+//
+//	f, err := os.Create("trace.jsonl")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer f.Close()
+//
+//	client, err := gel.CreateClient(gelcfg.Options{WireTrace: f})
+//
+// Later the trace can be replayed.
+//
+//	frames, err := gelwire.ReadTraceFile("trace.jsonl")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	server, err := gelwire.Replay(frames)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer server.Close()
+//
+//	client, err := gel.CreateClient(server.ClientOptions())
+package gelwire
+
+import (
+	"io"
+	"os"
+
+	"github.com/geldata/gel-go/internal/wiretrace"
+)
+
+// Sender is the side of a connection that sent a frame.
+type Sender = wiretrace.Sender
+
+const (
+	// Client frames are sent by the client.
+	Client = wiretrace.Client
+	// Server frames are sent by the server.
+	Server = wiretrace.Server
+)
+
+// Frame is a single protocol message in a trace.
+type Frame = wiretrace.Frame
+
+// ReadTrace decodes a trace written by a client with
+// [github.com/geldata/gel-go/gelcfg.Options.WireTrace] set.
+func ReadTrace(r io.Reader) ([]Frame, error) {
+	return wiretrace.Read(r)
+}
+
+// ReadTraceFile decodes the trace in the file at path.
+func ReadTraceFile(path string) ([]Frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+
+	return ReadTrace(f)
+}
@@ -0,0 +1,186 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelwire_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/gelwire"
+	gelint "github.com/geldata/gel-go/internal/client"
+	"github.com/geldata/gel-go/internal/codecs"
+	fs "github.com/geldata/gel-go/internal/fakeserver"
+)
+
+type User struct {
+	ID    geltypes.UUID        `gel:"id"`
+	Name  string               `gel:"name"`
+	Email geltypes.OptionalStr `gel:"email"`
+}
+
+const query = "SELECT User { name, email }"
+
+// record runs query against a fake server and returns the recorded trace.
+func record(t *testing.T, run func(*gel.Client)) []gelwire.Frame {
+	server, err := fs.Start(fs.Options{Password: "secret"})
+	require.NoError(t, err)
+	defer server.Close() // nolint:errcheck
+
+	b := fs.NewBuilder()
+	uuid := b.Scalar(codecs.UUIDID, "std::uuid")
+	str := b.Scalar(codecs.StrID, "std::str")
+	b.Object("default::User",
+		fs.Element{Name: "id", Type: uuid, Implicit: true},
+		fs.Element{Name: "name", Type: str},
+		fs.Element{Name: "email", Type: str, Cardinality: gelint.AtMostOne},
+	)
+
+	server.Handle(query, &fs.Response{
+		Output: b.Build(),
+		Data: [][]byte{
+			fs.Object(fs.UUID(geltypes.UUID{1}), fs.Str("alice"), nil),
+		},
+	})
+
+	var trace bytes.Buffer
+	opts := server.ClientOptions()
+	opts.WireTrace = &trace
+	client, err := gel.CreateClient(opts)
+	require.NoError(t, err)
+	run(client)
+	require.NoError(t, client.Close())
+
+	frames, err := gelwire.ReadTrace(&trace)
+	require.NoError(t, err)
+	return frames
+}
+
+// replay starts a replay server and a client connected to it. The caller
+// closes both.
+func replay(t *testing.T, frames []gelwire.Frame) (
+	*gelwire.ReplayServer,
+	*gel.Client,
+) {
+	server, err := gelwire.Replay(frames)
+	require.NoError(t, err)
+	client, err := gel.CreateClient(server.ClientOptions())
+	require.NoError(t, err)
+	return server, client
+}
+
+func TestRecord(t *testing.T) {
+	ctx := context.Background()
+	frames := record(t, func(client *gel.Client) {
+		var users []User
+		require.NoError(t, client.Query(ctx, query, &users))
+	})
+
+	var messages []string
+	for _, f := range frames {
+		assert.Equal(t, 1, f.Conn)
+		messages = append(messages, string(f.Sender)+" "+f.Type)
+
+		switch f.Type {
+		case "AuthenticationSASLInitialResponse",
+			"AuthenticationSASLResponse":
+			assert.Empty(t, f.Payload, "SCRAM messages are redacted")
+		case "Authentication":
+			assert.Len(t, f.Payload, 4, "SCRAM messages are redacted")
+		case "Parse", "Execute":
+			assert.Contains(t, string(f.Payload), query)
+		}
+	}
+
+	assert.Subset(t, messages, []string{
+		"client ClientHandshake",
+		"server Authentication",
+		"client AuthenticationSASLInitialResponse",
+		"server ReadyForCommand",
+		"client Parse",
+		"client Sync",
+		"server CommandDataDescription",
+		"client Execute",
+		"server Data",
+		"server CommandComplete",
+		"client Terminate",
+	})
+
+	for i := 1; i < len(frames); i++ {
+		assert.GreaterOrEqual(t, frames[i].Elapsed, frames[i-1].Elapsed)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	frames := record(t, func(client *gel.Client) {
+		var users []User
+		require.NoError(t, client.Query(ctx, query, &users))
+	})
+
+	server, client := replay(t, frames)
+
+	var users []User
+	require.NoError(t, client.Query(ctx, query, &users))
+	assert.Equal(t, []User{{ID: geltypes.UUID{1}, Name: "alice"}}, users)
+	require.NoError(t, client.Close())
+	require.NoError(t, server.Close())
+	assert.NoError(t, server.Err())
+}
+
+func TestReplayMismatch(t *testing.T) {
+	ctx := context.Background()
+	frames := record(t, func(client *gel.Client) {
+		require.NoError(t, client.EnsureConnected(ctx))
+	})
+
+	server, client := replay(t, frames)
+
+	var users []User
+	err := client.Query(ctx, query, &users)
+	require.Error(t, err)
+	require.NoError(t, client.Close())
+
+	require.NoError(t, server.Close())
+	assert.ErrorContains(t, server.Err(), "expected Terminate (0x58) "+
+		"from the client, got 0x50")
+}
+
+func TestReadTraceFile(t *testing.T) {
+	_, err := gelwire.ReadTraceFile(t.TempDir() + "/missing.jsonl")
+	assert.Error(t, err)
+
+	frames, err := gelwire.ReadTrace(strings.NewReader(
+		`{"conn":1,"sender":"client","type":"Sync","code":83,` +
+			`"elapsed":5,"payload":null}`,
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []gelwire.Frame{{
+		Conn:    1,
+		Sender:  gelwire.Client,
+		Type:    "Sync",
+		Code:    byte(gelint.Sync),
+		Elapsed: 5,
+	}}, frames)
+	assert.Equal(t, []byte{0x53, 0, 0, 0, 4}, frames[0].Bytes())
+}
@@ -0,0 +1,244 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gelwire
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/geldata/gel-go/gelcfg"
+	gel "github.com/geldata/gel-go/internal/client"
+	"github.com/geldata/gel-go/internal/fakeserver"
+)
+
+// ReplayServer serves recorded frames back to clients. Each accepted
+// connection replays the next recorded connection. Frames sent by the
+// server are written as they were recorded and frames sent by the client
+// are read and checked against the message type that was recorded.
+//
+// Authentication is skipped because it can not be replayed, so clients
+// don't need the original credentials.
+type ReplayServer struct {
+	listener net.Listener
+	caPEM    []byte
+
+	mu      sync.Mutex
+	scripts [][]Frame
+	conns   map[net.Conn]struct{}
+	errs    []error
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// Replay returns a server that listens on a random local port and replays
+// frames.
+func Replay(frames []Frame) (*ReplayServer, error) {
+	cert, caPEM, err := fakeserver.NewCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"edgedb-binary"},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ReplayServer{
+		listener: listener,
+		caPEM:    caPEM,
+		scripts:  split(frames),
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// split groups frames by connection in the order the connections were
+// opened.
+func split(frames []Frame) [][]Frame {
+	var scripts [][]Frame
+	index := make(map[int]int)
+	for _, f := range frames {
+		i, ok := index[f.Conn]
+		if !ok {
+			i = len(scripts)
+			index[f.Conn] = i
+			scripts = append(scripts, nil)
+		}
+
+		scripts[i] = append(scripts[i], f)
+	}
+
+	return scripts
+}
+
+// ClientOptions returns options for connecting to the server.
+func (s *ReplayServer) ClientOptions() gelcfg.Options {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return gelcfg.Options{
+		Host: addr.IP.String(),
+		Port: addr.Port,
+		TLSOptions: gelcfg.TLSOptions{
+			CA:           s.caPEM,
+			SecurityMode: gelcfg.TLSModeStrict,
+		},
+	}
+}
+
+// Err returns the differences between the recorded traffic and the traffic
+// sent by clients.
+func (s *ReplayServer) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return errors.Join(s.errs...)
+}
+
+// Close stops the server and closes all connections.
+func (s *ReplayServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *ReplayServer) serve() {
+	defer s.wg.Done()
+
+	for id := 1; ; id++ {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = c.Close()
+			return
+		}
+
+		var script []Frame
+		if len(s.scripts) > 0 {
+			script = s.scripts[0]
+			s.scripts = s.scripts[1:]
+		}
+		s.conns[c] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			err := s.replay(c, id, script)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.conns, c)
+			_ = c.Close()
+			if err != nil && !s.closed {
+				s.errs = append(s.errs, err)
+			}
+		}()
+	}
+}
+
+func (s *ReplayServer) replay(c net.Conn, id int, script []Frame) error {
+	if script == nil {
+		return fmt.Errorf("gelwire: connection %v was not recorded", id)
+	}
+
+	for i, f := range script {
+		if skip(&f) {
+			continue
+		}
+
+		if f.Sender == Server {
+			if _, err := c.Write(f.Bytes()); err != nil {
+				return fmt.Errorf("gelwire: connection %v frame %v: %w",
+					id, i, err)
+			}
+			continue
+		}
+
+		code, err := readFrame(c)
+		if err != nil {
+			return fmt.Errorf(
+				"gelwire: connection %v frame %v: expected %v: %w",
+				id, i, f.Type, err)
+		}
+
+		if code != f.Code {
+			return fmt.Errorf("gelwire: connection %v frame %v: "+
+				"expected %v (0x%02x) from the client, got 0x%02x",
+				id, i, f.Type, f.Code, code)
+		}
+	}
+
+	// The trace is over, wait for the client to hang up.
+	_, _ = io.Copy(io.Discard, c)
+	return nil
+}
+
+// skip reports whether f is part of the authentication exchange. The client
+// is told that authentication succeeded right away.
+func skip(f *Frame) bool {
+	switch gel.Message(f.Code) {
+	case gel.AuthenticationSASLInitialResponse,
+		gel.AuthenticationSASLResponse:
+		return f.Sender == Client
+	case gel.Authentication:
+		return f.Sender == Server &&
+			(len(f.Payload) < 4 || binary.BigEndian.Uint32(f.Payload) != 0)
+	default:
+		return false
+	}
+}
+
+// readFrame reads a frame from the client and returns its message type.
+func readFrame(r io.Reader) (byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+
+	n := int64(binary.BigEndian.Uint32(header[1:])) - 4
+	if n < 0 {
+		return 0, fmt.Errorf("invalid message length %v", n)
+	}
+
+	if _, err := io.CopyN(io.Discard, r, n); err != nil {
+		return 0, err
+	}
+
+	return header[0], nil
+}
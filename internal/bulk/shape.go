@@ -0,0 +1,295 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bulk derives insert shapes from go structs for bulk inserts.
+//
+// Rows are sent as a single array<tuple<...>> argument. Array elements can
+// not be missing, so optional fields are sent as arrays with zero or one
+// elements and unpacked in the insert shape.
+package bulk
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/geldata/gel-go/internal/introspect"
+)
+
+// Field is a struct field that is inserted as a property.
+type Field struct {
+	// Name is the property name.
+	Name string
+
+	// Type is the Gel type the field is sent as.
+	Type string
+
+	index    []int
+	optional bool
+	array    bool
+}
+
+// Shape describes how rows of a struct type are inserted.
+type Shape struct {
+	Fields []Field
+}
+
+// NewShape returns the shape for rows of type t. Fields are named by their
+// gel tag or by their field name. Unexported fields and the id field are
+// skipped.
+func NewShape(t reflect.Type) (*Shape, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected rows to be structs got %v", t)
+	}
+
+	s := &Shape{}
+	if err := s.addFields(t, nil); err != nil {
+		return nil, err
+	}
+
+	if len(s.Fields) == 0 {
+		return nil, fmt.Errorf("%v has no fields to insert", t)
+	}
+
+	return s, nil
+}
+
+func (s *Shape) addFields(t reflect.Type, index []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		tag, ok := field.Tag.Lookup("gel")
+		if !ok {
+			tag = field.Tag.Get("edgedb")
+		}
+
+		if tag == "$inline" {
+			if err := s.addFields(field.Type, fieldIndex); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = field.Name
+		}
+
+		if name == "id" {
+			continue
+		}
+
+		f, err := newField(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %v.%v: %w", t, field.Name, err)
+		}
+
+		f.Name = name
+		f.index = fieldIndex
+		s.Fields = append(s.Fields, f)
+	}
+
+	return nil
+}
+
+func newField(t reflect.Type) (Field, error) {
+	if name, ok := introspect.ScalarTypeName(t); ok {
+		return Field{Type: name}, nil
+	}
+
+	if inner, ok := introspect.OptionalType(t); ok {
+		if name, ok := introspect.ScalarTypeName(inner); ok {
+			return Field{Type: name, optional: true}, nil
+		}
+	}
+
+	if t.Kind() == reflect.Slice {
+		if name, ok := introspect.ScalarTypeName(t.Elem()); ok {
+			return Field{Type: "array<" + name + ">", array: true}, nil
+		}
+	}
+
+	return Field{}, fmt.Errorf("unsupported type %v", t)
+}
+
+// Query returns the insert statement for typeName. The query takes the rows
+// as its only argument. typeName may be qualified with a module name, each
+// part of it is quoted.
+func (s *Shape) Query(typeName string) (string, error) {
+	name, err := quoteTypeName(typeName)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	b.WriteString("WITH rows := <array<tuple<")
+	for i, field := range s.Fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		if field.optional {
+			b.WriteString("array<" + field.Type + ">")
+		} else {
+			b.WriteString(field.Type)
+		}
+	}
+	b.WriteString(">>>$0\n")
+
+	fmt.Fprintf(&b, "FOR row IN array_unpack(rows) UNION (\n")
+	fmt.Fprintf(&b, "\tINSERT %v {\n", name)
+	for i, field := range s.Fields {
+		if field.optional {
+			fmt.Fprintf(
+				&b,
+				"\t\t%v := (SELECT array_unpack(row.%v) LIMIT 1),\n",
+				quoteIdent(field.Name),
+				i,
+			)
+		} else {
+			fmt.Fprintf(&b, "\t\t%v := row.%v,\n", quoteIdent(field.Name), i)
+		}
+	}
+	b.WriteString("\t}\n)")
+
+	return b.String(), nil
+}
+
+// quoteTypeName quotes each part of a possibly module qualified type name.
+func quoteTypeName(typeName string) (string, error) {
+	parts := strings.Split(typeName, "::")
+	for i, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("invalid type name %q", typeName)
+		}
+
+		parts[i] = quoteIdent(part)
+	}
+
+	return strings.Join(parts, "::"), nil
+}
+
+// quoteIdent quotes name so that it can be used as an identifier in EdgeQL.
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Row returns the tuple for row and an estimate of its encoded size in bytes.
+// row must be an addressable value of the shape's type.
+func (s *Shape) Row(row reflect.Value) ([]any, int) {
+	tuple := make([]any, len(s.Fields))
+	size := 4
+
+	for i, field := range s.Fields {
+		val := row.FieldByIndex(field.index)
+
+		if field.optional {
+			out := val.Addr().MethodByName("Get").Call(nil)
+			if out[1].Bool() {
+				tuple[i] = []any{out[0].Interface()}
+				size += 20 + valueSize(out[0])
+			} else {
+				tuple[i] = []any{}
+				size += 20
+			}
+			continue
+		}
+
+		if field.array && val.IsNil() {
+			// A nil slice would be encoded as a missing value.
+			val = reflect.MakeSlice(val.Type(), 0, 0)
+		}
+
+		tuple[i] = val.Interface()
+		if field.array {
+			size += 20
+			for j := 0; j < val.Len(); j++ {
+				size += 4 + valueSize(val.Index(j))
+			}
+		} else {
+			size += 8 + valueSize(val)
+		}
+	}
+
+	return tuple, size
+}
+
+// valueSize estimates the encoded size of a scalar value.
+func valueSize(val reflect.Value) int {
+	switch val.Kind() {
+	case reflect.String, reflect.Slice:
+		return val.Len()
+	case reflect.Pointer:
+		if val.IsNil() {
+			return 0
+		}
+
+		if n, ok := val.Interface().(*big.Int); ok {
+			return 8 + n.BitLen()/8
+		}
+
+		return 16
+	default:
+		return 16
+	}
+}
+
+// Chunk is a range of rows that are inserted by a single query.
+type Chunk struct {
+	// Offset is the index of the chunk's first row.
+	Offset int
+
+	// Rows are the chunk's tuples.
+	Rows []any
+}
+
+// NextChunk returns the chunk that starts at offset. It has at most maxRows
+// rows and is approximately at most maxBytes bytes, but it always has at
+// least one row.
+func (s *Shape) NextChunk(
+	rows reflect.Value,
+	offset, maxRows, maxBytes int,
+) (Chunk, error) {
+	chunk := Chunk{Offset: offset}
+	size := 0
+
+	for i := offset; i < rows.Len() && len(chunk.Rows) < maxRows; i++ {
+		row := rows.Index(i)
+		if row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				return Chunk{}, fmt.Errorf("row %v is nil", i)
+			}
+			row = row.Elem()
+		}
+
+		tuple, n := s.Row(row)
+		if len(chunk.Rows) > 0 && size+n > maxBytes {
+			break
+		}
+
+		chunk.Rows = append(chunk.Rows, tuple)
+		size += n
+	}
+
+	return chunk, nil
+}
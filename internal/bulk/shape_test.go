@@ -0,0 +1,168 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulk
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Base struct {
+	ID   types.UUID `gel:"id"`
+	Name string     `gel:"name"`
+}
+
+type Row struct {
+	Base  `gel:"$inline"`
+	Count int64              `gel:"count"`
+	Nick  types.OptionalStr  `gel:"nick"`
+	Tags  []string           `gel:"tags"`
+	Span  types.DateDuration `gel:"span"`
+	Score float64
+}
+
+func TestShapeQuery(t *testing.T) {
+	shape, err := NewShape(reflect.TypeOf(Row{}))
+	require.NoError(t, err)
+
+	expected := "WITH rows := <array<tuple<std::str, std::int64, " +
+		"array<std::str>, array<std::str>, cal::date_duration, " +
+		"std::float64>>>$0\n" +
+		"FOR row IN array_unpack(rows) UNION (\n" +
+		"\tINSERT `default`::`Row` {\n" +
+		"\t\t`name` := row.0,\n" +
+		"\t\t`count` := row.1,\n" +
+		"\t\t`nick` := (SELECT array_unpack(row.2) LIMIT 1),\n" +
+		"\t\t`tags` := row.3,\n" +
+		"\t\t`span` := row.4,\n" +
+		"\t\t`Score` := row.5,\n" +
+		"\t}\n)"
+	query, err := shape.Query("default::Row")
+	require.NoError(t, err)
+	assert.Equal(t, expected, query)
+}
+
+func TestShapeQueryTypeName(t *testing.T) {
+	shape, err := NewShape(reflect.TypeOf(struct {
+		Name string "gel:\"na`me\""
+	}{}))
+	require.NoError(t, err)
+
+	query, err := shape.Query("Row")
+	require.NoError(t, err)
+	assert.Contains(t, query, "\tINSERT `Row` {\n")
+	assert.Contains(t, query, "\t\t`na``me` := row.0,\n")
+
+	query, err = shape.Query("default::Row {}; DELETE User")
+	require.NoError(t, err)
+	assert.Contains(
+		t,
+		query,
+		"\tINSERT `default`::`Row {}; DELETE User` {\n",
+	)
+
+	for _, name := range []string{"", "::Row", "default::", "a::::b"} {
+		_, err = shape.Query(name)
+		assert.EqualError(t, err, fmt.Sprintf("invalid type name %q", name))
+	}
+}
+
+func TestShapeRow(t *testing.T) {
+	shape, err := NewShape(reflect.TypeOf(Row{}))
+	require.NoError(t, err)
+
+	rows := []Row{
+		{Base: Base{Name: "a"}, Count: 1, Nick: types.NewOptionalStr("x")},
+		{Base: Base{Name: "b"}, Tags: []string{"t"}},
+	}
+	val := reflect.ValueOf(rows)
+
+	tuple, _ := shape.Row(val.Index(0))
+	assert.Equal(t, []any{
+		"a",
+		int64(1),
+		[]any{"x"},
+		[]string{},
+		types.DateDuration{},
+		float64(0),
+	}, tuple)
+
+	tuple, _ = shape.Row(val.Index(1))
+	assert.Equal(t, []any{
+		"b",
+		int64(0),
+		[]any{},
+		[]string{"t"},
+		types.DateDuration{},
+		float64(0),
+	}, tuple)
+}
+
+func TestShapeUnsupportedField(t *testing.T) {
+	type Link struct {
+		Friend Row `gel:"friend"`
+	}
+
+	_, err := NewShape(reflect.TypeOf(Link{}))
+	assert.EqualError(t, err,
+		"field bulk.Link.Friend: unsupported type bulk.Row")
+
+	_, err = NewShape(reflect.TypeOf(""))
+	assert.EqualError(t, err, "expected rows to be structs got string")
+}
+
+func TestNextChunk(t *testing.T) {
+	type Small struct {
+		Name string `gel:"name"`
+	}
+
+	shape, err := NewShape(reflect.TypeOf(Small{}))
+	require.NoError(t, err)
+
+	rows := reflect.ValueOf([]*Small{
+		{"a"},
+		{"b"},
+		{"c"},
+		{"dddddddddddddddddddddddddddddddddddddddd"},
+		{"e"},
+	})
+
+	var chunks []Chunk
+	for offset := 0; offset < rows.Len(); {
+		chunk, err := shape.NextChunk(rows, offset, 2, 30)
+		require.NoError(t, err)
+		chunks = append(chunks, chunk)
+		offset += len(chunk.Rows)
+	}
+
+	assert.Equal(t, []Chunk{
+		{Offset: 0, Rows: []any{[]any{"a"}, []any{"b"}}},
+		{Offset: 2, Rows: []any{[]any{"c"}}},
+		{Offset: 3, Rows: []any{
+			[]any{"dddddddddddddddddddddddddddddddddddddddd"},
+		}},
+		{Offset: 4, Rows: []any{[]any{"e"}}},
+	}, chunks)
+
+	_, err = shape.NextChunk(reflect.ValueOf([]*Small{nil}), 0, 2, 30)
+	assert.EqualError(t, err, "row 0 is nil")
+}
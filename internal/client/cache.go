@@ -31,6 +31,12 @@ type id cache (conn/pool specific) mapping:
 capabilities cache (conn/pool specific) mapping:
 (Query, Expected Cardinality, IO Format, outType) -> capabilities
 
+schema cache (pool specific) mapping:
+Branch -> Introspected Schema
+
+All caches except the descriptor cache are invalidated when a query with the
+DDL capability is executed.
+
 Optimistic execute flow:
 1. check type id cache for (eql, expCard, format).
 	- if cache miss then do prepare/execute flow instead of optimistic
@@ -86,9 +92,16 @@ func makeKey(q *query) queryKey {
 func (c *protocolConnection) getCachedTypeIDs(q *query) (*idPair, bool) {
 	if val, ok := c.typeIDCache.Get(makeKey(q)); ok {
 		x := val.(idPair)
+		c.debug(
+			"type id cache hit",
+			"query", q.cmd,
+			"in", x.in,
+			"out", x.out,
+		)
 		return &x, true
 	}
 
+	c.debug("type id cache miss", "query", q.cmd)
 	return nil, false
 }
 
@@ -105,6 +118,7 @@ func (c *protocolConnection) cacheCapabilities1pX(
 		c.inCodecCache.Invalidate()
 		c.outCodecCache.Invalidate()
 		c.capabilitiesCache.Invalidate()
+		c.schemaCache.invalidate()
 	}
 	c.capabilitiesCache.Put(makeKey(q), capabilities)
 }
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net/url"
 	"os"
 	"path"
@@ -40,6 +41,7 @@ import (
 	"github.com/geldata/gel-go/geltypes"
 	"github.com/geldata/gel-go/internal/gelerr"
 	"github.com/geldata/gel-go/internal/snc"
+	"github.com/geldata/gel-go/internal/wiretrace"
 	"github.com/sigurn/crc16"
 )
 
@@ -72,6 +74,8 @@ type connConfig struct {
 	tlsServerName      string
 	ServerSettings     *snc.ServerSettings
 	secretKey          string
+	wireTrace          *wiretrace.Recorder
+	logger             *slog.Logger
 }
 
 func (c *connConfig) tlsConfig() (*tls.Config, error) {
@@ -1167,6 +1171,11 @@ func (r *configResolver) config(opts *gelcfg.Options) (*connConfig, error) {
 		password = r.password.val.(string)
 	}
 
+	var wireTrace *wiretrace.Recorder
+	if opts.WireTrace != nil {
+		wireTrace = wiretrace.NewRecorder(opts.WireTrace, traceMessageName)
+	}
+
 	return &connConfig{
 		addr:               dialArgs{"tcp", fmt.Sprintf("%v:%v", host, port)},
 		user:               user,
@@ -1180,6 +1189,8 @@ func (r *configResolver) config(opts *gelcfg.Options) (*connConfig, error) {
 		tlsSecurity:        tlsSecurity,
 		tlsServerName:      tlsServerName,
 		secretKey:          secretKey,
+		wireTrace:          wireTrace,
+		logger:             newLogger(opts),
 	}, nil
 }
 
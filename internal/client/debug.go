@@ -0,0 +1,89 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/internal/wiretrace"
+)
+
+// debugEnvVar enables debug logging to stderr when it contains protocol,
+// for example GEL_GO_DEBUG=protocol.
+const debugEnvVar = "GEL_GO_DEBUG"
+
+// newLogger returns the logger for debug messages or nil if debug logging
+// is disabled.
+func newLogger(opts *gelcfg.Options) *slog.Logger {
+	if opts.Logger != nil {
+		return opts.Logger
+	}
+
+	for _, v := range strings.Split(os.Getenv(debugEnvVar), ",") {
+		if strings.TrimSpace(v) == "protocol" {
+			return slog.New(slog.NewTextHandler(
+				os.Stderr,
+				&slog.HandlerOptions{Level: slog.LevelDebug},
+			))
+		}
+	}
+
+	return nil
+}
+
+// connLogger returns the logger for messages about conn.
+func connLogger(cfg *connConfig, conn net.Conn) *slog.Logger {
+	if cfg.logger == nil {
+		return nil
+	}
+
+	return cfg.logger.With("conn", conn.LocalAddr().String())
+}
+
+// logFrames logs the type and length of every message sent and received on
+// conn. Message bodies are never logged so passwords, secret keys and SCRAM
+// messages don't end up in the logs.
+func logFrames(conn net.Conn, logger *slog.Logger) net.Conn {
+	return wiretrace.Wrap(conn, func(f wiretrace.Frame) {
+		msg := "received message"
+		if f.Sender == wiretrace.Client {
+			msg = "sent message"
+		}
+
+		logger.Debug(
+			msg,
+			"type", traceMessageName(f.Code, f.Sender),
+			"length", len(f.Payload),
+		)
+	})
+}
+
+func (c *connConfig) debug(msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.Debug(msg, args...)
+	}
+}
+
+func (c *protocolConnection) debug(msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.Debug(msg, args...)
+	}
+}
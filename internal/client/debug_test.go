@@ -0,0 +1,129 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/codecs"
+	fs "github.com/geldata/gel-go/internal/fakeserver"
+)
+
+func TestDebugLogging(t *testing.T) {
+	ctx := context.Background()
+	server, err := fs.Start(fs.Options{Password: "hunter2"})
+	require.NoError(t, err)
+	defer server.Close() // nolint:errcheck
+
+	server.Handle("SELECT 1", &fs.Response{
+		Output: fs.ScalarDescriptor(codecs.Int64ID, "std::int64"),
+		Data:   [][]byte{fs.Int64(1)},
+	})
+	server.Handle("UPDATE User SET { n := .n + 1 }",
+		&fs.Response{Error: &fs.Error{
+			Code:    0x05_03_01_00, // TransactionConflictError
+			Message: "could not serialize access",
+		}},
+		&fs.Response{Status: "UPDATE"},
+	)
+
+	var logs bytes.Buffer
+	opts := server.ClientOptions()
+	opts.Logger = slog.New(slog.NewJSONHandler(
+		&logs,
+		&slog.HandlerOptions{Level: slog.LevelDebug},
+	))
+	client, err := gel.CreateClient(opts)
+	require.NoError(t, err)
+
+	var result int64
+	require.NoError(t, client.QuerySingle(ctx, "SELECT 1", &result))
+	require.NoError(t, client.QuerySingle(ctx, "SELECT 1", &result))
+	err = client.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+		return tx.Execute(ctx, "UPDATE User SET { n := .n + 1 }")
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+
+	assert.NotContains(t, logs.String(), "hunter2")
+
+	var records []map[string]any
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var record map[string]any
+		require.NoError(t, dec.Decode(&record))
+		assert.Equal(t, "DEBUG", record["level"])
+		records = append(records, record)
+	}
+
+	find := func(msg string, attrs map[string]any) bool {
+		for _, record := range records {
+			if record["msg"] != msg {
+				continue
+			}
+
+			matches := true
+			for k, v := range attrs {
+				matches = matches && record[k] == v
+			}
+
+			if matches {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	assert.True(t, find("sent message", map[string]any{
+		"type": "AuthenticationSASLInitialResponse",
+	}))
+	assert.True(t, find("sent message", map[string]any{"type": "Parse"}))
+	assert.True(t, find("received message", map[string]any{
+		"type": "CommandDataDescription",
+	}))
+	assert.True(t, find("connected", map[string]any{"user": "edgedb"}))
+	assert.True(t, find("type id cache miss", map[string]any{
+		"query": "SELECT 1",
+	}))
+	assert.True(t, find("type id cache hit", map[string]any{
+		"query": "SELECT 1",
+	}))
+	assert.True(t, find("described query", map[string]any{
+		"query":        "SELECT 1",
+		"cardinality":  "AtMostOne",
+		"capabilities": float64(0),
+	}))
+	assert.True(t, find("execute", map[string]any{
+		"query":  "SELECT 1",
+		"format": "Binary",
+		"in":     geltypes.UUID{}.String(),
+		"out":    codecs.Int64ID.String(),
+	}))
+	assert.True(t, find("retrying transaction", map[string]any{
+		"attempt": float64(1),
+	}))
+}
@@ -18,7 +18,9 @@ package gel
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"time"
 
 	types "github.com/geldata/gel-go/geltypes"
@@ -42,6 +44,7 @@ type cacheCollection struct {
 	inCodecCache      *cache.Cache
 	outCodecCache     *cache.Cache
 	capabilitiesCache *cache.Cache // nolint:structcheck
+	schemaCache       *schemaCache
 }
 
 type protocolConnection struct {
@@ -55,6 +58,7 @@ type protocolConnection struct {
 
 	SystemConfig systemConfig
 	stateCodec   codecs.Encoder
+	logger       *slog.Logger
 }
 
 // connectWithTimeout makes a single attempt to connect to `addr`.
@@ -79,6 +83,7 @@ func connectWithTimeout(
 		acquireReaderSignal: make(chan struct{}, 1),
 		readerChan:          make(chan *buff.Reader, 1),
 		cacheCollection:     caches,
+		logger:              connLogger(cfg, socket.conn),
 	}
 
 	toBeDeserialized := make(chan *soc.Data, 2)
@@ -95,6 +100,18 @@ func connectWithTimeout(
 		return nil, err
 	}
 
+	conn.debug(
+		"connected",
+		"address", cfg.addr.address,
+		"user", cfg.user,
+		"branch", cfg.branch,
+		"protocol_version", fmt.Sprintf(
+			"%v.%v",
+			conn.protocolVersion.Major,
+			conn.protocolVersion.Minor,
+		),
+	)
+
 	return conn, conn.releaseReader(r)
 }
 
@@ -18,7 +18,6 @@ package gel
 
 import (
 	"fmt"
-	"reflect"
 
 	"github.com/geldata/gel-go/internal/buff"
 	"github.com/geldata/gel-go/internal/codecs"
@@ -40,6 +39,7 @@ func (c *protocolConnection) execGranularFlow1pX(
 	if err != nil {
 		return err
 	} else if cdcs == nil {
+		c.debug("codec cache miss", "query", q.cmd)
 		return c.pesimistic1pX(r, q)
 	}
 
@@ -64,6 +64,14 @@ func (c *protocolConnection) parse1pX(
 	r *buff.Reader,
 	q *query,
 ) (*CommandDescription, error) {
+	c.debug(
+		"parse",
+		"query", q.cmd,
+		"capabilities", q.getCapabilities(),
+		"format", q.fmt.String(),
+		"cardinality", q.expCard.String(),
+	)
+
 	w := buff.NewWriter(c.writeMemory[:0])
 	w.BeginMessage(uint8(Parse))
 	w.PushUint16(0) // no headers
@@ -131,7 +139,8 @@ func (c *protocolConnection) decodeCommandDataDescriptionMsg1pX(
 		return nil, err
 	}
 
-	c.cacheCapabilities1pX(q, r.PopUint64())
+	capabilities := r.PopUint64()
+	c.cacheCapabilities1pX(q, capabilities)
 
 	var descs CommandDescription
 	descs.Card = Cardinality(r.PopUint8())
@@ -172,6 +181,15 @@ func (c *protocolConnection) decodeCommandDataDescriptionMsg1pX(
 			q.expCard), nil)
 	}
 
+	c.debug(
+		"described query",
+		"query", q.cmd,
+		"capabilities", capabilities,
+		"cardinality", descs.Card.String(),
+		"in", descs.In.ID,
+		"out", descs.Out.ID,
+	)
+
 	c.cacheTypeIDs(q, idPair{in: descs.In.ID, out: descs.Out.ID})
 	descCache.Put(descs.In.ID, descs.In)
 	descCache.Put(descs.Out.ID, descs.Out)
@@ -183,6 +201,16 @@ func (c *protocolConnection) execute1pX(
 	q *query,
 	cdcs *codecPair,
 ) error {
+	c.debug(
+		"execute",
+		"query", q.cmd,
+		"capabilities", q.getCapabilities(),
+		"format", q.fmt.String(),
+		"cardinality", q.expCard.String(),
+		"in", cdcs.in.DescriptorID(),
+		"out", cdcs.out.DescriptorID(),
+	)
+
 	w := buff.NewWriter(c.writeMemory[:0])
 	w.BeginMessage(uint8(Execute))
 	w.PushUint16(0) // no headers
@@ -214,6 +242,7 @@ func (c *protocolConnection) execute1pX(
 		return gelerr.NewClientConnectionClosedError("", e)
 	}
 
+	q.describe(cdcs)
 	tmp := q.out
 	if q.expCard == AtMostOne {
 		err = ErrZeroResults
@@ -231,6 +260,9 @@ func (c *protocolConnection) execute1pX(
 			err = wrapAll(err, e)
 			cdcs, e = c.codecsFromDescriptors1pX(q, descs)
 			err = wrapAll(err, e)
+			if e == nil {
+				q.describe(cdcs)
+			}
 		case Data:
 			val, ok, e := decodeDataMsg(r, q, cdcs)
 			if e != nil {
@@ -241,7 +273,9 @@ func (c *protocolConnection) execute1pX(
 				}
 			}
 			if ok {
-				tmp = reflect.Append(tmp, val)
+				var e error
+				tmp, e = q.collect(tmp, val)
+				err = wrapAll(err, e)
 			}
 
 			if err == ErrZeroResults {
@@ -326,7 +360,7 @@ func (c *protocolConnection) decodeCommandCompleteMsg1pX(
 ) error {
 	discardHeaders1pX(r)
 	c.cacheCapabilities1pX(q, r.PopUint64())
-	r.Discard(int(r.PopUint32())) // discard command status
+	q.status = r.PopString()
 	if r.PopUUID() == descriptor.IDZero {
 		// empty state data
 		r.Discard(4)
@@ -49,6 +49,7 @@ func (c *protocolConnection) execGranularFlow2pX(
 		if err != nil {
 			return err
 		} else if cdcs == nil {
+			c.debug("codec cache miss", "query", q.cmd)
 			return c.pesimistic2pX(r, q)
 		}
 	} else {
@@ -94,6 +95,14 @@ func (c *protocolConnection) parse2pX(
 	r *buff.Reader,
 	q *query,
 ) (*CommandDescriptionV2, error) {
+	c.debug(
+		"parse",
+		"query", q.cmd,
+		"capabilities", q.getCapabilities(),
+		"format", q.fmt.String(),
+		"cardinality", q.expCard.String(),
+	)
+
 	w := buff.NewWriter(c.writeMemory[:0])
 	w.BeginMessage(uint8(Parse))
 	if err := c.writeAnnotations(w, q); err != nil {
@@ -193,7 +202,8 @@ func (c *protocolConnection) decodeCommandDataDescriptionMsg2pX(
 		q.unsafeIsolationDangers = errors
 	}
 
-	c.cacheCapabilities1pX(q, r.PopUint64())
+	capabilities := r.PopUint64()
+	c.cacheCapabilities1pX(q, capabilities)
 
 	var descs CommandDescriptionV2
 	descs.Card = Cardinality(r.PopUint8())
@@ -234,6 +244,15 @@ func (c *protocolConnection) decodeCommandDataDescriptionMsg2pX(
 			q.expCard), nil)
 	}
 
+	c.debug(
+		"described query",
+		"query", q.cmd,
+		"capabilities", capabilities,
+		"cardinality", descs.Card.String(),
+		"in", descs.In.ID,
+		"out", descs.Out.ID,
+	)
+
 	c.cacheTypeIDs(q, idPair{in: descs.In.ID, out: descs.Out.ID})
 	descCache.Put(descs.In.ID, descs.In)
 	descCache.Put(descs.Out.ID, descs.Out)
@@ -319,6 +338,16 @@ func (c *protocolConnection) execute2pX(
 	q *query,
 	cdcs *codecPair,
 ) error {
+	c.debug(
+		"execute",
+		"query", q.cmd,
+		"capabilities", q.getCapabilities(),
+		"format", q.fmt.String(),
+		"cardinality", q.expCard.String(),
+		"in", cdcs.in.DescriptorID(),
+		"out", cdcs.out.DescriptorID(),
+	)
+
 	w := buff.NewWriter(c.writeMemory[:0])
 	w.BeginMessage(uint8(Execute))
 	if err := c.writeAnnotations(w, q); err != nil {
@@ -361,6 +390,7 @@ func (c *protocolConnection) execute2pX(
 		return gelerr.NewClientConnectionClosedError("", e)
 	}
 
+	q.describe(cdcs)
 	tmp := q.out
 	if q.expCard == AtMostOne {
 		err = ErrZeroResults
@@ -378,6 +408,9 @@ func (c *protocolConnection) execute2pX(
 			err = wrapAll(err, e)
 			cdcs, e = c.codecsFromDescriptors2pX(q, descs)
 			err = wrapAll(err, e)
+			if e == nil {
+				q.describe(cdcs)
+			}
 		case Data:
 			val, ok, e := decodeDataMsg(r, q, cdcs)
 			if e != nil {
@@ -388,7 +421,9 @@ func (c *protocolConnection) execute2pX(
 				}
 			}
 			if ok {
-				tmp = reflect.Append(tmp, val)
+				var e error
+				tmp, e = q.collect(tmp, val)
+				err = wrapAll(err, e)
 			}
 
 			if err == ErrZeroResults {
@@ -514,7 +549,7 @@ func (c *protocolConnection) decodeCommandCompleteMsg2pX(
 ) error {
 	discardHeaders2pX(r)
 	c.cacheCapabilities1pX(q, r.PopUint64())
-	r.Discard(int(r.PopUint32())) // discard command status
+	q.status = r.PopString()
 	if r.PopUUID() == descriptor.IDZero {
 		// empty state data
 		r.Discard(4)
@@ -0,0 +1,225 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/internal/gelerr"
+	"github.com/xdg/scram"
+)
+
+// HTTPConfig is the configuration for making HTTP requests to a server.
+type HTTPConfig struct {
+	// ServerURL is the URL of the server, for example
+	// https://localhost:5656.
+	ServerURL string
+
+	// BaseURL is the URL of the branch, for example
+	// https://localhost:5656/branch/main.
+	BaseURL string
+
+	// TLSConfig verifies the server the same way connections do.
+	TLSConfig *tls.Config
+
+	// User and Password are used to request a token when there is no
+	// secret key.
+	User     string
+	Password string
+
+	// SecretKey is used to authenticate with cloud instances.
+	SecretKey string
+}
+
+// ResolveHTTPConfig resolves dsn and opts the same way [NewPool] does and
+// returns the configuration for HTTP requests to the resolved server.
+func ResolveHTTPConfig(dsn string, opts gelcfg.Options) (*HTTPConfig, error) { // nolint:gocritic,lll
+	cfg, err := parseConnectDSNAndArgs(dsn, &opts, newCfgPaths())
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.httpConfig()
+}
+
+// HTTPConfig returns the configuration for HTTP requests to the server the
+// pool connects to.
+func (p *Pool) HTTPConfig() (*HTTPConfig, error) { return p.Cfg.httpConfig() }
+
+// ClientPool returns the pool of a *gel.Client. It is set by the gel package
+// so that packages that import gel can reuse a client's resolved connection.
+var ClientPool func(client any) *Pool
+
+func (c *connConfig) httpConfig() (*HTTPConfig, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// The server speaks HTTP unless the binary protocol is negotiated.
+	tlsConfig.NextProtos = nil
+
+	server := &url.URL{Scheme: "https", Host: c.addr.address}
+	return &HTTPConfig{
+		ServerURL: server.String(),
+		BaseURL:   server.JoinPath("branch", c.branch).String(),
+		TLSConfig: tlsConfig,
+		User:      c.user,
+		Password:  c.password,
+		SecretKey: c.secretKey,
+	}, nil
+}
+
+// HTTPClient returns an http.Client that verifies the server with
+// c.TLSConfig.
+func (c *HTTPConfig) HTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = c.TLSConfig
+	return &http.Client{Transport: transport}
+}
+
+// Token returns a bearer token for HTTP requests. The secret key is used if
+// there is one, otherwise a token is requested with SCRAM authentication.
+func (c *HTTPConfig) Token(
+	ctx context.Context,
+	client *http.Client,
+) (string, error) {
+	if c.SecretKey != "" {
+		return c.SecretKey, nil
+	}
+
+	scramClient, err := scram.SHA256.NewClient(c.User, c.Password, "")
+	if err != nil {
+		return "", gelerr.NewAuthenticationError(err.Error(), nil)
+	}
+
+	conv := scramClient.NewConversation()
+	clientFirst, err := conv.Step("")
+	if err != nil {
+		return "", gelerr.NewAuthenticationError(err.Error(), nil)
+	}
+
+	resp, err := c.scramRequest(ctx, client, "data="+encode(clientFirst))
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+
+	header := resp.Header.Get("WWW-Authenticate")
+	if resp.StatusCode != http.StatusUnauthorized ||
+		!strings.HasPrefix(header, "SCRAM-SHA-256 ") {
+		return "", gelerr.NewAuthenticationError(fmt.Sprintf(
+			"unexpected response to SCRAM request: %v", resp.Status,
+		), nil)
+	}
+
+	attrs, err := scramAttrs(strings.TrimPrefix(header, "SCRAM-SHA-256 "))
+	if err != nil {
+		return "", err
+	}
+
+	clientFinal, err := conv.Step(attrs["data"])
+	if err != nil {
+		return "", gelerr.NewAuthenticationError(err.Error(), nil)
+	}
+
+	resp, err = c.scramRequest(ctx, client, fmt.Sprintf(
+		"sid=%v, data=%v", attrs["sid"], encode(clientFinal)))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", gelerr.NewAuthenticationError(fmt.Sprintf(
+			"authentication failed: %v: %s", resp.Status, body,
+		), nil)
+	}
+
+	attrs, err = scramAttrs(resp.Header.Get("Authentication-Info"))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := conv.Step(attrs["data"]); err != nil {
+		return "", gelerr.NewAuthenticationError(err.Error(), nil)
+	}
+
+	return string(body), nil
+}
+
+func (c *HTTPConfig) scramRequest(
+	ctx context.Context,
+	client *http.Client,
+	authorization string,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.ServerURL+"/auth/token",
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "SCRAM-SHA-256 "+authorization)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, gelerr.NewClientConnectionFailedError("", err)
+	}
+
+	return resp, nil
+}
+
+func encode(msg string) string {
+	return base64.StdEncoding.EncodeToString([]byte(msg))
+}
+
+// scramAttrs parses comma separated key=value pairs and decodes the base64
+// encoded data attribute.
+func scramAttrs(header string) (map[string]string, error) {
+	attrs := make(map[string]string)
+	for _, attr := range strings.Split(header, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(attr), "=")
+		if ok {
+			attrs[key] = val
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(attrs["data"])
+	if err != nil || attrs["data"] == "" {
+		return nil, gelerr.NewAuthenticationError(
+			"invalid SCRAM attributes: "+header, nil)
+	}
+
+	attrs["data"] = string(data)
+	return attrs, nil
+}
@@ -0,0 +1,138 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xdg/scram"
+)
+
+func TestHTTPConfig(t *testing.T) {
+	cfg := connConfig{
+		addr:        dialArgs{"tcp", "example.com:5656"},
+		branch:      "my branch",
+		tlsSecurity: "insecure",
+		secretKey:   "key",
+	}
+
+	http, err := cfg.httpConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com:5656", http.ServerURL)
+	assert.Equal(t, "https://example.com:5656/branch/my%20branch",
+		http.BaseURL)
+	assert.True(t, http.TLSConfig.InsecureSkipVerify)
+	assert.Nil(t, http.TLSConfig.NextProtos)
+	assert.Equal(t, "key", http.SecretKey)
+}
+
+// scramServer is a stand-in for the server's /auth/token endpoint.
+func scramServer(t *testing.T, user, password string) *httptest.Server {
+	client, err := scram.SHA256.NewClient(user, password, "")
+	require.NoError(t, err)
+	creds := client.GetStoredCredentials(scram.KeyFactors{
+		Salt:  "salt",
+		Iters: 4096,
+	})
+	server, err := scram.SHA256.NewServer(
+		func(name string) (scram.StoredCredentials, error) {
+			if name != user {
+				return scram.StoredCredentials{}, fmt.Errorf("no user")
+			}
+			return creds, nil
+		},
+	)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	conversations := make(map[string]*scram.ServerConversation)
+
+	return httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/auth/token" {
+				http.NotFound(w, r)
+				return
+			}
+
+			header := r.Header.Get("Authorization")
+			attrs, err := scramAttrs(
+				strings.TrimPrefix(header, "SCRAM-SHA-256 "))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			sid, ok := attrs["sid"]
+			conv := conversations[sid]
+			if !ok {
+				sid = fmt.Sprint(len(conversations) + 1)
+				conv = server.NewConversation()
+				conversations[sid] = conv
+			}
+
+			msg, err := conv.Step(attrs["data"])
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			data := base64.StdEncoding.EncodeToString([]byte(msg))
+			attr := fmt.Sprintf("sid=%v, data=%v", sid, data)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "SCRAM-SHA-256 "+attr)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Header().Set("Authentication-Info", attr)
+			_, _ = w.Write([]byte("token"))
+		},
+	))
+}
+
+func TestHTTPConfigToken(t *testing.T) {
+	server := scramServer(t, "admin", "secret")
+	defer server.Close()
+
+	cfg := HTTPConfig{ServerURL: server.URL, User: "admin"}
+
+	cfg.Password = "secret"
+	token, err := cfg.Token(context.Background(), server.Client())
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	cfg.Password = "wrong"
+	_, err = cfg.Token(context.Background(), server.Client())
+	assert.ErrorContains(t, err, "authentication failed")
+
+	cfg.SecretKey = "key"
+	token, err = cfg.Token(context.Background(), server.Client())
+	require.NoError(t, err)
+	assert.Equal(t, "key", token)
+}
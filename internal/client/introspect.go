@@ -35,9 +35,9 @@ type CommandDescription struct {
 // CommandDescriptionV2 is the information returned in the
 // CommandDataDescription message
 type CommandDescriptionV2 struct {
-	In   descriptor.V2
-	Out  descriptor.V2
-	Card Cardinality
+	In   descriptor.V2 `json:"in"`
+	Out  descriptor.V2 `json:"out"`
+	Card Cardinality   `json:"card"`
 }
 
 // Describe returns CommandDescription for the provided cmd.
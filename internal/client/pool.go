@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
 	"runtime"
 	"sync"
 	"time"
@@ -46,12 +47,22 @@ func NewPool(dsn string, opts gelcfg.Options) (*Pool, error) { // nolint:gocriti
 		warningHandler = opts.WarningHandler
 	}
 
+	return newPool(cfg, int(opts.Concurrency), QueryConfig{
+		WarningHandler: warningHandler,
+		QueryOptions:   gelcfg.NewQueryOptions(),
+		TxOptions:      gelcfg.NewTxOptions(),
+		RetryOptions:   gelcfg.NewRetryOptions(),
+		Annotations:    make(map[string]string),
+	}), nil
+}
+
+func newPool(cfg *connConfig, concurrency int, queryConfig QueryConfig) *Pool {
 	False := false
-	p := &Pool{
+	return &Pool{
 		isClosed:             &False,
 		isClosedMutex:        &sync.RWMutex{},
 		Cfg:                  cfg,
-		Concurrency:          int(opts.Concurrency),
+		Concurrency:          concurrency,
 		freeConns:            make(chan func() *transactableConn, 1),
 		potentialConnsMutext: &sync.Mutex{},
 		cacheCollection: cacheCollection{
@@ -60,20 +71,32 @@ func NewPool(dsn string, opts gelcfg.Options) (*Pool, error) { // nolint:gocriti
 			inCodecCache:      cache.New(1_000),
 			outCodecCache:     cache.New(1_000),
 			capabilitiesCache: cache.New(1_000),
+			schemaCache:       newSchemaCache(),
 		},
-		State: make(map[string]interface{}),
-		QueryConfig: QueryConfig{
-			WarningHandler: warningHandler,
-			QueryOptions:   gelcfg.NewQueryOptions(),
-			TxOptions:      gelcfg.NewTxOptions(),
-			RetryOptions:   gelcfg.NewRetryOptions(),
-			Annotations:    make(map[string]string),
-		},
+		State:       make(map[string]interface{}),
+		QueryConfig: queryConfig,
 	}
+}
 
-	return p, nil
+// WithBranch returns a new pool that connects to branch. The new pool has
+// the same configuration as p but its own connections and caches.
+func (p *Pool) WithBranch(branch string) *Pool {
+	cfg := *p.Cfg
+	cfg.branch = branch
+	cfg.database = branch
+
+	queryConfig := p.QueryConfig
+	queryConfig.Annotations = make(map[string]string)
+	maps.Copy(queryConfig.Annotations, p.QueryConfig.Annotations)
+
+	pool := newPool(&cfg, p.Concurrency, queryConfig)
+	maps.Copy(pool.State, p.State)
+	return pool
 }
 
+// Branch returns the name of the branch the pool connects to.
+func (p *Pool) Branch() string { return p.branchName() }
+
 // Pool is a connection pool.
 type Pool struct {
 	isClosed      *bool
@@ -111,6 +134,9 @@ func (p *Pool) newConn(ctx context.Context) (*transactableConn, error) {
 	return &conn, nil
 }
 
+// Conn is a connection acquired from a Pool.
+type Conn = transactableConn
+
 // Acquire gets a connection from the pool.
 func (p *Pool) Acquire(
 	ctx context.Context,
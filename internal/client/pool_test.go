@@ -0,0 +1,53 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"testing"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolWithBranch(t *testing.T) {
+	pool, err := NewPool("gel://localhost:5656/main", gelcfg.Options{
+		Concurrency: 3,
+	})
+	require.NoError(t, err)
+	pool.QueryConfig.Annotations["tag"] = "test"
+	pool.State["globals"] = map[string]interface{}{"a": int64(1)}
+
+	other := pool.WithBranch("preview")
+	assert.Equal(t, "main", pool.Branch())
+	assert.Equal(t, "preview", other.Branch())
+	assert.Equal(t, 3, other.Concurrency)
+	assert.Equal(t, pool.Cfg.addr, other.Cfg.addr)
+	assert.Equal(t, pool.State, other.State)
+	assert.Equal(t,
+		pool.QueryConfig.Annotations,
+		other.QueryConfig.Annotations,
+	)
+	assert.NotSame(t, pool.cacheCollection.typeIDCache,
+		other.cacheCollection.typeIDCache)
+
+	other.QueryConfig.Annotations["tag"] = "other"
+	assert.Equal(t, "test", pool.QueryConfig.Annotations["tag"])
+
+	require.NoError(t, other.Close())
+	assert.False(t, *pool.isClosed)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/geldata/gel-go/gelcfg"
 	"github.com/geldata/gel-go/gelerr"
 	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/codecs"
 	gelerrint "github.com/geldata/gel-go/internal/gelerr"
 	"github.com/geldata/gel-go/internal/introspect"
 )
@@ -45,6 +46,12 @@ type query struct {
 	unsafeIsolationDangers []error
 	isInTx                 bool
 
+	// rows receives decoded rows instead of q.out when it is not nil.
+	rows RowHandler
+
+	// status is the command status sent by the server in CommandComplete.
+	status string
+
 	// Used when providing the position of errors in a query.
 	// The fully qualified edgeql file path.
 	// If cmd is not from it's own file then use the value "query".
@@ -59,6 +66,25 @@ func (q *query) getCapabilities() uint64 {
 	return capabilities
 }
 
+// describe passes the output decoder to the query's RowHandler if it has one.
+func (q *query) describe(cdcs *codecPair) {
+	if q.rows != nil && cdcs != nil {
+		q.rows.Describe(cdcs.out)
+	}
+}
+
+// collect appends val to rows or passes it to the query's RowHandler.
+func (q *query) collect(
+	rows reflect.Value,
+	val reflect.Value,
+) (reflect.Value, error) {
+	if q.rows == nil {
+		return reflect.Append(rows, val), nil
+	}
+
+	return rows, q.rows.Row(val)
+}
+
 func (q *query) flat() bool {
 	if q.expCard != Many {
 		return true
@@ -205,6 +231,10 @@ func RunQuery(
 		}
 	}
 
+	if method == "QuerySingleSQL" {
+		return runQuerySingleSQL(ctx, c, cmd, out, args, state, cfg, isInTx)
+	}
+
 	q, err := NewQuery(
 		method,
 		cmd,
@@ -235,6 +265,116 @@ func RunQuery(
 	return err
 }
 
+// RowHandler receives query results one row at a time.
+type RowHandler interface {
+	// Describe is called with the output decoder before any rows are
+	// decoded with it.
+	Describe(codecs.Decoder)
+
+	// Row is called with each decoded row.
+	Row(reflect.Value) error
+}
+
+// StreamQuery runs a query passing each row to handler instead of collecting
+// the rows into a slice. Rows are decoded into rowType which must be nil for
+// the Execute and ExecuteSQL methods. The command status reported by the
+// server is returned.
+//
+// Rows already passed to handler can not be taken back if the query is
+// retried, so callers should configure cfg to make a single attempt.
+func StreamQuery(
+	ctx context.Context,
+	c queryable,
+	method, cmd string,
+	rowType reflect.Type,
+	args []interface{},
+	state map[string]interface{},
+	cfg *QueryConfig,
+	isInTx bool,
+	handler RowHandler,
+) (string, error) {
+	var out interface{}
+	if rowType != nil {
+		out = reflect.New(reflect.SliceOf(rowType)).Interface()
+	}
+
+	q, err := NewQuery(
+		method,
+		cmd,
+		args,
+		c.Capabilities1pX(),
+		state,
+		out,
+		true,
+		cfg,
+		isInTx,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if q.flat() && q.fmt != Null {
+		return "", gelerrint.NewInterfaceError(fmt.Sprintf(
+			"%v results can not be streamed", method), nil)
+	}
+
+	q.rows = handler
+	err = c.granularFlow(ctx, q)
+	return q.status, err
+}
+
+// runQuerySingleSQL runs a SQL query expecting at most one row. SQL queries
+// always have a result cardinality of Many, so the number of rows is checked
+// after the query has run instead of being sent to the server.
+func runQuerySingleSQL(
+	ctx context.Context,
+	c queryable,
+	cmd string,
+	out interface{},
+	args []interface{},
+	state map[string]interface{},
+	cfg *QueryConfig,
+	isInTx bool,
+) error {
+	val, err := introspect.ValueOf(out)
+	if err != nil {
+		return gelerrint.NewInterfaceError("", err)
+	}
+
+	rows := reflect.New(reflect.SliceOf(val.Type()))
+	err = RunQuery(
+		ctx,
+		c,
+		"QuerySQL",
+		cmd,
+		rows.Interface(),
+		args,
+		state,
+		cfg,
+		isInTx,
+	)
+	if err != nil {
+		return err
+	}
+
+	switch rows.Elem().Len() {
+	case 0:
+		if opt, ok := out.(unseter); ok {
+			opt.Unset()
+			return nil
+		}
+		return ErrZeroResults
+	case 1:
+		val.Set(rows.Elem().Index(0))
+		return nil
+	default:
+		return gelerrint.NewResultCardinalityMismatchError(fmt.Sprintf(
+			"the query returned %v rows but at most one was expected",
+			rows.Elem().Len(),
+		), nil)
+	}
+}
+
 // CopyState makes a copy of the state.
 func CopyState(in map[string]interface{}) map[string]interface{} {
 	out := make(map[string]interface{}, len(in))
@@ -50,6 +50,8 @@ func (c *reconnectingConn) reconnect(
 		maxTime = deadline
 	}
 
+	c.Cfg.debug("connecting", "address", c.Cfg.addr.address)
+
 	var edbErr gelerr.Error
 	for {
 		conn, err := connectWithTimeout(ctx, c.Cfg, c.cacheCollection)
@@ -67,6 +69,7 @@ func (c *reconnectingConn) reconnect(
 			return err
 		}
 
+		c.Cfg.debug("reconnecting", "error", err)
 		time.Sleep(time.Duration(10+rnd.Intn(200)) * time.Millisecond)
 	}
 }
@@ -0,0 +1,85 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"sync"
+
+	"github.com/geldata/gel-go/gelschema"
+)
+
+// schemaCache holds the introspected schema of each branch. All schemas are
+// dropped when a connection observes a DDL capability. The generation is
+// incremented on every invalidation so that a schema that was loaded while
+// DDL was being executed is not cached.
+type schemaCache struct {
+	mu         sync.Mutex
+	generation uint64
+	schemas    map[string]*gelschema.Schema
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{schemas: make(map[string]*gelschema.Schema)}
+}
+
+func (c *schemaCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	clear(c.schemas)
+}
+
+func (c *schemaCache) get(branch string) (*gelschema.Schema, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.schemas[branch], c.generation
+}
+
+func (c *schemaCache) put(
+	branch string,
+	generation uint64,
+	schema *gelschema.Schema,
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation == c.generation {
+		c.schemas[branch] = schema
+	}
+}
+
+func (p *Pool) branchName() string {
+	if p.Cfg.branch != "" {
+		return p.Cfg.branch
+	}
+
+	return p.Cfg.database
+}
+
+// CachedSchema returns the cached schema for the pool's branch or nil if it
+// isn't cached. The returned generation must be passed to CacheSchema.
+func (p *Pool) CachedSchema() (*gelschema.Schema, uint64) {
+	return p.cacheCollection.schemaCache.get(p.branchName())
+}
+
+// CacheSchema caches the schema for the pool's branch unless DDL was
+// observed after generation was returned by CachedSchema.
+func (p *Pool) CacheSchema(schema *gelschema.Schema, generation uint64) {
+	p.cacheCollection.schemaCache.put(p.branchName(), generation, schema)
+}
@@ -0,0 +1,76 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"testing"
+
+	"github.com/geldata/gel-go/gelschema"
+	"github.com/geldata/gel-go/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaCacheInvalidation(t *testing.T) {
+	c := newSchemaCache()
+	schema := &gelschema.Schema{}
+
+	cached, generation := c.get("main")
+	assert.Nil(t, cached)
+
+	c.put("main", generation, schema)
+	cached, _ = c.get("main")
+	assert.Same(t, schema, cached)
+
+	c.invalidate()
+	cached, _ = c.get("main")
+	assert.Nil(t, cached)
+
+	// A schema loaded before the invalidation is not cached.
+	c.put("main", generation, schema)
+	cached, generation = c.get("main")
+	assert.Nil(t, cached)
+
+	c.put("main", generation, schema)
+	cached, _ = c.get("main")
+	assert.Same(t, schema, cached)
+
+	cached, _ = c.get("other")
+	assert.Nil(t, cached)
+}
+
+func TestDDLInvalidatesSchemaCache(t *testing.T) {
+	conn := &protocolConnection{
+		cacheCollection: cacheCollection{
+			typeIDCache:       cache.New(1),
+			inCodecCache:      cache.New(1),
+			outCodecCache:     cache.New(1),
+			capabilitiesCache: cache.New(1),
+			schemaCache:       newSchemaCache(),
+		},
+	}
+
+	_, generation := conn.schemaCache.get("main")
+	conn.schemaCache.put("main", generation, &gelschema.Schema{})
+
+	conn.cacheCapabilities1pX(&query{}, 0)
+	cached, _ := conn.schemaCache.get("main")
+	assert.NotNil(t, cached)
+
+	conn.cacheCapabilities1pX(&query{}, capabilitiesDDL)
+	cached, _ = conn.schemaCache.get("main")
+	assert.Nil(t, cached)
+}
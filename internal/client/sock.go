@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/geldata/gel-go/internal/gelerr"
+	"github.com/geldata/gel-go/internal/wiretrace"
 )
 
 func connectAutoClosingSocket(
@@ -41,6 +42,14 @@ func connectAutoClosingSocket(
 		return nil, err
 	}
 
+	if cfg.wireTrace != nil {
+		conn = cfg.wireTrace.Wrap(conn)
+	}
+
+	if logger := connLogger(cfg, conn); logger != nil {
+		conn = logFrames(conn, logger)
+	}
+
 	return &autoClosingSocket{conn: conn}, nil
 }
 
@@ -70,6 +79,26 @@ func connectTLS(
 	return conn, nil
 }
 
+// traceMessageName names a message in a wire trace. Some client and server
+// messages share a type code so the sender decides which name is used.
+func traceMessageName(code byte, sender wiretrace.Sender) string {
+	msg := Message(code)
+	if sender == wiretrace.Client {
+		switch msg {
+		case DescribeStatement:
+			return "DescribeStatement"
+		case Execute0pX:
+			return "Execute0pX"
+		case RestoreBlock:
+			return "RestoreBlock"
+		case Sync:
+			return "Sync"
+		}
+	}
+
+	return msg.String()
+}
+
 // autoClosingSocket closes itself on network errors and future read/write
 // operations fail immediately with an error.
 type autoClosingSocket struct {
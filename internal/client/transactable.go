@@ -71,7 +71,15 @@ func (c *transactableConn) withRetries(
 				return err
 			}
 
-			time.Sleep(rule.Backoff()(i))
+			backoff := rule.Backoff()(i)
+			c.Cfg.debug(
+				"retrying query",
+				"query", q.cmd,
+				"attempt", i,
+				"backoff", backoff,
+				"error", err,
+			)
+			time.Sleep(backoff)
 			continue
 		}
 
@@ -153,6 +161,10 @@ func (c *transactableConn) Tx(
 			}
 
 			optimisticRepeatableRead = false
+			c.Cfg.debug(
+				"retrying transaction without repeatable read",
+				"error", err,
+			)
 			i--
 			continue
 		}
@@ -167,7 +179,14 @@ func (c *transactableConn) Tx(
 				return err
 			}
 
-			time.Sleep(rule.Backoff()(i))
+			backoff := rule.Backoff()(i)
+			c.Cfg.debug(
+				"retrying transaction",
+				"attempt", i,
+				"backoff", backoff,
+				"error", err,
+			)
+			time.Sleep(backoff)
 			continue
 		}
 
@@ -360,3 +360,26 @@ func (t *Tx) QuerySQL(
 		true,
 	)
 }
+
+// QuerySingleSQL runs a SQL query that returns at most one row.
+// If the query executes successfully but doesn't return a row
+// a NoDataError is returned. If the out argument is an optional type the out
+// argument will be set to missing instead of returning a NoDataError.
+func (t *Tx) QuerySingleSQL(
+	ctx context.Context,
+	cmd string,
+	out interface{},
+	args ...interface{},
+) error {
+	return RunQuery(
+		ctx,
+		t,
+		"QuerySingleSQL",
+		cmd,
+		out,
+		args,
+		t.state,
+		&t.cfg,
+		true,
+	)
+}
@@ -39,41 +39,76 @@ func (w *Warning) Err(query, filename string) error {
 		return gelerr.ErrorFromCode(w.Code, w.Message)
 	}
 
-	lineNo := *w.Line - 1
-	byteNo := *w.Start
-	lines := strings.Split(query, "\n")
-	if lineNo >= len(lines) {
+	loc, ok := Locate(query, *w.Line, *w.Start)
+	if !ok {
 		return gelerr.ErrorFromCode(w.Code, w.Message)
 	}
 
-	// replace tabs with a single space
-	// because we don't know how they will be printed.
-	line := strings.ReplaceAll(lines[lineNo], "\t", " ")
-
-	for i := 0; i < lineNo; i++ {
-		byteNo -= 1 + len(lines[i])
-	}
-
-	if byteNo >= len(line) {
-		byteNo = 0
-	}
-
 	hint := w.Hint
 	if hint == "" {
 		hint = "error"
 	}
 
-	runeCount := utf8.RuneCountInString(line[:byteNo])
-	padding := strings.Repeat(" ", runeCount)
+	padding := strings.Repeat(" ", loc.Column-1)
 	msg := w.Message + fmt.Sprintf(
 		"\n%s:%v:%v\n\n%v\n%v^ %v",
 		filename,
-		1+lineNo,
-		1+runeCount,
-		line,
+		loc.Line,
+		loc.Column,
+		loc.Text,
 		padding,
 		hint,
 	)
 
 	return gelerr.ErrorFromCode(w.Code, msg)
 }
+
+// Location is a position in a query's source text.
+type Location struct {
+	// Line is the 1 based line number.
+	Line int
+	// Column is the 1 based column counted in runes.
+	Column int
+	// Text is the line with tabs replaced by spaces.
+	Text string
+}
+
+// Locate returns the location of the byte offset start in query. line is
+// the 1 based line that start is on. If start is past the end of the line
+// the location points to the beginning of the line.
+func Locate(query string, line, start int) (Location, bool) {
+	lineNo := line - 1
+	byteNo := start
+	lines := strings.Split(query, "\n")
+	if lineNo < 0 || lineNo >= len(lines) {
+		return Location{}, false
+	}
+
+	// replace tabs with a single space
+	// because we don't know how they will be printed.
+	text := strings.ReplaceAll(lines[lineNo], "\t", " ")
+
+	for i := 0; i < lineNo; i++ {
+		byteNo -= 1 + len(lines[i])
+	}
+
+	if byteNo < 0 || byteNo >= len(text) {
+		byteNo = 0
+	}
+
+	return Location{
+		Line:   line,
+		Column: 1 + utf8.RuneCountInString(text[:byteNo]),
+		Text:   text,
+	}, true
+}
+
+// LocateOffset returns the location of the byte offset start in query.
+func LocateOffset(query string, start int) (Location, bool) {
+	if start < 0 || start > len(query) {
+		return Location{}, false
+	}
+
+	line := 1 + strings.Count(query[:start], "\n")
+	return Locate(query, line, start)
+}
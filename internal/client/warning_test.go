@@ -0,0 +1,60 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarningErr(t *testing.T) {
+	line, start := 2, 20
+	w := Warning{
+		Code:    0x04_00_00_00,
+		Message: "deprecated",
+		Hint:    "use something else",
+		Line:    &line,
+		Start:   &start,
+	}
+
+	query := "SELECT 1;\n\tSELECT ëx;"
+	assert.EqualError(t, w.Err(query, "query.edgeql"), "gel.QueryError: "+
+		"deprecated\nquery.edgeql:2:10\n\n SELECT ëx;\n         ^ "+
+		"use something else")
+
+	w.Line = nil
+	assert.EqualError(t, w.Err(query, ""), "gel.QueryError: deprecated")
+}
+
+func TestLocate(t *testing.T) {
+	query := "SELECT\n  ë { name }"
+
+	loc, ok := LocateOffset(query, 11)
+	assert.True(t, ok)
+	assert.Equal(t, Location{Line: 2, Column: 4, Text: "  ë { name }"}, loc)
+
+	loc, ok = Locate(query, 1, 100)
+	assert.True(t, ok)
+	assert.Equal(t, Location{Line: 1, Column: 1, Text: "SELECT"}, loc)
+
+	_, ok = Locate(query, 3, 0)
+	assert.False(t, ok)
+
+	_, ok = LocateOffset(query, 100)
+	assert.False(t, ok)
+}
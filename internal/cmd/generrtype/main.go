@@ -123,6 +123,31 @@ func ErrorFromCode(code uint32, msg string) error {
 	fmt.Print(code)
 }
 
+func printCategoryMap(types []*errgen.Type) {
+	fmt.Print(`
+
+func ErrorFromCategory(category gelerr.ErrorCategory, msg string) error {
+	switch category {`)
+
+	for _, typ := range types {
+		fmt.Printf(`
+	case gelerr.%[1]v:
+		return &%[1]v{msg: msg}`,
+			typ.Name,
+		)
+	}
+	code := `
+	default:
+		return &InternalClientError{
+			msg: fmt.Sprintf(
+				"invalid error category %q with message %q", category, msg,
+			),
+		}
+	}
+}`
+	fmt.Print(code)
+}
+
 //nolint:typecheck
 func main() {
 	var data [][]interface{}
@@ -161,4 +186,5 @@ func main() {
 	)`)
 	printErrors(types)
 	printCodeMap(types)
+	printCategoryMap(types)
 }
@@ -175,7 +175,7 @@ func BuildScalarEncoder(desc descriptor.Descriptor) (Encoder, error) {
 	}
 
 	if desc.Type == descriptor.Enum {
-		return &StrCodec{desc.ID}, nil
+		return &enumEncoder{StrCodec{desc.ID}}, nil
 	}
 
 	switch desc.ID {
@@ -233,12 +233,21 @@ func BuildScalarEncoderV2(desc *descriptor.V2) (Encoder, error) {
 		desc = GetScalarDescriptorV2(desc)
 	}
 
+	if equivalent, ok := pgEquivalent(desc); ok {
+		encoder, err := BuildScalarEncoderV2(equivalent)
+		if err != nil {
+			return nil, err
+		}
+
+		return buildPgEncoder(desc, encoder), nil
+	}
+
 	if desc.ID == DecimalID {
 		return &decimalEncoder{}, nil
 	}
 
 	if desc.Type == descriptor.Enum {
-		return &StrCodec{desc.ID}, nil
+		return &enumEncoder{StrCodec{desc.ID}}, nil
 	}
 
 	switch desc.ID {
@@ -285,6 +294,10 @@ func BuildScalarEncoderV2(desc *descriptor.V2) (Encoder, error) {
 	case MemoryID:
 		return &MemoryCodec{}, nil
 	default:
+		if encoder, ok := buildExtensionEncoder(desc); ok {
+			return encoder, nil
+		}
+
 		s := fmt.Sprintf("%#v\n", desc)
 		return nil, fmt.Errorf("unknown scalar type id %v %v", desc.ID, s)
 	}
@@ -335,8 +348,10 @@ func BuildDecoderV2(
 	switch desc.Type {
 	case descriptor.Set:
 		return buildSetDecoderV2(desc, typ, path)
-	case descriptor.Object, descriptor.SQLRecord:
+	case descriptor.Object:
 		return buildObjectDecoderV2(desc, typ, path)
+	case descriptor.SQLRecord:
+		return buildSQLRecordDecoder(desc, typ, path)
 	case descriptor.BaseScalar, descriptor.Enum, descriptor.Scalar:
 		return buildScalarDecoderV2(desc, typ, path)
 	case descriptor.Tuple:
@@ -382,7 +397,12 @@ func buildScalarDecoder(
 		case optionalStrType:
 			return &optionalStrDecoder{StrID}, nil
 		default:
-			expectedType = "string or geltypes.OptionalStr"
+			if typ.Kind() == reflect.String {
+				return &StrCodec{desc.ID}, nil
+			}
+
+			expectedType = "string, a named string type " +
+				"or geltypes.OptionalStr"
 			goto TypeMissmatch
 		}
 	}
@@ -596,6 +616,15 @@ func buildScalarDecoderV2(
 		desc = GetScalarDescriptorV2(desc)
 	}
 
+	if equivalent, ok := pgEquivalent(desc); ok {
+		decoder, err := buildScalarDecoderV2(equivalent, typ, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return buildPgDecoder(desc, decoder), nil
+	}
+
 	decoder, ok, err := buildUnmarshalerV2(desc, typ)
 	if err != nil {
 		return decoder, err
@@ -613,7 +642,12 @@ func buildScalarDecoderV2(
 		case optionalStrType:
 			return &optionalStrDecoder{StrID}, nil
 		default:
-			expectedType = "string or geltypes.OptionalStr"
+			if typ.Kind() == reflect.String {
+				return &StrCodec{desc.ID}, nil
+			}
+
+			expectedType = "string, a named string type " +
+				"or geltypes.OptionalStr"
 			goto TypeMissmatch
 		}
 	}
@@ -808,6 +842,11 @@ func buildScalarDecoderV2(
 			expectedType = "geltypes.Memory or geltypes.OptionalMemory"
 		}
 	default:
+		decoder, ok, err := buildExtensionDecoder(desc, typ, path)
+		if ok {
+			return decoder, err
+		}
+
 		s := fmt.Sprintf("%#v\n", desc)
 		return nil, fmt.Errorf("unknown scalar type id %v %v", desc.ID, s)
 	}
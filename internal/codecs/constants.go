@@ -68,6 +68,17 @@ var (
 	BigIntID = types.UUID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0x10}
 	// MemoryID is the cfg::memory type descriptor ID
 	MemoryID = types.UUID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0x30}
+	// PgJSONID is the std::pg::json type descriptor ID
+	PgJSONID = types.UUID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 1}
+	// PgTimestampTZID is the std::pg::timestamptz type descriptor ID
+	PgTimestampTZID = types.UUID{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 2}
+	// PgTimestampID is the std::pg::timestamp type descriptor ID
+	PgTimestampID = types.UUID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 3}
+	// PgDateID is the std::pg::date type descriptor ID
+	PgDateID = types.UUID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 4}
+	// PgIntervalID is the std::pg::interval type descriptor ID
+	PgIntervalID = types.UUID{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 5}
 
 	int16Type                 = reflect.TypeOf(int16(0))
 	int32Type                 = reflect.TypeOf(int32(0))
@@ -0,0 +1,47 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+
+	"github.com/geldata/gel-go/internal/descriptor"
+)
+
+// buildExtensionEncoder looks up the encoder for an extension type by name.
+// Extension types don't have well known descriptor IDs.
+func buildExtensionEncoder(desc *descriptor.V2) (Encoder, bool) {
+	if encoder, ok := buildVectorEncoder(desc); ok {
+		return encoder, true
+	}
+
+	return buildGeometryEncoder(desc)
+}
+
+// buildExtensionDecoder looks up the decoder for an extension type by name.
+func buildExtensionDecoder(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	path Path,
+) (Decoder, bool, error) {
+	decoder, ok, err := buildVectorDecoder(desc, typ, path)
+	if ok {
+		return decoder, true, err
+	}
+
+	return buildGeometryDecoder(desc, typ, path)
+}
@@ -0,0 +1,160 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	"github.com/geldata/gel-go/internal/descriptor"
+)
+
+// Names of the ext::postgis types.
+const (
+	GeometryName  = "ext::postgis::geometry"
+	GeographyName = "ext::postgis::geography"
+)
+
+var (
+	geometryType         = reflect.TypeOf(types.Geometry{})
+	optionalGeometryType = reflect.TypeOf(types.OptionalGeometry{})
+)
+
+func buildGeometryEncoder(desc *descriptor.V2) (Encoder, bool) {
+	switch desc.Name {
+	case GeometryName, GeographyName:
+		return &GeometryCodec{desc.ID}, true
+	default:
+		return nil, false
+	}
+}
+
+func buildGeometryDecoder(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	path Path,
+) (Decoder, bool, error) {
+	switch desc.Name {
+	case GeometryName, GeographyName:
+	default:
+		return nil, false, nil
+	}
+
+	switch typ {
+	case geometryType:
+		return &GeometryCodec{desc.ID}, true, nil
+	case optionalGeometryType:
+		return &optionalGeometryDecoder{desc.ID}, true, nil
+	default:
+		return nil, true, fmt.Errorf(
+			"expected %v to be geltypes.Geometry or "+
+				"geltypes.OptionalGeometry got %v", path, typ,
+		)
+	}
+}
+
+// GeometryCodec encodes/decodes ext::postgis::geometry and
+// ext::postgis::geography values.
+type GeometryCodec struct {
+	id types.UUID
+}
+
+// Type returns the type the codec encodes/decodes
+func (c *GeometryCodec) Type() reflect.Type { return geometryType }
+
+// DescriptorID returns the codecs descriptor id.
+func (c *GeometryCodec) DescriptorID() types.UUID { return c.id }
+
+// Decode decodes a value
+func (c *GeometryCodec) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	err := (*types.Geometry)(out).UnmarshalBinary(r.Buf)
+	r.Discard(len(r.Buf))
+	return err
+}
+
+// Encode encodes a value
+func (c *GeometryCodec) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	switch in := val.(type) {
+	case types.Geometry:
+		return c.encodeData(w, in, path)
+	case types.OptionalGeometry:
+		data, ok := in.Get()
+		return encodeOptional(w, !ok, required,
+			func() error { return c.encodeData(w, data, path) },
+			func() error {
+				return missingValueError("geltypes.OptionalGeometry", path)
+			})
+	default:
+		return fmt.Errorf("expected %v to be geltypes.Geometry or "+
+			"geltypes.OptionalGeometry got %T", path, val)
+	}
+}
+
+func (c *GeometryCodec) encodeData(
+	w *buff.Writer,
+	data types.Geometry,
+	path Path,
+) error {
+	wkb, err := data.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("cannot encode %v: %w", path, err)
+	}
+
+	w.PushUint32(uint32(len(wkb))) // data length
+	w.PushBytes(wkb)
+	return nil
+}
+
+type optionalGeometryLayout struct {
+	val types.Geometry
+	set bool
+}
+
+type optionalGeometryDecoder struct {
+	id types.UUID
+}
+
+func (c *optionalGeometryDecoder) DescriptorID() types.UUID { return c.id }
+
+func (c *optionalGeometryDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	opgeometry := (*optionalGeometryLayout)(out)
+	err := opgeometry.val.UnmarshalBinary(r.Buf)
+	r.Discard(len(r.Buf))
+	if err != nil {
+		return err
+	}
+
+	opgeometry.set = true
+	return nil
+}
+
+func (c *optionalGeometryDecoder) DecodeMissing(out unsafe.Pointer) {
+	(*types.OptionalGeometry)(out).Unset()
+}
+
+func (c *optionalGeometryDecoder) DecodePresent(_ unsafe.Pointer) {}
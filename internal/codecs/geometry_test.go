@@ -0,0 +1,50 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeometryCodec(t *testing.T) {
+	desc := extensionDescriptor(GeographyName)
+	in := types.Geometry{SRID: 4326, Shape: types.Point{X: 1, Y: 2}}
+	data, out := roundTrip(t, desc, in, reflect.TypeOf(types.Geometry{}))
+	wkb, err := in.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, wkb, data)
+	assert.Equal(t, in, out)
+
+	_, out = roundTrip(t, desc, types.NewOptionalGeometry(in),
+		reflect.TypeOf(types.OptionalGeometry{}))
+	assert.Equal(t, types.NewOptionalGeometry(in), out)
+
+	encoder, err := BuildScalarEncoderV2(desc)
+	require.NoError(t, err)
+	err = encoder.Encode(nil, types.Geometry{}, Path("args[0]"), true)
+	assert.EqualError(t, err,
+		"cannot encode args[0]: geltypes.Geometry has no shape")
+
+	_, err = BuildDecoderV2(desc, reflect.TypeOf(""), Path("out"))
+	assert.EqualError(t, err, "expected out to be geltypes.Geometry or "+
+		"geltypes.OptionalGeometry got string")
+}
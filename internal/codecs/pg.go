@@ -0,0 +1,162 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	"github.com/geldata/gel-go/internal/descriptor"
+)
+
+// pgScalars maps the std::pg types that SQL queries return to the EdgeQL
+// types with the same wire format. std::pg::json is the exception, it is
+// sent as text without the format byte of std::json.
+var pgScalars = map[types.UUID]types.UUID{
+	PgJSONID:        JSONID,
+	PgTimestampTZID: DateTimeID,
+	PgTimestampID:   LocalDTID,
+	PgDateID:        LocalDateID,
+	PgIntervalID:    RelativeDurationID,
+}
+
+// pgEquivalent returns a copy of desc with the ID of the equivalent EdgeQL
+// type if desc is a std::pg type.
+func pgEquivalent(desc *descriptor.V2) (*descriptor.V2, bool) {
+	id, ok := pgScalars[desc.ID]
+	if !ok {
+		return nil, false
+	}
+
+	equivalent := *desc
+	equivalent.ID = id
+	return &equivalent, true
+}
+
+func buildPgEncoder(desc *descriptor.V2, equivalent Encoder) Encoder {
+	if desc.ID == PgJSONID {
+		return &pgJSONEncoder{equivalent, desc.ID}
+	}
+
+	return &pgEncoder{equivalent, desc.ID}
+}
+
+func buildPgDecoder(desc *descriptor.V2, equivalent Decoder) Decoder {
+	optional, isOptional := equivalent.(OptionalDecoder)
+	switch {
+	case desc.ID == PgJSONID && isOptional:
+		return &pgOptionalJSONDecoder{pgOptionalDecoder{optional, desc.ID}}
+	case desc.ID == PgJSONID:
+		return &pgJSONDecoder{pgDecoder{equivalent, desc.ID}}
+	case isOptional:
+		return &pgOptionalDecoder{optional, desc.ID}
+	default:
+		return &pgDecoder{equivalent, desc.ID}
+	}
+}
+
+// pgEncoder encodes a std::pg type with the encoder of the equivalent
+// EdgeQL type.
+type pgEncoder struct {
+	Encoder
+	id types.UUID
+}
+
+func (c *pgEncoder) DescriptorID() types.UUID { return c.id }
+
+// pgDecoder decodes a std::pg type with the decoder of the equivalent
+// EdgeQL type.
+type pgDecoder struct {
+	Decoder
+	id types.UUID
+}
+
+func (c *pgDecoder) DescriptorID() types.UUID { return c.id }
+
+type pgOptionalDecoder struct {
+	OptionalDecoder
+	id types.UUID
+}
+
+func (c *pgOptionalDecoder) DescriptorID() types.UUID { return c.id }
+
+// addJSONFormat returns a reader for the std::pg::json data in r with the
+// std::json format byte added.
+func addJSONFormat(r *buff.Reader) *buff.Reader {
+	data := make([]byte, 1+len(r.Buf))
+	data[0] = 1 // json format
+	copy(data[1:], r.Buf)
+	r.Discard(len(r.Buf))
+	return buff.SimpleReader(data)
+}
+
+type pgJSONDecoder struct {
+	pgDecoder
+}
+
+func (c *pgJSONDecoder) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	return c.Decoder.Decode(addJSONFormat(r), out)
+}
+
+type pgOptionalJSONDecoder struct {
+	pgOptionalDecoder
+}
+
+func (c *pgOptionalJSONDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	return c.OptionalDecoder.Decode(addJSONFormat(r), out)
+}
+
+// pgJSONEncoder removes the std::json format byte from data encoded by the
+// std::json encoder.
+type pgJSONEncoder struct {
+	Encoder
+	id types.UUID
+}
+
+func (c *pgJSONEncoder) DescriptorID() types.UUID { return c.id }
+
+func (c *pgJSONEncoder) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	tmp := buff.NewWriter(nil)
+	if err := c.Encoder.Encode(tmp, val, path, required); err != nil {
+		return err
+	}
+
+	data := tmp.Unwrap()
+	n := binary.BigEndian.Uint32(data)
+	switch {
+	case n == 0xffffffff: // missing value
+		w.PushUint32(n)
+	case n == 0 || data[4] != 1:
+		return fmt.Errorf("cannot encode %v: invalid json format", path)
+	default:
+		w.PushUint32(n - 1)
+		w.PushBytes(data[5:])
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	"github.com/geldata/gel-go/internal/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pgDescriptor(id types.UUID) *descriptor.V2 {
+	return &descriptor.V2{Type: descriptor.Scalar, ID: id}
+}
+
+func decodePg(t *testing.T, id types.UUID, typ reflect.Type, data []byte) any {
+	decoder, err := BuildDecoderV2(pgDescriptor(id), typ, Path("out"))
+	require.NoError(t, err)
+	assert.Equal(t, id, decoder.DescriptorID())
+
+	out := reflect.New(typ)
+	r := buff.SimpleReader(data)
+	require.NoError(t, decoder.Decode(r, unsafe.Pointer(out.Pointer())))
+	assert.Empty(t, r.Buf)
+	return out.Elem().Interface()
+}
+
+func encodePg(t *testing.T, id types.UUID, val any) []byte {
+	encoder, err := BuildScalarEncoderV2(pgDescriptor(id))
+	require.NoError(t, err)
+	assert.Equal(t, id, encoder.DescriptorID())
+
+	w := buff.NewWriter(nil)
+	require.NoError(t, encoder.Encode(w, val, Path("args"), true))
+	return w.Unwrap()
+}
+
+func TestPgTimestampTZ(t *testing.T) {
+	// one second after 2000-01-01T00:00:00Z
+	data := []byte{0, 0, 0, 0, 0, 0x0f, 0x42, 0x40}
+	expected := time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)
+
+	out := decodePg(t, PgTimestampTZID, dateTimeType, data)
+	assert.True(t, expected.Equal(out.(time.Time)))
+
+	out = decodePg(t, PgTimestampTZID, optionalDateTimeType, data)
+	val, ok := out.(types.OptionalDateTime).Get()
+	assert.True(t, ok)
+	assert.True(t, expected.Equal(val))
+
+	assert.Equal(t,
+		append([]byte{0, 0, 0, 8}, data...),
+		encodePg(t, PgTimestampTZID, expected))
+}
+
+func TestPgDateAndTimestamp(t *testing.T) {
+	out := decodePg(t, PgDateID, localDateType, []byte{0, 0, 0, 1})
+	assert.Equal(t, types.NewLocalDate(2000, 1, 2), out)
+
+	out = decodePg(t, PgTimestampID, localDateTimeType,
+		[]byte{0, 0, 0, 0, 0, 0x0f, 0x42, 0x40})
+	assert.Equal(t, types.NewLocalDateTime(2000, 1, 1, 0, 0, 1, 0), out)
+}
+
+func TestPgInterval(t *testing.T) {
+	data := []byte{
+		0, 0, 0, 0, 0, 0x0f, 0x42, 0x40, // microseconds
+		0, 0, 0, 2, // days
+		0, 0, 0, 3, // months
+	}
+
+	out := decodePg(t, PgIntervalID, relativeDurationType, data)
+	assert.Equal(t, types.NewRelativeDuration(3, 2, 1_000_000), out)
+}
+
+func TestPgJSON(t *testing.T) {
+	data := []byte(`{"a": 1}`)
+
+	out := decodePg(t, PgJSONID, bytesType, data)
+	assert.Equal(t, data, out)
+
+	out = decodePg(t, PgJSONID, optionalBytesType, data)
+	assert.Equal(t, types.NewOptionalBytes(data), out)
+
+	out = decodePg(t, PgJSONID, anyMapType, data)
+	assert.Equal(t, map[string]any{"a": float64(1)}, out)
+
+	encoded := encodePg(t, PgJSONID, data)
+	assert.Equal(t, append([]byte{0, 0, 0, 8}, data...), encoded)
+
+	encoder, err := BuildScalarEncoderV2(pgDescriptor(PgJSONID))
+	require.NoError(t, err)
+	w := buff.NewWriter(nil)
+	err = encoder.Encode(w, types.OptionalBytes{}, Path("args"), false)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff}, w.Unwrap())
+}
+
+func TestPgDefaultType(t *testing.T) {
+	typ, err := DefaultType(pgDescriptor(PgTimestampID))
+	require.NoError(t, err)
+	assert.Equal(t, localDateTimeType, typ)
+}
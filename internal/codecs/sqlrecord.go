@@ -0,0 +1,338 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	"github.com/geldata/gel-go/internal/descriptor"
+	"github.com/geldata/gel-go/internal/introspect"
+)
+
+var (
+	anyType      = reflect.TypeOf((*any)(nil)).Elem()
+	anyMapType   = reflect.TypeOf(map[string]any{})
+	anySliceType = reflect.TypeOf([]any{})
+
+	defaultScalarTypes = map[types.UUID]reflect.Type{
+		UUIDID:             uuidType,
+		StrID:              strType,
+		BytesID:            bytesType,
+		Int16ID:            int16Type,
+		Int32ID:            int32Type,
+		Int64ID:            int64Type,
+		Float32ID:          float32Type,
+		Float64ID:          float64Type,
+		BoolID:             boolType,
+		DateTimeID:         dateTimeType,
+		LocalDTID:          localDateTimeType,
+		LocalDateID:        localDateType,
+		LocalTimeID:        localTimeType,
+		DurationID:         durationType,
+		JSONID:             anyType,
+		BigIntID:           bigIntType,
+		RelativeDurationID: relativeDurationType,
+		DateDurationID:     dateDurationType,
+		MemoryID:           memoryType,
+		PgJSONID:           anyType,
+		PgTimestampTZID:    dateTimeType,
+		PgTimestampID:      localDateTimeType,
+		PgDateID:           localDateType,
+		PgIntervalID:       relativeDurationType,
+	}
+
+	defaultExtensionTypes = map[string]reflect.Type{
+		VectorName:       vectorType,
+		HalfVectorName:   halfVectorType,
+		SparseVectorName: sparseVectorType,
+		GeometryName:     geometryType,
+		GeographyName:    geometryType,
+	}
+)
+
+// DefaultType returns the go type that a value described by desc is decoded
+// into when the caller did not provide a more specific type.
+func DefaultType(desc *descriptor.V2) (reflect.Type, error) {
+	switch desc.Type {
+	case descriptor.Enum:
+		return strType, nil
+	case descriptor.BaseScalar, descriptor.Scalar:
+		scalar := GetScalarDescriptorV2(desc)
+		if typ, ok := defaultScalarTypes[scalar.ID]; ok {
+			return typ, nil
+		}
+		if typ, ok := defaultExtensionTypes[scalar.Name]; ok {
+			return typ, nil
+		}
+	case descriptor.Array:
+		elem, err := DefaultType(&desc.Fields[0].Desc)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	}
+
+	name := desc.Name
+	if name == "" {
+		name = desc.Type.String()
+	}
+
+	return nil, fmt.Errorf("no default go type for %v", name)
+}
+
+func buildSQLRecordDecoder(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	path Path,
+) (Decoder, error) {
+	switch typ {
+	case anyMapType:
+		return buildDynamicSQLRecordDecoder(desc, path, decodeIntoMap)
+	case anySliceType:
+		return buildDynamicSQLRecordDecoder(desc, path, decodeIntoSlice)
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf(
+			"expected %v to be a Struct, map[string]any or []any got %v",
+			path, typ,
+		)
+	}
+
+	fields := make([]*DecoderField, len(desc.Fields))
+
+	for i, field := range desc.Fields {
+		sf, ok := introspect.SQLColumnField(typ, field.Name)
+		if !ok {
+			return nil, fmt.Errorf(
+				"expected %v to have a field for column %q",
+				path, field.Name,
+			)
+		}
+
+		child, err := BuildDecoderV2(
+			&field.Desc,
+			sf.Type,
+			path.AddField(field.Name),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[i] = &DecoderField{
+			name:    field.Name,
+			offset:  sf.Offset,
+			decoder: child,
+		}
+	}
+
+	return &sqlRecordDecoder{desc.ID, fields, path}, nil
+}
+
+type sqlRecordDecoder struct {
+	id     types.UUID
+	fields []*DecoderField
+	path   Path
+}
+
+func (c *sqlRecordDecoder) DescriptorID() types.UUID { return c.id }
+
+func (c *sqlRecordDecoder) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	elmCount := int(r.PopUint32())
+	if elmCount != len(c.fields) {
+		return fmt.Errorf(
+			"wrong number of columns: expected %v, got %v",
+			len(c.fields), elmCount)
+	}
+
+	for _, field := range c.fields {
+		r.Discard(4) // reserved
+
+		p := pAdd(out, field.offset)
+		elmLen := r.PopUint32()
+		if elmLen != 0xffffffff {
+			err := field.decoder.Decode(r.PopSlice(elmLen), p)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		optional, ok := field.decoder.(OptionalDecoder)
+		if !ok {
+			return fmt.Errorf(
+				"cannot decode NULL into %v, use an optional type",
+				c.path.AddField(field.name),
+			)
+		}
+		optional.DecodeMissing(p)
+	}
+
+	return nil
+}
+
+// SQLColumn describes a column in the result of a SQL query.
+type SQLColumn struct {
+	// Name is the column name.
+	Name string
+
+	// TypeName is the name of the column's Gel type.
+	TypeName string
+
+	// Type is the go type that the column is decoded into.
+	Type reflect.Type
+}
+
+// SQLColumnDecoder is a Decoder for SQL records that decodes rows into
+// map[string]any or []any values.
+type SQLColumnDecoder interface {
+	Decoder
+	Columns() []SQLColumn
+}
+
+// dynamicColumn decodes a single column into a newly allocated value of typ.
+type dynamicColumn struct {
+	name     string
+	typeName string
+	typ      reflect.Type
+	decoder  Decoder
+}
+
+func (c *dynamicColumn) decode(r *buff.Reader) (any, error) {
+	elmLen := r.PopUint32()
+	if elmLen == 0xffffffff {
+		return nil, nil
+	}
+
+	val := reflect.New(c.typ)
+	err := c.decoder.Decode(
+		r.PopSlice(elmLen),
+		unsafe.Pointer(val.Pointer()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return val.Elem().Interface(), nil
+}
+
+type dynamicSQLRecordDecoder struct {
+	id      types.UUID
+	columns []*dynamicColumn
+	store   func(*dynamicSQLRecordDecoder, []any, unsafe.Pointer)
+}
+
+func buildDynamicSQLRecordDecoder(
+	desc *descriptor.V2,
+	path Path,
+	store func(*dynamicSQLRecordDecoder, []any, unsafe.Pointer),
+) (Decoder, error) {
+	columns := make([]*dynamicColumn, len(desc.Fields))
+
+	for i, field := range desc.Fields {
+		typ, err := DefaultType(&field.Desc)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"cannot decode %v: %w", path.AddField(field.Name), err)
+		}
+
+		child, err := BuildDecoderV2(
+			&field.Desc,
+			typ,
+			path.AddField(field.Name),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		columns[i] = &dynamicColumn{
+			name:     field.Name,
+			typeName: field.Desc.Name,
+			typ:      typ,
+			decoder:  child,
+		}
+	}
+
+	return &dynamicSQLRecordDecoder{desc.ID, columns, store}, nil
+}
+
+func (c *dynamicSQLRecordDecoder) DescriptorID() types.UUID { return c.id }
+
+// Columns returns the columns decoded by c.
+func (c *dynamicSQLRecordDecoder) Columns() []SQLColumn {
+	columns := make([]SQLColumn, len(c.columns))
+	for i, column := range c.columns {
+		columns[i] = SQLColumn{
+			Name:     column.name,
+			TypeName: column.typeName,
+			Type:     column.typ,
+		}
+	}
+
+	return columns
+}
+
+func (c *dynamicSQLRecordDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	elmCount := int(r.PopUint32())
+	if elmCount != len(c.columns) {
+		return fmt.Errorf(
+			"wrong number of columns: expected %v, got %v",
+			len(c.columns), elmCount)
+	}
+
+	values := make([]any, len(c.columns))
+	for i, column := range c.columns {
+		r.Discard(4) // reserved
+
+		val, err := column.decode(r)
+		if err != nil {
+			return err
+		}
+		values[i] = val
+	}
+
+	c.store(c, values, out)
+	return nil
+}
+
+func decodeIntoMap(
+	c *dynamicSQLRecordDecoder,
+	values []any,
+	out unsafe.Pointer,
+) {
+	row := make(map[string]any, len(values))
+	for i, column := range c.columns {
+		row[column.name] = values[i]
+	}
+
+	*(*map[string]any)(out) = row
+}
+
+func decodeIntoSlice(
+	_ *dynamicSQLRecordDecoder,
+	values []any,
+	out unsafe.Pointer,
+) {
+	*(*[]any)(out) = values
+}
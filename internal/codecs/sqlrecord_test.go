@@ -0,0 +1,142 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	"github.com/geldata/gel-go/internal/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSQLRecord = descriptor.V2{
+	Type: descriptor.SQLRecord,
+	ID:   types.UUID{1},
+	Fields: []*descriptor.FieldV2{
+		{
+			Name:     "user_id",
+			Desc:     descriptor.V2{Type: descriptor.Scalar, ID: Int64ID},
+			Required: true,
+		},
+		{
+			Name:     "Name",
+			Desc:     descriptor.V2{Type: descriptor.Scalar, ID: StrID},
+			Required: true,
+		},
+	},
+}
+
+// sqlRecordData encodes a record with an int64 and a nullable str column.
+func sqlRecordData(id int64, name *string) []byte {
+	w := buff.NewWriter(nil)
+	w.BeginMessage(0)
+	w.PushUint32(2) // element count
+	w.PushUint32(0) // reserved
+	w.PushUint32(8)
+	w.PushUint64(uint64(id))
+	w.PushUint32(0) // reserved
+	if name == nil {
+		w.PushUint32(0xffffffff)
+	} else {
+		w.PushString(*name)
+	}
+	w.EndMessage()
+
+	// strip the message type and length
+	return w.Unwrap()[5:]
+}
+
+func decodeSQLRecord(t *testing.T, typ reflect.Type, data []byte) any {
+	decoder, err := BuildDecoderV2(&testSQLRecord, typ, Path("row"))
+	require.NoError(t, err)
+
+	out := reflect.New(typ)
+	err = decoder.Decode(
+		buff.SimpleReader(data),
+		unsafe.Pointer(out.Pointer()),
+	)
+	require.NoError(t, err)
+	return out.Elem().Interface()
+}
+
+func TestSQLRecordStruct(t *testing.T) {
+	type row struct {
+		UserID int64
+		Name   types.OptionalStr `db:"name"`
+	}
+
+	name := "Alice"
+	result := decodeSQLRecord(
+		t, reflect.TypeOf(row{}), sqlRecordData(7, &name))
+	assert.Equal(t, row{7, types.NewOptionalStr("Alice")}, result)
+
+	result = decodeSQLRecord(t, reflect.TypeOf(row{}), sqlRecordData(8, nil))
+	assert.Equal(t, row{UserID: 8}, result)
+}
+
+func TestSQLRecordStructNullIntoRequired(t *testing.T) {
+	type row struct {
+		UserID int64
+		Name   string
+	}
+
+	decoder, err := BuildDecoderV2(
+		&testSQLRecord, reflect.TypeOf(row{}), Path("row"))
+	require.NoError(t, err)
+
+	var out row
+	err = decoder.Decode(
+		buff.SimpleReader(sqlRecordData(1, nil)),
+		unsafe.Pointer(&out),
+	)
+	assert.EqualError(t, err,
+		"cannot decode NULL into row.Name, use an optional type")
+}
+
+func TestSQLRecordStructMissingField(t *testing.T) {
+	type row struct {
+		UserID int64
+	}
+
+	_, err := BuildDecoderV2(
+		&testSQLRecord, reflect.TypeOf(row{}), Path("row"))
+	assert.EqualError(t, err,
+		`expected row to have a field for column "Name"`)
+}
+
+func TestSQLRecordMap(t *testing.T) {
+	name := "Bob"
+	result := decodeSQLRecord(
+		t, reflect.TypeOf(map[string]any{}), sqlRecordData(3, &name))
+	assert.Equal(t, map[string]any{"user_id": int64(3), "Name": "Bob"}, result)
+
+	result = decodeSQLRecord(
+		t, reflect.TypeOf(map[string]any{}), sqlRecordData(4, nil))
+	assert.Equal(t, map[string]any{"user_id": int64(4), "Name": nil}, result)
+}
+
+func TestSQLRecordSlice(t *testing.T) {
+	name := "Carol"
+	result := decodeSQLRecord(
+		t, reflect.TypeOf([]any{}), sqlRecordData(5, &name))
+	assert.Equal(t, []any{int64(5), "Carol"}, result)
+}
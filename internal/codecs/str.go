@@ -97,6 +97,27 @@ func (c *StrCodec) encodeMarshaler(
 	return nil
 }
 
+// enumEncoder encodes enum members. In addition to the values accepted by
+// StrCodec it accepts any named string type, like the enum types generated
+// by edgeql-go.
+type enumEncoder struct {
+	StrCodec
+}
+
+// Encode encodes an enum member.
+func (c *enumEncoder) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	if v := reflect.ValueOf(val); v.Kind() == reflect.String {
+		return c.encodeData(w, v.String())
+	}
+
+	return c.StrCodec.Encode(w, val, path, required)
+}
+
 type optionalStr struct {
 	val string
 	set bool
@@ -0,0 +1,45 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"reflect"
+	"testing"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/descriptor"
+	"github.com/stretchr/testify/assert"
+)
+
+type color string
+
+func TestEnumNamedStringType(t *testing.T) {
+	desc := &descriptor.V2{
+		Type:    descriptor.Enum,
+		ID:      types.UUID{1},
+		Name:    "default::Color",
+		Members: []string{"Red", "Green"},
+	}
+
+	data, out := roundTrip(t, desc, color("Green"), reflect.TypeOf(color("")))
+	assert.Equal(t, []byte("Green"), data)
+	assert.Equal(t, color("Green"), out)
+
+	data, out = roundTrip(t, desc, "Red", reflect.TypeOf(""))
+	assert.Equal(t, []byte("Red"), data)
+	assert.Equal(t, "Red", out)
+}
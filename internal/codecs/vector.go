@@ -0,0 +1,548 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	"github.com/geldata/gel-go/internal/descriptor"
+)
+
+// Names of the ext::pgvector types.
+const (
+	VectorName       = "ext::pgvector::vector"
+	HalfVectorName   = "ext::pgvector::halfvec"
+	SparseVectorName = "ext::pgvector::sparsevec"
+)
+
+var (
+	vectorType               = reflect.TypeOf(types.Vector{})
+	halfVectorType           = reflect.TypeOf(types.HalfVector{})
+	sparseVectorType         = reflect.TypeOf(types.SparseVector{})
+	float32SliceType         = reflect.TypeOf([]float32{})
+	optionalVectorType       = reflect.TypeOf(types.OptionalVector{})
+	optionalHalfVectorType   = reflect.TypeOf(types.OptionalHalfVector{})
+	optionalSparseVectorType = reflect.TypeOf(types.OptionalSparseVector{})
+)
+
+func buildVectorEncoder(desc *descriptor.V2) (Encoder, bool) {
+	switch desc.Name {
+	case VectorName:
+		return &VectorCodec{desc.ID}, true
+	case HalfVectorName:
+		return &HalfVectorCodec{desc.ID}, true
+	case SparseVectorName:
+		return &SparseVectorCodec{desc.ID}, true
+	default:
+		return nil, false
+	}
+}
+
+func buildVectorDecoder(
+	desc *descriptor.V2,
+	typ reflect.Type,
+	path Path,
+) (Decoder, bool, error) {
+	var expectedType string
+
+	switch desc.Name {
+	case VectorName:
+		switch typ {
+		case vectorType, float32SliceType:
+			return &VectorCodec{desc.ID}, true, nil
+		case optionalVectorType:
+			return &optionalVectorDecoder{desc.ID}, true, nil
+		default:
+			expectedType = "geltypes.Vector or geltypes.OptionalVector"
+		}
+	case HalfVectorName:
+		switch typ {
+		case halfVectorType, float32SliceType:
+			return &HalfVectorCodec{desc.ID}, true, nil
+		case optionalHalfVectorType:
+			return &optionalHalfVectorDecoder{desc.ID}, true, nil
+		default:
+			expectedType = "geltypes.HalfVector or " +
+				"geltypes.OptionalHalfVector"
+		}
+	case SparseVectorName:
+		switch typ {
+		case sparseVectorType:
+			return &SparseVectorCodec{desc.ID}, true, nil
+		case optionalSparseVectorType:
+			return &optionalSparseVectorDecoder{desc.ID}, true, nil
+		default:
+			expectedType = "geltypes.SparseVector or " +
+				"geltypes.OptionalSparseVector"
+		}
+	default:
+		return nil, false, nil
+	}
+
+	return nil, true, fmt.Errorf(
+		"expected %v to be %v got %v", path, expectedType, typ,
+	)
+}
+
+// decodeVectorHeader reads the dimension of a vector or half vector and
+// checks that the data has the expected length.
+func decodeVectorHeader(r *buff.Reader, width int) (int, error) {
+	if len(r.Buf) < 4 {
+		return 0, fmt.Errorf("invalid vector data length %v", len(r.Buf))
+	}
+
+	dim := int(r.PopUint16())
+	r.PopUint16() // reserved
+
+	if len(r.Buf) != dim*width {
+		return 0, fmt.Errorf(
+			"invalid vector data: %v dimensions in %v bytes",
+			dim, len(r.Buf))
+	}
+
+	return dim, nil
+}
+
+func decodeVector(r *buff.Reader) ([]float32, error) {
+	dim, err := decodeVectorHeader(r, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = math.Float32frombits(r.PopUint32())
+	}
+
+	return v, nil
+}
+
+func encodeVectorHeader(w *buff.Writer, dim int, width int) error {
+	if dim > math.MaxUint16 {
+		return fmt.Errorf("vector has %v dimensions, the maximum is %v",
+			dim, math.MaxUint16)
+	}
+
+	w.PushUint32(uint32(4 + dim*width)) // data length
+	w.PushUint16(uint16(dim))
+	w.PushUint16(0) // reserved
+	return nil
+}
+
+// VectorCodec encodes/decodes ext::pgvector::vector values.
+type VectorCodec struct {
+	id types.UUID
+}
+
+// Type returns the type the codec encodes/decodes
+func (c *VectorCodec) Type() reflect.Type { return vectorType }
+
+// DescriptorID returns the codecs descriptor id.
+func (c *VectorCodec) DescriptorID() types.UUID { return c.id }
+
+// Decode decodes a value
+func (c *VectorCodec) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	v, err := decodeVector(r)
+	if err != nil {
+		return err
+	}
+
+	*(*[]float32)(out) = v
+	return nil
+}
+
+// Encode encodes a value
+func (c *VectorCodec) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	switch in := val.(type) {
+	case types.Vector:
+		return c.encodeData(w, in)
+	case []float32:
+		return c.encodeData(w, in)
+	case types.OptionalVector:
+		data, ok := in.Get()
+		return encodeOptional(w, !ok, required,
+			func() error { return c.encodeData(w, data) },
+			func() error {
+				return missingValueError("geltypes.OptionalVector", path)
+			})
+	default:
+		return fmt.Errorf("expected %v to be geltypes.Vector, "+
+			"geltypes.OptionalVector or []float32 got %T", path, val)
+	}
+}
+
+func (c *VectorCodec) encodeData(w *buff.Writer, data []float32) error {
+	if err := encodeVectorHeader(w, len(data), 4); err != nil {
+		return err
+	}
+
+	for _, val := range data {
+		w.PushUint32(math.Float32bits(val))
+	}
+
+	return nil
+}
+
+type optionalVectorLayout struct {
+	val []float32
+	set bool
+}
+
+type optionalVectorDecoder struct {
+	id types.UUID
+}
+
+func (c *optionalVectorDecoder) DescriptorID() types.UUID { return c.id }
+
+func (c *optionalVectorDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	v, err := decodeVector(r)
+	if err != nil {
+		return err
+	}
+
+	opvector := (*optionalVectorLayout)(out)
+	opvector.val = v
+	opvector.set = true
+	return nil
+}
+
+func (c *optionalVectorDecoder) DecodeMissing(out unsafe.Pointer) {
+	(*types.OptionalVector)(out).Unset()
+}
+
+func (c *optionalVectorDecoder) DecodePresent(_ unsafe.Pointer) {}
+
+func decodeHalfVector(r *buff.Reader) ([]float32, error) {
+	dim, err := decodeVectorHeader(r, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = halfToFloat32(r.PopUint16())
+	}
+
+	return v, nil
+}
+
+// HalfVectorCodec encodes/decodes ext::pgvector::halfvec values.
+type HalfVectorCodec struct {
+	id types.UUID
+}
+
+// Type returns the type the codec encodes/decodes
+func (c *HalfVectorCodec) Type() reflect.Type { return halfVectorType }
+
+// DescriptorID returns the codecs descriptor id.
+func (c *HalfVectorCodec) DescriptorID() types.UUID { return c.id }
+
+// Decode decodes a value
+func (c *HalfVectorCodec) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	v, err := decodeHalfVector(r)
+	if err != nil {
+		return err
+	}
+
+	*(*[]float32)(out) = v
+	return nil
+}
+
+// Encode encodes a value
+func (c *HalfVectorCodec) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	switch in := val.(type) {
+	case types.HalfVector:
+		return c.encodeData(w, in)
+	case []float32:
+		return c.encodeData(w, in)
+	case types.OptionalHalfVector:
+		data, ok := in.Get()
+		return encodeOptional(w, !ok, required,
+			func() error { return c.encodeData(w, data) },
+			func() error {
+				return missingValueError(
+					"geltypes.OptionalHalfVector", path)
+			})
+	default:
+		return fmt.Errorf("expected %v to be geltypes.HalfVector, "+
+			"geltypes.OptionalHalfVector or []float32 got %T", path, val)
+	}
+}
+
+func (c *HalfVectorCodec) encodeData(w *buff.Writer, data []float32) error {
+	if err := encodeVectorHeader(w, len(data), 2); err != nil {
+		return err
+	}
+
+	for _, val := range data {
+		w.PushUint16(float32ToHalf(val))
+	}
+
+	return nil
+}
+
+type optionalHalfVectorDecoder struct {
+	id types.UUID
+}
+
+func (c *optionalHalfVectorDecoder) DescriptorID() types.UUID { return c.id }
+
+func (c *optionalHalfVectorDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	v, err := decodeHalfVector(r)
+	if err != nil {
+		return err
+	}
+
+	opvector := (*optionalVectorLayout)(out)
+	opvector.val = v
+	opvector.set = true
+	return nil
+}
+
+func (c *optionalHalfVectorDecoder) DecodeMissing(out unsafe.Pointer) {
+	(*types.OptionalHalfVector)(out).Unset()
+}
+
+func (c *optionalHalfVectorDecoder) DecodePresent(_ unsafe.Pointer) {}
+
+// halfToFloat32 converts an IEEE 754 half precision float.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h) & 0x3ff
+
+	switch {
+	case exp == 0x1f: // inf or nan
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case exp == 0 && mant == 0:
+		return math.Float32frombits(sign)
+	case exp == 0: // subnormal
+		exp = 127 - 14
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		mant &= 0x3ff
+		return math.Float32frombits(sign | exp<<23 | mant<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	}
+}
+
+// float32ToHalf converts f to an IEEE 754 half precision float rounding to
+// the nearest even value.
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	if exp == 0xff { // inf or nan
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+
+	exp = exp - 127 + 15
+	switch {
+	case exp >= 0x1f: // overflow
+		return sign | 0x7c00
+	case exp < -10: // underflow
+		return sign
+	case exp <= 0: // subnormal
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		return sign | uint16(roundEven(mant, shift))
+	default:
+		// A carry out of the mantissa correctly increments the exponent.
+		return sign | uint16(uint32(exp)<<10+roundEven(mant, 13))
+	}
+}
+
+// roundEven returns v >> shift rounded to the nearest even value.
+func roundEven(v uint32, shift uint) uint32 {
+	result := v >> shift
+	rem := v & (1<<shift - 1)
+	half := uint32(1) << (shift - 1)
+	if rem > half || (rem == half && result&1 == 1) {
+		result++
+	}
+
+	return result
+}
+
+func decodeSparseVector(r *buff.Reader) (types.SparseVector, error) {
+	if len(r.Buf) < 12 {
+		return types.SparseVector{}, fmt.Errorf(
+			"invalid sparse vector data length %v", len(r.Buf))
+	}
+
+	dim := int32(r.PopUint32())
+	nnz := int(r.PopUint32())
+	r.PopUint32() // reserved
+
+	if len(r.Buf) != nnz*8 {
+		return types.SparseVector{}, fmt.Errorf(
+			"invalid sparse vector data: %v elements in %v bytes",
+			nnz, len(r.Buf))
+	}
+
+	v := types.SparseVector{
+		Dim:     dim,
+		Indices: make([]int32, nnz),
+		Values:  make([]float32, nnz),
+	}
+
+	for i := range v.Indices {
+		v.Indices[i] = int32(r.PopUint32())
+	}
+
+	for i := range v.Values {
+		v.Values[i] = math.Float32frombits(r.PopUint32())
+	}
+
+	return v, nil
+}
+
+// SparseVectorCodec encodes/decodes ext::pgvector::sparsevec values.
+type SparseVectorCodec struct {
+	id types.UUID
+}
+
+// Type returns the type the codec encodes/decodes
+func (c *SparseVectorCodec) Type() reflect.Type { return sparseVectorType }
+
+// DescriptorID returns the codecs descriptor id.
+func (c *SparseVectorCodec) DescriptorID() types.UUID { return c.id }
+
+// Decode decodes a value
+func (c *SparseVectorCodec) Decode(r *buff.Reader, out unsafe.Pointer) error {
+	v, err := decodeSparseVector(r)
+	if err != nil {
+		return err
+	}
+
+	*(*types.SparseVector)(out) = v
+	return nil
+}
+
+// Encode encodes a value
+func (c *SparseVectorCodec) Encode(
+	w *buff.Writer,
+	val interface{},
+	path Path,
+	required bool,
+) error {
+	switch in := val.(type) {
+	case types.SparseVector:
+		return c.encodeData(w, in, path)
+	case types.OptionalSparseVector:
+		data, ok := in.Get()
+		return encodeOptional(w, !ok, required,
+			func() error { return c.encodeData(w, data, path) },
+			func() error {
+				return missingValueError(
+					"geltypes.OptionalSparseVector", path)
+			})
+	default:
+		return fmt.Errorf("expected %v to be geltypes.SparseVector or "+
+			"geltypes.OptionalSparseVector got %T", path, val)
+	}
+}
+
+func (c *SparseVectorCodec) encodeData(
+	w *buff.Writer,
+	data types.SparseVector,
+	path Path,
+) error {
+	if len(data.Indices) != len(data.Values) {
+		return fmt.Errorf(
+			"cannot encode %v: %v indices but %v values",
+			path, len(data.Indices), len(data.Values))
+	}
+
+	w.PushUint32(uint32(12 + 8*len(data.Values))) // data length
+	w.PushUint32(uint32(data.Dim))
+	w.PushUint32(uint32(len(data.Values)))
+	w.PushUint32(0) // reserved
+
+	for _, idx := range data.Indices {
+		w.PushUint32(uint32(idx))
+	}
+
+	for _, val := range data.Values {
+		w.PushUint32(math.Float32bits(val))
+	}
+
+	return nil
+}
+
+type optionalSparseVectorLayout struct {
+	val types.SparseVector
+	set bool
+}
+
+type optionalSparseVectorDecoder struct {
+	id types.UUID
+}
+
+func (c *optionalSparseVectorDecoder) DescriptorID() types.UUID {
+	return c.id
+}
+
+func (c *optionalSparseVectorDecoder) Decode(
+	r *buff.Reader,
+	out unsafe.Pointer,
+) error {
+	v, err := decodeSparseVector(r)
+	if err != nil {
+		return err
+	}
+
+	opvector := (*optionalSparseVectorLayout)(out)
+	opvector.val = v
+	opvector.set = true
+	return nil
+}
+
+func (c *optionalSparseVectorDecoder) DecodeMissing(out unsafe.Pointer) {
+	(*types.OptionalSparseVector)(out).Unset()
+}
+
+func (c *optionalSparseVectorDecoder) DecodePresent(_ unsafe.Pointer) {}
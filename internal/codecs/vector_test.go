@@ -0,0 +1,177 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	types "github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	"github.com/geldata/gel-go/internal/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// extensionDescriptor describes a user defined scalar extending an
+// extension type.
+func extensionDescriptor(name string) *descriptor.V2 {
+	return &descriptor.V2{
+		Type: descriptor.Scalar,
+		ID:   types.UUID{2},
+		Name: "default::embedding",
+		Ancestors: []*descriptor.FieldV2{{
+			Desc: descriptor.V2{
+				Type: descriptor.Scalar,
+				ID:   types.UUID{1},
+				Name: name,
+			},
+		}},
+	}
+}
+
+// roundTrip encodes in and decodes the data into a new value of typ.
+func roundTrip(
+	t *testing.T,
+	desc *descriptor.V2,
+	in any,
+	typ reflect.Type,
+) ([]byte, any) {
+	encoder, err := BuildScalarEncoderV2(desc)
+	require.NoError(t, err)
+	assert.Equal(t, types.UUID{1}, encoder.DescriptorID())
+
+	w := buff.NewWriter(nil)
+	require.NoError(t, encoder.Encode(w, in, Path("args"), true))
+	data := w.Unwrap()[4:] // strip the data length
+
+	decoder, err := BuildDecoderV2(desc, typ, Path("out"))
+	require.NoError(t, err)
+
+	out := reflect.New(typ)
+	r := buff.SimpleReader(data)
+	require.NoError(t, decoder.Decode(r, unsafe.Pointer(out.Pointer())))
+	assert.Empty(t, r.Buf)
+	return data, out.Elem().Interface()
+}
+
+func TestVectorCodec(t *testing.T) {
+	desc := extensionDescriptor(VectorName)
+	data, out := roundTrip(t, desc, types.Vector{1, -2.5},
+		reflect.TypeOf(types.Vector{}))
+	assert.Equal(t, []byte{
+		0, 2, 0, 0,
+		0x3f, 0x80, 0, 0,
+		0xc0, 0x20, 0, 0,
+	}, data)
+	assert.Equal(t, types.Vector{1, -2.5}, out)
+
+	_, out = roundTrip(t, desc, []float32{3},
+		reflect.TypeOf(types.OptionalVector{}))
+	assert.Equal(t, types.NewOptionalVector(types.Vector{3}), out)
+
+	_, out = roundTrip(t, desc, types.NewOptionalVector(types.Vector{4}),
+		reflect.TypeOf([]float32{}))
+	assert.Equal(t, []float32{4}, out)
+
+	_, err := BuildDecoderV2(desc, reflect.TypeOf(""), Path("out"))
+	assert.EqualError(t, err, "expected out to be geltypes.Vector or "+
+		"geltypes.OptionalVector got string")
+}
+
+func TestHalfVectorCodec(t *testing.T) {
+	desc := extensionDescriptor(HalfVectorName)
+	data, out := roundTrip(t, desc, types.HalfVector{1, -2.5, 0.1},
+		reflect.TypeOf(types.HalfVector{}))
+	assert.Equal(t, []byte{0, 3, 0, 0, 0x3c, 0, 0xc1, 0, 0x2e, 0x66}, data)
+	assert.Equal(t, types.HalfVector{1, -2.5, 0.099975586}, out)
+
+	_, out = roundTrip(t, desc, types.HalfVector{},
+		reflect.TypeOf(types.OptionalHalfVector{}))
+	assert.Equal(t, types.NewOptionalHalfVector(types.HalfVector{}), out)
+}
+
+func TestSparseVectorCodec(t *testing.T) {
+	desc := extensionDescriptor(SparseVectorName)
+	in := types.NewSparseVector([]float32{0, 0, 1.5})
+	data, out := roundTrip(t, desc, in,
+		reflect.TypeOf(types.SparseVector{}))
+	assert.Equal(t, []byte{
+		0, 0, 0, 3,
+		0, 0, 0, 1,
+		0, 0, 0, 0,
+		0, 0, 0, 2,
+		0x3f, 0xc0, 0, 0,
+	}, data)
+	assert.Equal(t, in, out)
+
+	_, out = roundTrip(t, desc, types.NewOptionalSparseVector(in),
+		reflect.TypeOf(types.OptionalSparseVector{}))
+	assert.Equal(t, types.NewOptionalSparseVector(in), out)
+
+	encoder, err := BuildScalarEncoderV2(desc)
+	require.NoError(t, err)
+	err = encoder.Encode(buff.NewWriter(nil), types.SparseVector{
+		Dim:     3,
+		Indices: []int32{1},
+	}, Path("args[0]"), true)
+	assert.EqualError(t, err,
+		"cannot encode args[0]: 1 indices but 0 values")
+}
+
+func TestVectorDefaultType(t *testing.T) {
+	typ, err := DefaultType(extensionDescriptor(SparseVectorName))
+	require.NoError(t, err)
+	assert.Equal(t, reflect.TypeOf(types.SparseVector{}), typ)
+}
+
+func TestHalfFloat(t *testing.T) {
+	cases := []struct {
+		f    float32
+		half uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{65504, 0x7bff},
+		{float32(math.Inf(1)), 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+		{0x1p-14, 0x0400}, // smallest normal
+		{0x1p-24, 0x0001}, // smallest subnormal
+		{0x1p-15, 0x0200}, // subnormal
+		{0.33325195, 0x3555},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.half, float32ToHalf(c.f), "%v", c.f)
+		assert.Equal(t, c.f, halfToFloat32(c.half), "%#04x", c.half)
+	}
+
+	// rounding
+	assert.Equal(t, uint16(0x7c00), float32ToHalf(65520))   // overflow
+	assert.Equal(t, uint16(0x0000), float32ToHalf(0x1p-26)) // underflow
+	assert.Equal(t, uint16(0x0001), float32ToHalf(0x1.8p-25))
+	assert.Equal(t, uint16(0x3c00), float32ToHalf(1+0x1p-11))   // tie, even
+	assert.Equal(t, uint16(0x3c02), float32ToHalf(1+0x3p-11))   // tie, even
+	assert.Equal(t, uint16(0x3c01), float32ToHalf(1+0x1.8p-11)) // nearest
+
+	nan := float32ToHalf(float32(math.NaN()))
+	assert.True(t, math.IsNaN(float64(halfToFloat32(nan))))
+}
@@ -28,20 +28,23 @@ import (
 // V2 is a type descriptor
 // https://docs.geldata.com/reference/reference/protocol/typedesc
 type V2 struct {
-	Type          Type
-	ID            geltypes.UUID
-	Name          string
-	SchemaDefined bool
-	Ancestors     []*FieldV2
-	Fields        []*FieldV2
+	Type          Type          `json:"type"`
+	ID            geltypes.UUID `json:"id"`
+	Name          string        `json:"name,omitempty"`
+	SchemaDefined bool          `json:"schema_defined,omitempty"`
+	Ancestors     []*FieldV2    `json:"ancestors,omitempty"`
+	Fields        []*FieldV2    `json:"fields,omitempty"`
+
+	// Members holds the member names of an enum in schema order.
+	Members []string `json:"members,omitempty"`
 }
 
 // FieldV2 represents the child of a descriptor
 type FieldV2 struct {
-	Name     string
-	Desc     V2
-	Required bool
-	Union    bool
+	Name     string `json:"name,omitempty"`
+	Desc     V2     `json:"desc"`
+	Required bool   `json:"required,omitempty"`
+	Union    bool   `json:"union,omitempty"`
 }
 
 // PopV2 builds a descriptor tree from a describe statement type description.
@@ -65,7 +68,7 @@ func PopV2(
 			fields := []*FieldV2{{
 				Desc: descriptorsV2[r.PopUint16()],
 			}}
-			desc = V2{Set, id, "", false, nil, fields}
+			desc = V2{Set, id, "", false, nil, fields, nil}
 		case Object:
 			r.PopUint8()  // schema_defined
 			r.PopUint16() // type
@@ -73,22 +76,22 @@ func PopV2(
 			if err != nil {
 				return V2{}, err
 			}
-			desc = V2{Object, id, "", true, nil, fields}
+			desc = V2{Object, id, "", true, nil, fields, nil}
 		case Scalar:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
 			ancestors := scalarFields2pX(r, descriptorsV2, false)
-			desc = V2{Scalar, id, name, true, ancestors, nil}
+			desc = V2{Scalar, id, name, true, ancestors, nil, nil}
 		case Tuple:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
 			ancestors, fields := tupleFields2pX(r, descriptorsV2)
-			desc = V2{Tuple, id, name, true, ancestors, fields}
+			desc = V2{Tuple, id, name, true, ancestors, fields, nil}
 		case NamedTuple:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
 			ancestors, fields := namedTupleFields2pX(r, descriptorsV2)
-			desc = V2{Tuple, id, name, true, ancestors, fields}
+			desc = V2{Tuple, id, name, true, ancestors, fields, nil}
 		case Array:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
@@ -100,19 +103,19 @@ func PopV2(
 			if err != nil {
 				return V2{}, err
 			}
-			desc = V2{Array, id, name, true, ancestors, fields}
+			desc = V2{Array, id, name, true, ancestors, fields, nil}
 		case Enum:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
 			ancestors := scalarFields2pX(r, descriptorsV2, false)
-			discardEnumMemberNames(r)
-			desc = V2{Enum, id, name, true, ancestors, nil}
+			members := enumMemberNames(r)
+			desc = V2{Enum, id, name, true, ancestors, nil, members}
 		case InputShape:
 			fields, err := objectFields2pX(r, descriptorsV2, true)
 			if err != nil {
 				return V2{}, err
 			}
-			desc = V2{InputShape, id, "", true, nil, fields}
+			desc = V2{InputShape, id, "", true, nil, fields, nil}
 		case Range:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
@@ -120,11 +123,11 @@ func PopV2(
 			fields := []*FieldV2{{
 				Desc: descriptorsV2[r.PopUint16()],
 			}}
-			desc = V2{Range, id, name, true, ancestors, fields}
+			desc = V2{Range, id, name, true, ancestors, fields, nil}
 		case ObjectShape:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
-			desc = V2{ObjectShape, id, name, true, nil, nil}
+			desc = V2{ObjectShape, id, name, true, nil, nil, nil}
 		case Compound:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
@@ -139,7 +142,7 @@ func PopV2(
 				return V2{}, fmt.Errorf("unexpected operation type: %v", t)
 			}
 			fields := scalarFields2pX(r, descriptorsV2, unionOperation)
-			desc = V2{Compound, id, name, true, nil, fields}
+			desc = V2{Compound, id, name, true, nil, fields, nil}
 		case MultiRange:
 			name := r.PopString()
 			r.PopUint8() // schema_defined
@@ -152,10 +155,10 @@ func PopV2(
 					}},
 				},
 			}}
-			desc = V2{MultiRange, id, name, true, ancestors, fields}
+			desc = V2{MultiRange, id, name, true, ancestors, fields, nil}
 		case SQLRecord:
 			fields := sqlRecordFields(r, descriptorsV2)
-			desc = V2{SQLRecord, id, "", false, nil, fields}
+			desc = V2{SQLRecord, id, "", false, nil, fields, nil}
 		default:
 			if 0x80 <= typ {
 				// ignore unknown type annotations
@@ -285,3 +288,13 @@ func sqlRecordFields(
 
 	return fields
 }
+
+func enumMemberNames(r *buff.Reader) []string {
+	n := int(r.PopUint16())
+	members := make([]string, n)
+	for i := 0; i < n; i++ {
+		members[i] = r.PopString()
+	}
+
+	return members
+}
@@ -0,0 +1,569 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeserver
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/geldata/gel-go/internal"
+	"github.com/geldata/gel-go/internal/buff"
+	gel "github.com/geldata/gel-go/internal/client"
+	"github.com/geldata/gel-go/internal/codecs"
+	"github.com/geldata/gel-go/internal/descriptor"
+	"github.com/xdg/scram"
+)
+
+// Error codes used by the server.
+const (
+	protocolErrorCode         = 0x03_00_00_00
+	parameterTypeMismatchCode = 0x03_02_01_00
+	transactionErrorCode      = 0x05_03_00_00
+	authenticationErrorCode   = 0x07_01_00_00
+)
+
+// Transaction states sent in ReadyForCommand.
+const (
+	idle     = 'I'
+	inTx     = 'T'
+	failedTx = 'E'
+)
+
+// errClosed stops serving a connection.
+var errClosed = errors.New("connection closed")
+
+type message struct {
+	typ gel.Message
+	r   *buff.Reader
+}
+
+type conn struct {
+	net.Conn
+	server  *Server
+	version internal.ProtocolVersion
+	status  byte
+
+	// out holds the messages that are written on the next flush.
+	out   []byte
+	fault Fault
+
+	// skip is set after an error until the next Sync.
+	skip bool
+}
+
+func (c *conn) serve() {
+	defer func() { _ = c.Close() }()
+
+	c.status = idle
+	if err := c.handshake(); err != nil {
+		return
+	}
+
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return
+		}
+
+		if err := c.handle(msg); err != nil {
+			return
+		}
+	}
+}
+
+// read reads the next message.
+func (c *conn) read() (message, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(c, header); err != nil {
+		return message{}, err
+	}
+
+	n := int(binary.BigEndian.Uint32(header[1:])) - 4
+	if n < 0 {
+		return message{}, fmt.Errorf("invalid message length %v", n)
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c, payload); err != nil {
+		return message{}, err
+	}
+
+	return message{
+		typ: gel.Message(header[0]),
+		r:   buff.SimpleReader(payload),
+	}, nil
+}
+
+// decode calls fn and reports a malformed message instead of panicking.
+func decode(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed message: %v", r)
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+// write queues a message.
+func (c *conn) write(typ gel.Message, body func(w *buff.Writer)) {
+	w := buff.NewWriter(nil)
+	w.BeginMessage(uint8(typ))
+	body(w)
+	w.EndMessage()
+	c.out = append(c.out, w.Unwrap()...)
+}
+
+// flush writes the queued messages, injecting the current fault.
+func (c *conn) flush() error {
+	out, fault := c.out, c.fault
+	c.out, c.fault = nil, Fault{}
+
+	time.Sleep(fault.Delay)
+
+	if fault.Disconnect {
+		if fault.DisconnectAfter < len(out) {
+			out = out[:fault.DisconnectAfter]
+		}
+	}
+
+	chunk := fault.ChunkSize
+	if chunk <= 0 {
+		chunk = len(out)
+	}
+
+	for i := 0; i < len(out); i += chunk {
+		if i > 0 {
+			time.Sleep(fault.ChunkDelay)
+		}
+
+		end := min(i+chunk, len(out))
+		if _, err := c.Write(out[i:end]); err != nil {
+			return err
+		}
+	}
+
+	if fault.Disconnect {
+		return errClosed
+	}
+
+	return nil
+}
+
+func (c *conn) writeError(code uint32, msg string) {
+	c.write(gel.ErrorResponse, func(w *buff.Writer) {
+		w.PushUint8(0x78) // severity ERROR
+		w.PushUint32(code)
+		w.PushString(msg)
+		w.PushUint16(0) // attributes
+	})
+}
+
+// fail sends an error and closes the connection.
+func (c *conn) fail(code uint32, msg string) error {
+	c.writeError(code, msg)
+	_ = c.flush()
+	return errClosed
+}
+
+func (c *conn) handshake() error {
+	msg, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	if msg.typ != gel.ClientHandshake {
+		return c.fail(protocolErrorCode, fmt.Sprintf(
+			"expected ClientHandshake got 0x%x", msg.typ))
+	}
+
+	var (
+		version internal.ProtocolVersion
+		params  = make(map[string]string)
+	)
+	err = decode(func() {
+		version.Major = msg.r.PopUint16()
+		version.Minor = msg.r.PopUint16()
+		n := int(msg.r.PopUint16())
+		for i := 0; i < n; i++ {
+			params[msg.r.PopString()] = msg.r.PopString()
+		}
+	})
+	if err != nil {
+		return c.fail(protocolErrorCode, err.Error())
+	}
+
+	c.version = c.server.opts.ProtocolVersion
+	if version != c.version {
+		c.write(gel.ServerHandshake, func(w *buff.Writer) {
+			w.PushUint16(c.version.Major)
+			w.PushUint16(c.version.Minor)
+			w.PushUint16(0) // extensions
+		})
+	}
+
+	if params["user"] != c.server.opts.User {
+		return c.fail(authenticationErrorCode, fmt.Sprintf(
+			"authentication failed for user %q", params["user"]))
+	}
+
+	if c.server.opts.Password != "" {
+		if err := c.authenticate(params["user"]); err != nil {
+			return err
+		}
+	} else {
+		c.writeAuthentication(0, nil)
+	}
+
+	c.write(gel.ServerKeyData, func(w *buff.Writer) {
+		w.PushUUID(descriptor.IDZero)
+		w.PushUUID(descriptor.IDZero)
+	})
+
+	if n := c.server.opts.PoolConcurrency; n > 0 {
+		c.write(gel.ParameterStatus, func(w *buff.Writer) {
+			w.PushString("suggested_pool_concurrency")
+			w.PushString(strconv.Itoa(n))
+		})
+	}
+
+	c.write(gel.StateDataDescription, func(w *buff.Writer) {
+		w.PushUUID(c.server.opts.State.ID)
+		pushBytes(w, c.server.opts.State.Data)
+	})
+
+	c.writeReadyForCommand()
+	return c.flush()
+}
+
+func (c *conn) writeAuthentication(status uint32, data []byte) {
+	c.write(gel.Authentication, func(w *buff.Writer) {
+		w.PushUint32(status)
+		if data != nil {
+			pushBytes(w, data)
+		}
+	})
+}
+
+// authenticate runs a SCRAM-SHA-256 conversation.
+func (c *conn) authenticate(user string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	client, err := scram.SHA256.NewClient(
+		c.server.opts.User,
+		c.server.opts.Password,
+		"",
+	)
+	if err != nil {
+		return err
+	}
+
+	credentials := client.GetStoredCredentials(scram.KeyFactors{
+		Salt:  string(salt),
+		Iters: 4096,
+	})
+	server, err := scram.SHA256.NewServer(
+		func(string) (scram.StoredCredentials, error) {
+			return credentials, nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	c.write(gel.Authentication, func(w *buff.Writer) {
+		w.PushUint32(0x0a) // SASL
+		w.PushUint32(1)
+		w.PushString("SCRAM-SHA-256")
+	})
+	if err := c.flush(); err != nil {
+		return err
+	}
+
+	conv := server.NewConversation()
+	for _, typ := range []gel.Message{
+		gel.AuthenticationSASLInitialResponse,
+		gel.AuthenticationSASLResponse,
+	} {
+		msg, err := c.read()
+		if err != nil {
+			return err
+		}
+
+		if msg.typ != typ {
+			return c.fail(protocolErrorCode, fmt.Sprintf(
+				"expected 0x%x got 0x%x", typ, msg.typ))
+		}
+
+		var data string
+		err = decode(func() {
+			if typ == gel.AuthenticationSASLInitialResponse {
+				msg.r.PopString() // method
+			}
+			data = msg.r.PopString()
+		})
+		if err != nil {
+			return c.fail(protocolErrorCode, err.Error())
+		}
+
+		reply, err := conv.Step(data)
+		if err != nil {
+			return c.fail(authenticationErrorCode, fmt.Sprintf(
+				"authentication failed for user %q", user))
+		}
+
+		if typ == gel.AuthenticationSASLInitialResponse {
+			c.writeAuthentication(0x0b, []byte(reply)) // SASL continue
+			if err := c.flush(); err != nil {
+				return err
+			}
+		} else {
+			c.writeAuthentication(0x0c, []byte(reply)) // SASL final
+		}
+	}
+
+	c.writeAuthentication(0, nil)
+	return nil
+}
+
+func (c *conn) writeReadyForCommand() {
+	c.write(gel.ReadyForCommand, func(w *buff.Writer) {
+		w.PushUint16(0) // annotations
+		w.PushUint8(c.status)
+	})
+}
+
+func (c *conn) handle(msg message) error {
+	switch msg.typ {
+	case gel.Parse, gel.Execute:
+		if c.skip {
+			return nil
+		}
+
+		q, err := c.decodeQuery(msg)
+		if err != nil {
+			return c.fail(protocolErrorCode, err.Error())
+		}
+
+		c.query(q)
+		return nil
+	case gel.Sync:
+		c.skip = false
+		c.writeReadyForCommand()
+		return c.flush()
+	case gel.Flush:
+		return c.flush()
+	case gel.Terminate:
+		return errClosed
+	default:
+		return c.fail(protocolErrorCode, fmt.Sprintf(
+			"unexpected message type 0x%x", msg.typ))
+	}
+}
+
+func (c *conn) decodeQuery(msg message) (*Query, error) {
+	q := &Query{
+		Execute:     msg.typ == gel.Execute,
+		Annotations: make(map[string]string),
+		InTx:        c.status != idle,
+	}
+
+	err := decode(func() {
+		r := msg.r
+		n := int(r.PopUint16())
+		for i := 0; i < n; i++ {
+			q.Annotations[r.PopString()] = r.PopString()
+		}
+
+		q.Capabilities = r.PopUint64()
+		r.PopUint64() // compilation flags
+		r.PopUint64() // implicit limit
+		q.Language = gel.EdgeQL
+		if c.version.Major >= 3 {
+			q.Language = gel.Language(r.PopUint8())
+		}
+		q.Format = gel.Format(r.PopUint8())
+		q.Cardinality = gel.Cardinality(r.PopUint8())
+		q.Text = r.PopString()
+		r.PopUUID() // state descriptor id
+		q.State = append([]byte(nil), r.PopBytes()...)
+
+		if q.Execute {
+			q.InputID = r.PopUUID()
+			q.OutputID = r.PopUUID()
+			q.Args = append([]byte(nil), r.PopBytes()...)
+		}
+	})
+
+	return q, err
+}
+
+// query responds to a Parse or Execute message.
+func (c *conn) query(q *Query) {
+	var resp *Response
+	if q.Execute && c.status == failedTx && status(q.Text) != "ROLLBACK" {
+		resp = &Response{Error: &Error{
+			Code: transactionErrorCode,
+			Message: "current transaction is aborted, " +
+				"commands ignored until end of transaction block",
+		}}
+	} else {
+		resp = c.server.respond(q)
+	}
+
+	if q.Execute {
+		c.fault = resp.Fault
+	}
+
+	if resp.Error != nil && (q.Execute || resp.Error.Compile) {
+		c.writeError(resp.Error.Code, resp.Error.Message)
+		c.skip = true
+		if c.status == inTx {
+			c.status = failedTx
+		}
+		return
+	}
+
+	described := false
+	if !q.Execute ||
+		q.Format != gel.Null &&
+			(q.InputID != resp.Input.ID || q.OutputID != resp.Output.ID) {
+		c.writeCommandDataDescription(q, resp)
+		described = true
+	}
+
+	if !q.Execute {
+		return
+	}
+
+	if q.InputID != resp.Input.ID {
+		if !described {
+			c.writeCommandDataDescription(q, resp)
+		}
+
+		c.writeError(parameterTypeMismatchCode,
+			"specified parameter type(s) do not match the parameter "+
+				"types inferred from specified command(s)")
+		c.skip = true
+		return
+	}
+
+	if q.Format != gel.Null {
+		for _, data := range resp.Data {
+			c.write(gel.Data, func(w *buff.Writer) {
+				w.PushUint16(1)
+				pushBytes(w, data)
+			})
+		}
+	}
+
+	commandStatus := resp.Status
+	if commandStatus == "" {
+		commandStatus = status(q.Text)
+	}
+
+	c.write(gel.CommandComplete, func(w *buff.Writer) {
+		w.PushUint16(0) // annotations
+		w.PushUint64(resp.Capabilities)
+		w.PushString(commandStatus)
+		w.PushUUID(descriptor.IDZero)
+		w.PushUint32(0) // state data
+	})
+
+	switch commandStatus {
+	case "START TRANSACTION":
+		c.status = inTx
+	case "COMMIT", "ROLLBACK":
+		c.status = idle
+	}
+}
+
+func (c *conn) writeCommandDataDescription(q *Query, resp *Response) {
+	card := resp.Cardinality
+	if card == 0 {
+		card = q.Cardinality
+		if q.Format == gel.Null {
+			card = gel.NoResult
+		}
+	}
+
+	c.write(gel.CommandDataDescription, func(w *buff.Writer) {
+		w.PushUint16(0) // annotations
+		w.PushUint64(resp.Capabilities)
+		w.PushUint8(uint8(card))
+		w.PushUUID(resp.Input.ID)
+		pushBytes(w, resp.Input.Data)
+		w.PushUUID(resp.Output.ID)
+		pushBytes(w, resp.Output.Data)
+	})
+}
+
+// defaultState describes a connection state with module, aliases, config
+// and globals.
+func defaultState() Descriptor {
+	b := NewBuilder()
+	str := b.Scalar(codecs.StrID, "std::str")
+	boolean := b.Scalar(codecs.BoolID, "std::bool")
+	duration := b.Scalar(codecs.DurationID, "std::duration")
+	isolation := b.Enum(
+		"sys::TransactionIsolation",
+		"RepeatableRead",
+		"Serializable",
+	)
+	accessMode := b.Enum("sys::TransactionAccessMode", "ReadOnly", "ReadWrite")
+	aliases := b.Array(b.Tuple(str, str))
+	optional := gel.AtMostOne
+	config := b.InputShape(
+		Element{Name: "allow_user_specified_id", Type: boolean,
+			Cardinality: optional},
+		Element{Name: "apply_access_policies", Type: boolean,
+			Cardinality: optional},
+		Element{Name: "default_transaction_access_mode", Type: accessMode,
+			Cardinality: optional},
+		Element{Name: "default_transaction_isolation", Type: isolation,
+			Cardinality: optional},
+		Element{Name: "query_execution_timeout", Type: duration,
+			Cardinality: optional},
+		Element{Name: "session_idle_transaction_timeout", Type: duration,
+			Cardinality: optional},
+	)
+	globals := b.InputShape()
+	b.InputShape(
+		Element{Name: "module", Type: str, Cardinality: optional},
+		Element{Name: "aliases", Type: aliases, Cardinality: optional},
+		Element{Name: "config", Type: config, Cardinality: optional},
+		Element{Name: "globals", Type: globals, Cardinality: optional},
+	)
+
+	return b.Build()
+}
+
+// pushBytes writes length prefixed bytes.
+func pushBytes(w *buff.Writer, data []byte) {
+	w.PushUint32(uint32(len(data)))
+	w.PushBytes(data)
+}
@@ -0,0 +1,105 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeserver
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/geldata/gel-go/geltypes"
+)
+
+// The functions in this file encode values in the binary data format.
+// https://docs.geldata.com/reference/reference/protocol/dataformats
+
+// Str encodes a std::str.
+func Str(s string) []byte { return []byte(s) }
+
+// Bool encodes a std::bool.
+func Bool(b bool) []byte {
+	if b {
+		return []byte{1}
+	}
+
+	return []byte{0}
+}
+
+// Int16 encodes a std::int16.
+func Int16(i int16) []byte {
+	return binary.BigEndian.AppendUint16(nil, uint16(i))
+}
+
+// Int32 encodes a std::int32.
+func Int32(i int32) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(i))
+}
+
+// Int64 encodes a std::int64.
+func Int64(i int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(i))
+}
+
+// Float64 encodes a std::float64.
+func Float64(f float64) []byte {
+	return binary.BigEndian.AppendUint64(nil, math.Float64bits(f))
+}
+
+// UUID encodes a std::uuid.
+func UUID(id geltypes.UUID) []byte { return id[:] }
+
+// JSON encodes a std::json.
+func JSON(data string) []byte { return append([]byte{1}, data...) }
+
+// Object encodes an object. A nil element is encoded as missing.
+func Object(elems ...[]byte) []byte {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(elems)))
+	for _, elem := range elems {
+		buf = binary.BigEndian.AppendUint32(buf, 0) // reserved
+		buf = appendElement(buf, elem)
+	}
+
+	return buf
+}
+
+// Tuple encodes a tuple or named tuple.
+func Tuple(elems ...[]byte) []byte { return Object(elems...) }
+
+// Array encodes an array or set.
+func Array(elems ...[]byte) []byte {
+	if len(elems) == 0 {
+		return make([]byte, 12)
+	}
+
+	buf := binary.BigEndian.AppendUint32(nil, 1) // dimensions
+	buf = binary.BigEndian.AppendUint64(buf, 0)  // reserved
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(elems)))
+	buf = binary.BigEndian.AppendUint32(buf, 1) // lower bound
+	for _, elem := range elems {
+		buf = appendElement(buf, elem)
+	}
+
+	return buf
+}
+
+func appendElement(buf, elem []byte) []byte {
+	if elem == nil {
+		return binary.BigEndian.AppendUint32(buf, 0xffffffff)
+	}
+
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(elem)))
+	return append(buf, elem...)
+}
@@ -0,0 +1,264 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeserver
+
+import (
+	"crypto/sha256"
+	"strconv"
+
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal/buff"
+	gel "github.com/geldata/gel-go/internal/client"
+	"github.com/geldata/gel-go/internal/descriptor"
+)
+
+// Descriptor is an encoded protocol 2.0 type descriptor.
+// The zero value describes an empty tuple, which is the type of queries
+// without arguments or results.
+type Descriptor struct {
+	ID   geltypes.UUID
+	Data []byte
+}
+
+// Element is an object, input shape or named tuple element.
+type Element struct {
+	Name string
+
+	// Type is the position returned by the Builder method that added the
+	// element's type.
+	Type uint16
+
+	// Cardinality defaults to One.
+	Cardinality gel.Cardinality
+
+	// Implicit is set for elements that are not part of the query's shape,
+	// like the id of an object.
+	Implicit bool
+}
+
+func (e Element) cardinality() uint8 {
+	if e.Cardinality == 0 {
+		return uint8(gel.One)
+	}
+
+	return uint8(e.Cardinality)
+}
+
+// Builder builds type descriptors. Each method adds a type and returns its
+// position that is used to reference it from later types. The last added
+// type is the described type.
+type Builder struct {
+	data      []byte
+	positions map[geltypes.UUID]uint16
+	last      geltypes.UUID
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{positions: make(map[geltypes.UUID]uint16)}
+}
+
+// Build returns the descriptor for the last added type.
+func (b *Builder) Build() Descriptor {
+	return Descriptor{ID: b.last, Data: append([]byte(nil), b.data...)}
+}
+
+// add appends a descriptor. The id of types without an id is derived from
+// their encoding so that equal types have equal ids.
+func (b *Builder) add(
+	typ descriptor.Type,
+	id geltypes.UUID,
+	body func(w *buff.Writer),
+) uint16 {
+	w := buff.NewWriter(nil)
+	body(w)
+	encoded := w.Unwrap()
+
+	if id == descriptor.IDZero {
+		sum := sha256.Sum256(append([]byte{uint8(typ)}, encoded...))
+		copy(id[:], sum[:16])
+	}
+
+	b.last = id
+	if pos, ok := b.positions[id]; ok {
+		return pos
+	}
+
+	w = buff.NewWriter(nil)
+	w.PushUint32(uint32(1 + 16 + len(encoded)))
+	w.PushUint8(uint8(typ))
+	w.PushUUID(id)
+	b.data = append(b.data, w.Unwrap()...)
+	b.data = append(b.data, encoded...)
+
+	pos := uint16(len(b.positions))
+	b.positions[id] = pos
+	return pos
+}
+
+// Scalar adds a scalar type. Base scalars like std::str must use their
+// well known id.
+func (b *Builder) Scalar(id geltypes.UUID, name string) uint16 {
+	return b.add(descriptor.Scalar, id, func(w *buff.Writer) {
+		w.PushString(name)
+		w.PushUint8(0)  // schema_defined
+		w.PushUint16(0) // ancestors
+	})
+}
+
+// Enum adds an enum type.
+func (b *Builder) Enum(name string, members ...string) uint16 {
+	return b.add(descriptor.Enum, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushString(name)
+		w.PushUint8(1)  // schema_defined
+		w.PushUint16(0) // ancestors
+		w.PushUint16(uint16(len(members)))
+		for _, member := range members {
+			w.PushString(member)
+		}
+	})
+}
+
+// Set adds a set of elem.
+func (b *Builder) Set(elem uint16) uint16 {
+	return b.add(descriptor.Set, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushUint16(elem)
+	})
+}
+
+// Array adds an array of elem.
+func (b *Builder) Array(elem uint16) uint16 {
+	return b.add(descriptor.Array, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushString("")
+		w.PushUint8(0)  // schema_defined
+		w.PushUint16(0) // ancestors
+		w.PushUint16(elem)
+		w.PushUint16(1) // dimensions
+		w.PushUint32(0xffffffff)
+	})
+}
+
+// Tuple adds a tuple of elems.
+func (b *Builder) Tuple(elems ...uint16) uint16 {
+	return b.add(descriptor.Tuple, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushString("")
+		w.PushUint8(0)  // schema_defined
+		w.PushUint16(0) // ancestors
+		w.PushUint16(uint16(len(elems)))
+		for _, elem := range elems {
+			w.PushUint16(elem)
+		}
+	})
+}
+
+// NamedTuple adds a named tuple.
+func (b *Builder) NamedTuple(elems ...Element) uint16 {
+	typ := descriptor.NamedTuple
+	return b.add(typ, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushString("")
+		w.PushUint8(0)  // schema_defined
+		w.PushUint16(0) // ancestors
+		w.PushUint16(uint16(len(elems)))
+		for _, elem := range elems {
+			w.PushString(elem.Name)
+			w.PushUint16(elem.Type)
+		}
+	})
+}
+
+// Object adds an object of the named object type.
+func (b *Builder) Object(name string, elems ...Element) uint16 {
+	shape := b.add(
+		descriptor.ObjectShape,
+		descriptor.IDZero,
+		func(w *buff.Writer) {
+			w.PushString(name)
+			w.PushUint8(1) // schema_defined
+		},
+	)
+
+	return b.add(descriptor.Object, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushUint8(1) // schema_defined
+		w.PushUint16(shape)
+		w.PushUint16(uint16(len(elems)))
+		for _, elem := range elems {
+			var flags uint32
+			if elem.Implicit {
+				flags |= 1
+			}
+
+			w.PushUint32(flags)
+			w.PushUint8(elem.cardinality())
+			w.PushString(elem.Name)
+			w.PushUint16(elem.Type)
+			w.PushUint16(shape) // source_type
+		}
+	})
+}
+
+// SQLRecord adds a row returned by a SQL query.
+func (b *Builder) SQLRecord(elems ...Element) uint16 {
+	typ := descriptor.SQLRecord
+	return b.add(typ, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushUint16(uint16(len(elems)))
+		for _, elem := range elems {
+			w.PushString(elem.Name)
+			w.PushUint16(elem.Type)
+		}
+	})
+}
+
+// InputShape adds a sparse object like the connection state.
+func (b *Builder) InputShape(elems ...Element) uint16 {
+	typ := descriptor.InputShape
+	return b.add(typ, descriptor.IDZero, func(w *buff.Writer) {
+		w.PushUint16(uint16(len(elems)))
+		for _, elem := range elems {
+			w.PushUint32(0) // flags
+			w.PushUint8(elem.cardinality())
+			w.PushString(elem.Name)
+			w.PushUint16(elem.Type)
+		}
+	})
+}
+
+// ScalarDescriptor returns the descriptor of a base scalar type.
+func ScalarDescriptor(id geltypes.UUID, name string) Descriptor {
+	b := NewBuilder()
+	b.Scalar(id, name)
+	return b.Build()
+}
+
+// ArgsDescriptor returns the input descriptor for positional arguments
+// of the given scalar types. types must be created by [ScalarDescriptor],
+// use [Builder.Object] for other argument types. Arguments are described
+// as an object with elements named by their position.
+func ArgsDescriptor(types ...Descriptor) Descriptor {
+	b := NewBuilder()
+	elems := make([]Element, len(types))
+	for i, typ := range types {
+		pos, ok := b.positions[typ.ID]
+		if !ok {
+			pos = uint16(len(b.positions))
+			b.positions[typ.ID] = pos
+			b.data = append(b.data, typ.Data...)
+		}
+		elems[i] = Element{Name: strconv.Itoa(i), Type: pos}
+	}
+	b.Object("", elems...)
+	return b.Build()
+}
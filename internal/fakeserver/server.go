@@ -0,0 +1,382 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fakeserver implements the server side of the binary protocol for
+// hermetic tests. Query responses are scripted with type descriptors and
+// encoded data and can inject network faults like disconnects in the middle
+// of a message or slow writes.
+//
+// Only protocol version 2.0 and later are supported.
+package fakeserver
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/geldata/gel-go/internal"
+	gel "github.com/geldata/gel-go/internal/client"
+)
+
+// Options configures a Server.
+type Options struct {
+	// User is the user that clients authenticate as. The default is edgedb.
+	User string
+
+	// Password enables SCRAM-SHA-256 authentication.
+	// Without a password all clients are trusted.
+	Password string
+
+	// ProtocolVersion is the protocol version the server offers.
+	// The default is the newest version supported by the client.
+	ProtocolVersion internal.ProtocolVersion
+
+	// PoolConcurrency is sent as the suggested_pool_concurrency parameter.
+	PoolConcurrency int
+
+	// State describes the connection state. The default has module,
+	// aliases, config and globals.
+	State Descriptor
+}
+
+// Error is an ErrorResponse.
+type Error struct {
+	// Code is the error code, for example 0x05_03_01_00 for
+	// TransactionConflictError.
+	Code    uint32
+	Message string
+
+	// Compile sends the error in response to Parse like the server does
+	// for invalid queries. Other errors are sent in response to Execute.
+	Compile bool
+}
+
+// Fault is a network failure that is injected when the response to Execute
+// is written.
+type Fault struct {
+	// Delay is how long the server waits before it writes the response.
+	Delay time.Duration
+
+	// ChunkSize splits the response into writes of at most ChunkSize bytes.
+	ChunkSize int
+
+	// ChunkDelay is how long the server waits between chunks.
+	ChunkDelay time.Duration
+
+	// Disconnect closes the connection after DisconnectAfter bytes of the
+	// response have been written.
+	Disconnect      bool
+	DisconnectAfter int
+}
+
+// Query is a Parse or Execute message received by the server.
+type Query struct {
+	// Execute is false for Parse messages.
+	Execute bool
+
+	Text         string
+	Language     gel.Language
+	Format       gel.Format
+	Cardinality  gel.Cardinality
+	Capabilities uint64
+	Annotations  map[string]string
+
+	// State is the encoded connection state.
+	State []byte
+
+	// InputID and OutputID are the descriptor ids the client used to
+	// encode the arguments and expects the results in.
+	// They are only set for Execute messages.
+	InputID  geltypes.UUID
+	OutputID geltypes.UUID
+
+	// Args are the encoded arguments.
+	Args []byte
+
+	// InTx is true if the connection is in a transaction.
+	InTx bool
+}
+
+// Response is the response to a query.
+type Response struct {
+	// Error is sent instead of a result if it is set.
+	Error *Error
+
+	// Cardinality is the result cardinality. The default is the cardinality
+	// the client expects.
+	Cardinality gel.Cardinality
+
+	// Capabilities are the capabilities the query uses.
+	Capabilities uint64
+
+	Input  Descriptor
+	Output Descriptor
+
+	// Data are the encoded result elements.
+	Data [][]byte
+
+	// Status is sent in CommandComplete. The default is the query's first
+	// keyword.
+	Status string
+
+	Fault Fault
+}
+
+// Handler returns the response to a query or nil if it doesn't handle the
+// query.
+type Handler func(*Query) *Response
+
+// Server is a stand-in Gel server. Close it when it is no longer needed.
+type Server struct {
+	opts     Options
+	listener net.Listener
+	caPEM    []byte
+
+	mu       sync.Mutex
+	handlers []Handler
+	queries  []Query
+	conns    map[*conn]struct{}
+	accepted int
+	wg       sync.WaitGroup
+}
+
+// Start returns a server that listens on a random local port.
+func Start(opts Options) (*Server, error) {
+	if opts.User == "" {
+		opts.User = "edgedb"
+	}
+
+	if opts.ProtocolVersion == (internal.ProtocolVersion{}) {
+		opts.ProtocolVersion = gel.ProtocolVersionMax
+	}
+
+	if opts.ProtocolVersion.Major < 2 {
+		return nil, errors.New("fakeserver: protocol versions before " +
+			"2.0 are not supported")
+	}
+
+	if opts.State.ID == (geltypes.UUID{}) {
+		opts.State = defaultState()
+	}
+
+	cert, caPEM, err := NewCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"edgedb-binary"},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		opts:     opts,
+		listener: listener,
+		caPEM:    caPEM,
+		conns:    make(map[*conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// ClientOptions returns options for connecting to the server.
+func (s *Server) ClientOptions() gelcfg.Options {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	opts := gelcfg.Options{
+		Host: addr.IP.String(),
+		Port: addr.Port,
+		User: s.opts.User,
+		TLSOptions: gelcfg.TLSOptions{
+			CA:           s.caPEM,
+			SecurityMode: gelcfg.TLSModeStrict,
+		},
+	}
+
+	if s.opts.Password != "" {
+		opts.Password = geltypes.NewOptionalStr(s.opts.Password)
+	}
+
+	return opts
+}
+
+// Handle responds to queries with the text query. Responses are used in
+// order and the last response is repeated. A response is used up when the
+// query is executed or when it is a compile error.
+func (s *Server) Handle(query string, responses ...*Response) {
+	var mu sync.Mutex
+	s.HandleFunc(func(q *Query) *Response {
+		if q.Text != query || len(responses) == 0 {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Parse and Execute for the same query get the same response.
+		resp := responses[0]
+		used := q.Execute || resp.Error != nil && resp.Error.Compile
+		if used && len(responses) > 1 {
+			responses = responses[1:]
+		}
+
+		return resp
+	})
+}
+
+// HandleFunc adds a handler. Handlers are called in the order they were
+// added until one returns a response.
+//
+// Queries without a response get an error unless they don't return data,
+// for example START TRANSACTION, which succeed.
+func (s *Server) HandleFunc(h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers = append(s.handlers, h)
+}
+
+// Queries returns the queries received by the server.
+func (s *Server) Queries() []Query {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Query(nil), s.queries...)
+}
+
+// Executed returns the text of the executed queries.
+func (s *Server) Executed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var queries []string
+	for _, q := range s.queries {
+		if q.Execute {
+			queries = append(queries, q.Text)
+		}
+	}
+
+	return queries
+}
+
+// Connections returns the number of open connections.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+// Accepted returns the number of connections accepted since the server
+// started.
+func (s *Server) Accepted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.accepted
+}
+
+// Disconnect closes all open connections.
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.conns {
+		_ = c.Close()
+	}
+}
+
+// Close stops the server and closes all connections.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.Disconnect()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		netConn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		c := &conn{Conn: netConn, server: s}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.accepted++
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			c.serve()
+
+			s.mu.Lock()
+			delete(s.conns, c)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// respond records q and returns its response.
+func (s *Server) respond(q *Query) *Response {
+	s.mu.Lock()
+	s.queries = append(s.queries, *q)
+	handlers := s.handlers
+	s.mu.Unlock()
+
+	for _, h := range handlers {
+		if resp := h(q); resp != nil {
+			return resp
+		}
+	}
+
+	if q.Format == gel.Null {
+		return &Response{}
+	}
+
+	return &Response{Error: &Error{
+		Code:    0x04_00_00_00, // QueryError
+		Message: "fakeserver: no response for query: " + q.Text,
+		Compile: true,
+	}}
+}
+
+// status returns the default CommandComplete status for a query.
+func status(query string) string {
+	words := strings.Fields(strings.ToUpper(query))
+	switch {
+	case len(words) == 0:
+		return ""
+	case len(words) > 1 && words[0] == "START":
+		return "START TRANSACTION"
+	default:
+		return strings.TrimSuffix(words[0], ";")
+	}
+}
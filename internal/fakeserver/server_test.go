@@ -0,0 +1,245 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakeserver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gel "github.com/geldata/gel-go"
+	"github.com/geldata/gel-go/gelerr"
+	"github.com/geldata/gel-go/geltypes"
+	gelint "github.com/geldata/gel-go/internal/client"
+	"github.com/geldata/gel-go/internal/codecs"
+	fs "github.com/geldata/gel-go/internal/fakeserver"
+)
+
+var (
+	int64Desc = fs.ScalarDescriptor(codecs.Int64ID, "std::int64")
+	strDesc   = fs.ScalarDescriptor(codecs.StrID, "std::str")
+)
+
+func start(t *testing.T, opts fs.Options) (*fs.Server, *gel.Client) {
+	server, err := fs.Start(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, server.Close()) })
+
+	client, err := gel.CreateClient(server.ClientOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, client.Close()) })
+
+	return server, client
+}
+
+func TestQuerySingle(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{})
+	server.Handle("SELECT 1", &fs.Response{
+		Output: int64Desc,
+		Data:   [][]byte{fs.Int64(1)},
+	})
+
+	var result int64
+	require.NoError(t, client.QuerySingle(ctx, "SELECT 1", &result))
+	assert.Equal(t, int64(1), result)
+	assert.Equal(t, []string{"SELECT 1"}, server.Executed())
+}
+
+func TestQueryObjects(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{})
+
+	b := fs.NewBuilder()
+	uuid := b.Scalar(codecs.UUIDID, "std::uuid")
+	str := b.Scalar(codecs.StrID, "std::str")
+	b.Object("default::User",
+		fs.Element{Name: "id", Type: uuid, Implicit: true},
+		fs.Element{Name: "name", Type: str},
+		fs.Element{Name: "email", Type: str, Cardinality: gelint.AtMostOne},
+	)
+
+	id := geltypes.UUID{1}
+	server.Handle("SELECT User { name, email }", &fs.Response{
+		Output: b.Build(),
+		Data: [][]byte{
+			fs.Object(fs.UUID(id), fs.Str("alice"), fs.Str("a@example.com")),
+			fs.Object(fs.UUID(id), fs.Str("bob"), nil),
+		},
+	})
+
+	type User struct {
+		ID    geltypes.UUID        `gel:"id"`
+		Name  string               `gel:"name"`
+		Email geltypes.OptionalStr `gel:"email"`
+	}
+
+	var users []User
+	err := client.Query(ctx, "SELECT User { name, email }", &users)
+	require.NoError(t, err)
+	email := geltypes.NewOptionalStr("a@example.com")
+	assert.Equal(t, []User{
+		{ID: id, Name: "alice", Email: email},
+		{ID: id, Name: "bob"},
+	}, users)
+}
+
+func TestArgs(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{})
+	server.Handle("SELECT <str>$0", &fs.Response{
+		Input:  fs.ArgsDescriptor(strDesc),
+		Output: strDesc,
+		Data:   [][]byte{fs.Str("hello")},
+	})
+
+	var result string
+	err := client.QuerySingle(ctx, "SELECT <str>$0", &result, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", result)
+
+	queries := server.Queries()
+	require.Len(t, queries, 2)
+	assert.False(t, queries[0].Execute)
+	assert.True(t, queries[1].Execute)
+	assert.Equal(t, fs.Object(fs.Str("hi")), queries[1].Args)
+}
+
+func TestQueryError(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{})
+	server.Handle("SELECT missing", &fs.Response{Error: &fs.Error{
+		Code:    0x04_03_00_00, // InvalidReferenceError
+		Message: "object type or alias 'default::missing' does not exist",
+	}})
+
+	var result int64
+	err := client.QuerySingle(ctx, "SELECT missing", &result)
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.InvalidReferenceError))
+
+	err = client.QuerySingle(ctx, "SELECT unknown", &result)
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.QueryError))
+}
+
+func TestPassword(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{User: "admin", Password: "secret"})
+	require.NoError(t, client.EnsureConnected(ctx))
+
+	opts := server.ClientOptions()
+	opts.Password = geltypes.NewOptionalStr("wrong")
+	other, err := gel.CreateClient(opts)
+	require.NoError(t, err)
+	defer other.Close() // nolint:errcheck
+
+	err = other.EnsureConnected(ctx)
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.AuthenticationError))
+}
+
+func TestTxRetry(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{})
+	server.Handle("UPDATE User SET { n := .n + 1 }",
+		&fs.Response{Error: &fs.Error{
+			Code:    0x05_03_01_00, // TransactionConflictError
+			Message: "could not serialize access",
+		}},
+		&fs.Response{Status: "UPDATE"},
+	)
+
+	attempts := 0
+	err := client.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+		attempts++
+		return tx.Execute(ctx, "UPDATE User SET { n := .n + 1 }")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	executed := server.Executed()
+	require.Len(t, executed, 6)
+	assert.Equal(t, "ROLLBACK;", executed[2])
+	assert.Equal(t, "COMMIT;", executed[5])
+}
+
+func TestMidMessageDisconnect(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{})
+	server.Handle("SELECT 1",
+		&fs.Response{
+			Output: int64Desc,
+			Data:   [][]byte{fs.Int64(1)},
+			Fault:  fs.Fault{Disconnect: true, DisconnectAfter: 3},
+		},
+		&fs.Response{Output: int64Desc, Data: [][]byte{fs.Int64(1)}},
+	)
+
+	var result []int64
+	require.NoError(t, client.Query(ctx, "SELECT 1", &result))
+	assert.Equal(t, []int64{1}, result)
+	assert.Equal(t, 2, server.Accepted())
+}
+
+func TestDisconnectIdle(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{})
+	server.Handle("SELECT 1", &fs.Response{
+		Output: int64Desc,
+		Data:   [][]byte{fs.Int64(1)},
+	})
+
+	var result []int64
+	require.NoError(t, client.Query(ctx, "SELECT 1", &result))
+	server.Disconnect()
+	require.NoError(t, client.Query(ctx, "SELECT 1", &result))
+	assert.Equal(t, []int64{1}, result)
+	assert.Equal(t, 2, server.Accepted())
+}
+
+func TestSlowWrites(t *testing.T) {
+	server, client := start(t, fs.Options{})
+	server.Handle("SELECT 1", &fs.Response{
+		Output: int64Desc,
+		Data:   [][]byte{fs.Int64(1)},
+		Fault:  fs.Fault{ChunkSize: 1, ChunkDelay: 10 * time.Millisecond},
+	})
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	var result []int64
+	err := client.Query(ctx, "SELECT 1", &result)
+	var gelErr gelerr.Error
+	require.True(t, errors.As(err, &gelErr), err)
+	assert.True(t, gelErr.Category(gelerr.ClientConnectionTimeoutError))
+}
+
+func TestPoolConcurrency(t *testing.T) {
+	ctx := context.Background()
+	server, client := start(t, fs.Options{PoolConcurrency: 3})
+	require.NoError(t, client.EnsureConnected(ctx))
+	assert.Equal(t, 1, server.Connections())
+}
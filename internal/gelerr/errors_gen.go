@@ -4662,3 +4662,216 @@ func ErrorFromCode(code uint32, msg string) error {
 		}
 	}
 }
+
+func ErrorFromCategory(category gelerr.ErrorCategory, msg string) error {
+	switch category {
+	case gelerr.InternalServerError:
+		return &InternalServerError{msg: msg}
+	case gelerr.UnsupportedFeatureError:
+		return &UnsupportedFeatureError{msg: msg}
+	case gelerr.ProtocolError:
+		return &ProtocolError{msg: msg}
+	case gelerr.BinaryProtocolError:
+		return &BinaryProtocolError{msg: msg}
+	case gelerr.UnsupportedProtocolVersionError:
+		return &UnsupportedProtocolVersionError{msg: msg}
+	case gelerr.TypeSpecNotFoundError:
+		return &TypeSpecNotFoundError{msg: msg}
+	case gelerr.UnexpectedMessageError:
+		return &UnexpectedMessageError{msg: msg}
+	case gelerr.InputDataError:
+		return &InputDataError{msg: msg}
+	case gelerr.ParameterTypeMismatchError:
+		return &ParameterTypeMismatchError{msg: msg}
+	case gelerr.StateMismatchError:
+		return &StateMismatchError{msg: msg}
+	case gelerr.ResultCardinalityMismatchError:
+		return &ResultCardinalityMismatchError{msg: msg}
+	case gelerr.CapabilityError:
+		return &CapabilityError{msg: msg}
+	case gelerr.UnsupportedCapabilityError:
+		return &UnsupportedCapabilityError{msg: msg}
+	case gelerr.DisabledCapabilityError:
+		return &DisabledCapabilityError{msg: msg}
+	case gelerr.UnsafeIsolationLevelError:
+		return &UnsafeIsolationLevelError{msg: msg}
+	case gelerr.QueryError:
+		return &QueryError{msg: msg}
+	case gelerr.InvalidSyntaxError:
+		return &InvalidSyntaxError{msg: msg}
+	case gelerr.EdgeQLSyntaxError:
+		return &EdgeQLSyntaxError{msg: msg}
+	case gelerr.SchemaSyntaxError:
+		return &SchemaSyntaxError{msg: msg}
+	case gelerr.GraphQLSyntaxError:
+		return &GraphQLSyntaxError{msg: msg}
+	case gelerr.InvalidTypeError:
+		return &InvalidTypeError{msg: msg}
+	case gelerr.InvalidTargetError:
+		return &InvalidTargetError{msg: msg}
+	case gelerr.InvalidLinkTargetError:
+		return &InvalidLinkTargetError{msg: msg}
+	case gelerr.InvalidPropertyTargetError:
+		return &InvalidPropertyTargetError{msg: msg}
+	case gelerr.InvalidReferenceError:
+		return &InvalidReferenceError{msg: msg}
+	case gelerr.UnknownModuleError:
+		return &UnknownModuleError{msg: msg}
+	case gelerr.UnknownLinkError:
+		return &UnknownLinkError{msg: msg}
+	case gelerr.UnknownPropertyError:
+		return &UnknownPropertyError{msg: msg}
+	case gelerr.UnknownUserError:
+		return &UnknownUserError{msg: msg}
+	case gelerr.UnknownDatabaseError:
+		return &UnknownDatabaseError{msg: msg}
+	case gelerr.UnknownParameterError:
+		return &UnknownParameterError{msg: msg}
+	case gelerr.DeprecatedScopingError:
+		return &DeprecatedScopingError{msg: msg}
+	case gelerr.SchemaError:
+		return &SchemaError{msg: msg}
+	case gelerr.SchemaDefinitionError:
+		return &SchemaDefinitionError{msg: msg}
+	case gelerr.InvalidDefinitionError:
+		return &InvalidDefinitionError{msg: msg}
+	case gelerr.InvalidModuleDefinitionError:
+		return &InvalidModuleDefinitionError{msg: msg}
+	case gelerr.InvalidLinkDefinitionError:
+		return &InvalidLinkDefinitionError{msg: msg}
+	case gelerr.InvalidPropertyDefinitionError:
+		return &InvalidPropertyDefinitionError{msg: msg}
+	case gelerr.InvalidUserDefinitionError:
+		return &InvalidUserDefinitionError{msg: msg}
+	case gelerr.InvalidDatabaseDefinitionError:
+		return &InvalidDatabaseDefinitionError{msg: msg}
+	case gelerr.InvalidOperatorDefinitionError:
+		return &InvalidOperatorDefinitionError{msg: msg}
+	case gelerr.InvalidAliasDefinitionError:
+		return &InvalidAliasDefinitionError{msg: msg}
+	case gelerr.InvalidFunctionDefinitionError:
+		return &InvalidFunctionDefinitionError{msg: msg}
+	case gelerr.InvalidConstraintDefinitionError:
+		return &InvalidConstraintDefinitionError{msg: msg}
+	case gelerr.InvalidCastDefinitionError:
+		return &InvalidCastDefinitionError{msg: msg}
+	case gelerr.DuplicateDefinitionError:
+		return &DuplicateDefinitionError{msg: msg}
+	case gelerr.DuplicateModuleDefinitionError:
+		return &DuplicateModuleDefinitionError{msg: msg}
+	case gelerr.DuplicateLinkDefinitionError:
+		return &DuplicateLinkDefinitionError{msg: msg}
+	case gelerr.DuplicatePropertyDefinitionError:
+		return &DuplicatePropertyDefinitionError{msg: msg}
+	case gelerr.DuplicateUserDefinitionError:
+		return &DuplicateUserDefinitionError{msg: msg}
+	case gelerr.DuplicateDatabaseDefinitionError:
+		return &DuplicateDatabaseDefinitionError{msg: msg}
+	case gelerr.DuplicateOperatorDefinitionError:
+		return &DuplicateOperatorDefinitionError{msg: msg}
+	case gelerr.DuplicateViewDefinitionError:
+		return &DuplicateViewDefinitionError{msg: msg}
+	case gelerr.DuplicateFunctionDefinitionError:
+		return &DuplicateFunctionDefinitionError{msg: msg}
+	case gelerr.DuplicateConstraintDefinitionError:
+		return &DuplicateConstraintDefinitionError{msg: msg}
+	case gelerr.DuplicateCastDefinitionError:
+		return &DuplicateCastDefinitionError{msg: msg}
+	case gelerr.DuplicateMigrationError:
+		return &DuplicateMigrationError{msg: msg}
+	case gelerr.SessionTimeoutError:
+		return &SessionTimeoutError{msg: msg}
+	case gelerr.IdleSessionTimeoutError:
+		return &IdleSessionTimeoutError{msg: msg}
+	case gelerr.QueryTimeoutError:
+		return &QueryTimeoutError{msg: msg}
+	case gelerr.TransactionTimeoutError:
+		return &TransactionTimeoutError{msg: msg}
+	case gelerr.IdleTransactionTimeoutError:
+		return &IdleTransactionTimeoutError{msg: msg}
+	case gelerr.ExecutionError:
+		return &ExecutionError{msg: msg}
+	case gelerr.InvalidValueError:
+		return &InvalidValueError{msg: msg}
+	case gelerr.DivisionByZeroError:
+		return &DivisionByZeroError{msg: msg}
+	case gelerr.NumericOutOfRangeError:
+		return &NumericOutOfRangeError{msg: msg}
+	case gelerr.AccessPolicyError:
+		return &AccessPolicyError{msg: msg}
+	case gelerr.QueryAssertionError:
+		return &QueryAssertionError{msg: msg}
+	case gelerr.IntegrityError:
+		return &IntegrityError{msg: msg}
+	case gelerr.ConstraintViolationError:
+		return &ConstraintViolationError{msg: msg}
+	case gelerr.CardinalityViolationError:
+		return &CardinalityViolationError{msg: msg}
+	case gelerr.MissingRequiredError:
+		return &MissingRequiredError{msg: msg}
+	case gelerr.TransactionError:
+		return &TransactionError{msg: msg}
+	case gelerr.TransactionConflictError:
+		return &TransactionConflictError{msg: msg}
+	case gelerr.TransactionSerializationError:
+		return &TransactionSerializationError{msg: msg}
+	case gelerr.TransactionDeadlockError:
+		return &TransactionDeadlockError{msg: msg}
+	case gelerr.QueryCacheInvalidationError:
+		return &QueryCacheInvalidationError{msg: msg}
+	case gelerr.WatchError:
+		return &WatchError{msg: msg}
+	case gelerr.ConfigurationError:
+		return &ConfigurationError{msg: msg}
+	case gelerr.AccessError:
+		return &AccessError{msg: msg}
+	case gelerr.AuthenticationError:
+		return &AuthenticationError{msg: msg}
+	case gelerr.AvailabilityError:
+		return &AvailabilityError{msg: msg}
+	case gelerr.BackendUnavailableError:
+		return &BackendUnavailableError{msg: msg}
+	case gelerr.ServerOfflineError:
+		return &ServerOfflineError{msg: msg}
+	case gelerr.UnknownTenantError:
+		return &UnknownTenantError{msg: msg}
+	case gelerr.ServerBlockedError:
+		return &ServerBlockedError{msg: msg}
+	case gelerr.BackendError:
+		return &BackendError{msg: msg}
+	case gelerr.UnsupportedBackendFeatureError:
+		return &UnsupportedBackendFeatureError{msg: msg}
+	case gelerr.ClientError:
+		return &ClientError{msg: msg}
+	case gelerr.ClientConnectionError:
+		return &ClientConnectionError{msg: msg}
+	case gelerr.ClientConnectionFailedError:
+		return &ClientConnectionFailedError{msg: msg}
+	case gelerr.ClientConnectionFailedTemporarilyError:
+		return &ClientConnectionFailedTemporarilyError{msg: msg}
+	case gelerr.ClientConnectionTimeoutError:
+		return &ClientConnectionTimeoutError{msg: msg}
+	case gelerr.ClientConnectionClosedError:
+		return &ClientConnectionClosedError{msg: msg}
+	case gelerr.InterfaceError:
+		return &InterfaceError{msg: msg}
+	case gelerr.QueryArgumentError:
+		return &QueryArgumentError{msg: msg}
+	case gelerr.MissingArgumentError:
+		return &MissingArgumentError{msg: msg}
+	case gelerr.UnknownArgumentError:
+		return &UnknownArgumentError{msg: msg}
+	case gelerr.InvalidArgumentError:
+		return &InvalidArgumentError{msg: msg}
+	case gelerr.NoDataError:
+		return &NoDataError{msg: msg}
+	case gelerr.InternalClientError:
+		return &InternalClientError{msg: msg}
+	default:
+		return &InternalClientError{
+			msg: fmt.Sprintf(
+				"invalid error category %q with message %q", category, msg,
+			),
+		}
+	}
+}
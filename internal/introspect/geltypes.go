@@ -0,0 +1,83 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package introspect
+
+import (
+	"math/big"
+	"reflect"
+	"time"
+
+	types "github.com/geldata/gel-go/geltypes"
+)
+
+// scalarTypes maps go types to the Gel scalar types they are encoded as.
+var scalarTypes = map[reflect.Type]string{
+	reflect.TypeOf(""):                         "std::str",
+	reflect.TypeOf(false):                      "std::bool",
+	reflect.TypeOf(int16(0)):                   "std::int16",
+	reflect.TypeOf(int32(0)):                   "std::int32",
+	reflect.TypeOf(int64(0)):                   "std::int64",
+	reflect.TypeOf(float32(0)):                 "std::float32",
+	reflect.TypeOf(float64(0)):                 "std::float64",
+	reflect.TypeOf([]byte{}):                   "std::bytes",
+	reflect.TypeOf(&big.Int{}):                 "std::bigint",
+	reflect.TypeOf(time.Time{}):                "std::datetime",
+	reflect.TypeOf(types.UUID{}):               "std::uuid",
+	reflect.TypeOf(types.Duration(0)):          "std::duration",
+	reflect.TypeOf(types.LocalDateTime{}):      "cal::local_datetime",
+	reflect.TypeOf(types.LocalDate{}):          "cal::local_date",
+	reflect.TypeOf(types.LocalTime{}):          "cal::local_time",
+	reflect.TypeOf(types.RelativeDuration{}):   "cal::relative_duration",
+	reflect.TypeOf(types.DateDuration{}):       "cal::date_duration",
+	reflect.TypeOf(types.Memory(0)):            "cfg::memory",
+	reflect.TypeOf(types.RangeInt32{}):         "range<std::int32>",
+	reflect.TypeOf(types.RangeInt64{}):         "range<std::int64>",
+	reflect.TypeOf(types.RangeFloat32{}):       "range<std::float32>",
+	reflect.TypeOf(types.RangeFloat64{}):       "range<std::float64>",
+	reflect.TypeOf(types.RangeDateTime{}):      "range<std::datetime>",
+	reflect.TypeOf(types.RangeLocalDateTime{}): "range<cal::local_datetime>",
+	reflect.TypeOf(types.RangeLocalDate{}):     "range<cal::local_date>",
+	reflect.TypeOf(types.Vector{}):             "ext::pgvector::vector",
+	reflect.TypeOf(types.HalfVector{}):         "ext::pgvector::halfvec",
+	reflect.TypeOf(types.SparseVector{}):       "ext::pgvector::sparsevec",
+	reflect.TypeOf(types.Geometry{}):           "ext::postgis::geometry",
+}
+
+// ScalarTypeName returns the fully qualified name of the Gel type that
+// values of type t are encoded as.
+func ScalarTypeName(t reflect.Type) (string, bool) {
+	name, ok := scalarTypes[t]
+	return name, ok
+}
+
+// OptionalType returns the type of the value wrapped by an optional type like
+// geltypes.OptionalStr. Optional types have a Get() (T, bool) method.
+func OptionalType(t reflect.Type) (reflect.Type, bool) {
+	method, ok := reflect.PointerTo(t).MethodByName("Get")
+	if !ok {
+		return nil, false
+	}
+
+	// The receiver is the method's first input.
+	if method.Type.NumIn() != 1 ||
+		method.Type.NumOut() != 2 ||
+		method.Type.Out(1).Kind() != reflect.Bool {
+		return nil, false
+	}
+
+	return method.Type.Out(0), true
+}
@@ -19,6 +19,8 @@ package introspect
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"unicode"
 )
 
 func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
@@ -56,6 +58,99 @@ func StructField(t reflect.Type, name string) (reflect.StructField, bool) {
 	return reflect.StructField{}, false
 }
 
+// SQLColumnField finds a field for a SQL result column. In addition to the
+// rules used by StructField, fields are matched by their `db` tag and then by
+// comparing the column name case insensitively to the field name or to the
+// field name converted to snake_case.
+func SQLColumnField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if f, ok := StructField(t, name); ok {
+		return f, true
+	}
+
+	if f, ok := fieldByDBTag(t, name); ok {
+		return f, true
+	}
+
+	return fieldByFoldedName(t, name)
+}
+
+func isInlined(field reflect.StructField) bool {
+	tag, ok := field.Tag.Lookup("gel")
+	if !ok {
+		tag = field.Tag.Get("edgedb")
+	}
+
+	return tag == "$inline"
+}
+
+func fieldByDBTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isInlined(field) {
+			if f, ok := fieldByDBTag(field.Type, name); ok {
+				f.Offset += field.Offset
+				return f, true
+			}
+			continue
+		}
+
+		tag, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if tag != "" && tag != "-" && tag == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func fieldByFoldedName(
+	t reflect.Type,
+	name string,
+) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isInlined(field) {
+			if f, ok := fieldByFoldedName(field.Type, name); ok {
+				f.Offset += field.Offset
+				return f, true
+			}
+			continue
+		}
+
+		if !field.IsExported() || field.Tag.Get("db") == "-" {
+			continue
+		}
+
+		if strings.EqualFold(field.Name, name) ||
+			strings.EqualFold(SnakeCase(field.Name), name) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// SnakeCase converts a Go identifier like UserID into user_id.
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	b.Grow(len(name) + 4)
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
 // ValueOf returns the reflect.Value of an out parameter or an error
 // if the out parameter is not valid.
 func ValueOf(i interface{}) (reflect.Value, error) {
@@ -201,3 +201,51 @@ func TestEdgeDBTagAccepted(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, "FieldName", field.Name)
 }
+
+type SQLRow struct {
+	InlinedSomeStruct `gel:"$inline"`
+	UserID            string
+	CreatedAt         string `db:"created"`
+	Ignored           string `db:"-"`
+	HTTPServerName    string
+}
+
+func TestSQLColumnField(t *testing.T) {
+	typ := reflect.TypeOf(SQLRow{})
+
+	tests := []struct {
+		column string
+		field  string
+	}{
+		{"First", "Third"},
+		{"zebra", "Zebra"},
+		{"user_id", "UserID"},
+		{"USERID", "UserID"},
+		{"created", "CreatedAt"},
+		{"created_at", "CreatedAt"},
+		{"http_server_name", "HTTPServerName"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.column, func(t *testing.T) {
+			field, ok := SQLColumnField(typ, test.column)
+			require.True(t, ok)
+			assert.Equal(t, test.field, field.Name)
+		})
+	}
+
+	_, ok := SQLColumnField(typ, "ignored")
+	assert.False(t, ok)
+
+	field, ok := SQLColumnField(typ, "second")
+	require.True(t, ok)
+	assert.Equal(t, "Second", field.Name)
+	assert.Equal(t, uintptr(16), field.Offset)
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "user_id", SnakeCase("UserID"))
+	assert.Equal(t, "http_server", SnakeCase("HTTPServer"))
+	assert.Equal(t, "name", SnakeCase("Name"))
+	assert.Equal(t, "address2_line", SnakeCase("Address2Line"))
+}
@@ -0,0 +1,129 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package project finds the Gel project that contains a directory.
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	toml "github.com/pelletier/go-toml/v2"
+)
+
+// Project is a Gel project.
+type Project struct {
+	// RootDir is the directory that contains the project's manifest.
+	RootDir string
+
+	// Manifest is the path to the project's gel.toml or edgedb.toml.
+	Manifest string
+
+	// SchemaDir is the directory that contains the project's schema.
+	SchemaDir string
+
+	// MigrationsDir is the directory that contains the project's migrations.
+	MigrationsDir string
+}
+
+func isTOMLFile(file string) (bool, error) {
+	info, err := os.Stat(file)
+	if err == nil {
+		if info.Mode() == fs.ModeDir {
+			return false, fmt.Errorf(
+				"expected %q to be a file not a directory",
+				file,
+			)
+		}
+		return true, nil
+	}
+
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// Find returns the project that contains dir. The project's root is the
+// closest directory at or above dir that contains a gel.toml or edgedb.toml
+// file.
+func Find(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		parent := filepath.Dir(dir)
+		if dir == parent {
+			return nil, fmt.Errorf(
+				"could not find gel.toml, " +
+					"fix this by initializing a project, run: " +
+					" gel project init",
+			)
+		}
+
+		file := filepath.Join(dir, "gel.toml")
+		isTOML, err := isTOMLFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isTOML {
+			file = filepath.Join(dir, "edgedb.toml")
+			isTOML, err = isTOMLFile(file)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if isTOML {
+			return load(dir, file)
+		}
+		dir = parent
+	}
+}
+
+func load(dir, file string) (*Project, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var x struct {
+		Project struct {
+			SchemaDir string `toml:"schema-dir"`
+		}
+	}
+	x.Project.SchemaDir = "dbschema"
+	err = toml.Unmarshal(data, &x)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaDir := filepath.Join(dir, x.Project.SchemaDir)
+
+	return &Project{
+		RootDir:       dir,
+		Manifest:      file,
+		SchemaDir:     schemaDir,
+		MigrationsDir: filepath.Join(schemaDir, "migrations"),
+	}, nil
+}
@@ -0,0 +1,61 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0o700))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(root, "gel.toml"),
+		[]byte("[project]\nschema-dir = \"somewhere/else\"\n"),
+		0o600,
+	))
+
+	p, err := Find(nested)
+	require.NoError(t, err)
+	assert.Equal(t, &Project{
+		RootDir:       root,
+		Manifest:      filepath.Join(root, "gel.toml"),
+		SchemaDir:     filepath.Join(root, "somewhere", "else"),
+		MigrationsDir: filepath.Join(root, "somewhere", "else", "migrations"),
+	}, p)
+}
+
+func TestFindEdgeDBTOML(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(root, "edgedb.toml"),
+		[]byte("[edgedb]\nserver-version = \"3.3\"\n"),
+		0o600,
+	))
+
+	p, err := Find(root)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "dbschema"), p.SchemaDir)
+	assert.Equal(t, filepath.Join(root, "dbschema", "migrations"),
+		p.MigrationsDir)
+}
@@ -44,8 +44,9 @@ var (
 		os.TempDir(),
 		"edgedb-go-test-server-info",
 	)
-	opts gelcfg.Options
-	once sync.Once
+	opts    gelcfg.Options
+	initErr error
+	once    sync.Once
 )
 
 type info struct {
@@ -88,10 +89,28 @@ func randomBranchName() string {
 	return fmt.Sprintf("test_%v", rand.Intn(10_000_000))
 }
 
+// StartServer starts the test server if it isn't already running and
+// returns the connection options for its default branch.
+func StartServer() (gelcfg.Options, error) {
+	once.Do(initServerInfo)
+	return opts, initErr
+}
+
+// ServerOptions is like StartServer but exits the process if the server
+// can not be started.
+func ServerOptions() gelcfg.Options {
+	o, err := StartServer()
+	if err != nil {
+		Fatal(err)
+	}
+
+	return o
+}
+
 // Options starts the test server if it isn't already running, creates an empty
 // branch, and returns the connection options.
 func Options() gelcfg.Options {
-	once.Do(initServerInfo)
+	opts := ServerOptions()
 
 	pool, err := gelint.NewPool("", opts)
 	if err != nil {
@@ -214,7 +233,7 @@ func readServerStatusFile(fileName string) (*info, error) {
 	return &inf, nil
 }
 
-func startServerProcess() *info {
+func startServerProcess() (*info, error) {
 	log.Print("starting test server")
 	defer log.Print("test server started")
 
@@ -225,7 +244,7 @@ func startServerProcess() *info {
 
 	dir, err := os.MkdirTemp("", "")
 	if err != nil {
-		Fatal(err)
+		return nil, err
 	}
 
 	statusFile := path.Join(dir, "status-file")
@@ -301,7 +320,7 @@ Set EDGEDB_DEBUG_SERVER=1 to see server debug logs.
 
 	err = cmd.Start()
 	if err != nil {
-		Fatal(err)
+		return nil, err
 	}
 
 	log.Println("waiting for test server connection info")
@@ -316,7 +335,7 @@ Set EDGEDB_DEBUG_SERVER=1 to see server debug logs.
 
 	if err != nil {
 		_ = cmd.Process.Kill()
-		Fatal(err)
+		return nil, err
 	}
 
 	if len(localInfo.TLSCertFile) != 0 && runtime.GOOS == "windows" {
@@ -330,7 +349,7 @@ Set EDGEDB_DEBUG_SERVER=1 to see server debug logs.
 			getWSLPath(tmpFile),
 		).Output()
 		if err != nil {
-			Fatal(err)
+			return nil, err
 		}
 		localInfo.TLSCertFile = tmpFile
 	}
@@ -338,21 +357,25 @@ Set EDGEDB_DEBUG_SERVER=1 to see server debug logs.
 	localInfo.PID = cmd.Process.Pid
 	data, err := json.Marshal(localInfo)
 	if err != nil {
-		Fatal(err)
+		return nil, err
 	}
 
 	err = os.WriteFile(testServerInfo, data, 0777)
 	if err != nil {
-		Fatal(err)
+		return nil, err
 	}
 
-	return localInfo
+	return localInfo, nil
 }
 
 func initServerInfo() {
 	i, err := readCachedInfoFile()
 	if err != nil {
-		i = startServerProcess()
+		i, err = startServerProcess()
+		if err != nil {
+			initErr = fmt.Errorf("starting test server: %w", err)
+			return
+		}
 	}
 
 	opts = i.options()
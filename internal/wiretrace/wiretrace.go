@@ -0,0 +1,304 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wiretrace records the protocol frames exchanged on a connection.
+package wiretrace
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// Sender is the side of a connection that sent a frame.
+type Sender string
+
+const (
+	// Client frames are sent by the client.
+	Client Sender = "client"
+	// Server frames are sent by the server.
+	Server Sender = "server"
+)
+
+// Message types that carry secrets and are redacted when recording.
+const (
+	authentication      = 0x52
+	clientHandshake     = 0x56
+	saslInitialResponse = 0x70
+	saslResponse        = 0x72
+)
+
+// Frame is a single protocol message.
+type Frame struct {
+	// Conn identifies the connection the frame belongs to. Connections are
+	// numbered from 1 in the order they were opened.
+	Conn int `json:"conn"`
+
+	// Sender is the side of the connection that sent the frame.
+	Sender Sender `json:"sender"`
+
+	// Type is the name of the message type.
+	Type string `json:"type"`
+
+	// Code is the message type.
+	Code byte `json:"code"`
+
+	// Elapsed is the time between opening the connection and the frame
+	// being sent or received.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Payload is the message body without the type and length header.
+	Payload []byte `json:"payload"`
+}
+
+// Bytes returns the frame as it is sent on the wire.
+func (f *Frame) Bytes() []byte {
+	b := make([]byte, 5, 5+len(f.Payload))
+	b[0] = f.Code
+	binary.BigEndian.PutUint32(b[1:], uint32(4+len(f.Payload)))
+	return append(b, f.Payload...)
+}
+
+// Read decodes frames written by a [Recorder].
+func Read(r io.Reader) ([]Frame, error) {
+	var frames []Frame
+	dec := json.NewDecoder(r)
+	for {
+		var f Frame
+		err := dec.Decode(&f)
+		if errors.Is(err, io.EOF) {
+			return frames, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		frames = append(frames, f)
+	}
+}
+
+// Namer returns the name of a message type.
+type Namer func(code byte, sender Sender) string
+
+// Recorder writes the frames of every connection it wraps to an io.Writer
+// as JSON lines. Authentication messages and the secret key are redacted.
+type Recorder struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	name  Namer
+	conns int
+	err   error
+}
+
+// NewRecorder returns a Recorder that writes to w.
+func NewRecorder(w io.Writer, name Namer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w), name: name}
+}
+
+// Wrap returns a net.Conn that records everything read from and written to
+// conn.
+func (r *Recorder) Wrap(conn net.Conn) net.Conn {
+	r.mu.Lock()
+	r.conns++
+	id := r.conns
+	r.mu.Unlock()
+
+	return Wrap(conn, func(f Frame) {
+		f.Conn = id
+		r.record(f)
+	})
+}
+
+func (r *Recorder) record(f Frame) {
+	f.Type = r.name(f.Code, f.Sender)
+	f.Payload = redact(f)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	if err := r.enc.Encode(f); err != nil {
+		r.err = err
+		log.Println("gel: stopped recording wire trace:", err)
+	}
+}
+
+// redact removes secrets from a frame's payload.
+func redact(f Frame) []byte {
+	switch {
+	case f.Sender == Client && (f.Code == saslInitialResponse ||
+		f.Code == saslResponse):
+		return nil
+	case f.Sender == Server && f.Code == authentication:
+		// Keep the authentication status, drop the SCRAM messages.
+		return f.Payload[:min(4, len(f.Payload))]
+	case f.Sender == Client && f.Code == clientHandshake:
+		return redactHandshake(f.Payload)
+	default:
+		return f.Payload
+	}
+}
+
+// redactHandshake replaces the secret_key parameter of a client handshake.
+func redactHandshake(payload []byte) []byte {
+	s := scanner{data: payload}
+	s.bytes(4) // protocol version
+	n := int(s.uint16())
+	redacted := append([]byte(nil), payload[:s.pos]...)
+	for i := 0; i < n; i++ {
+		name := s.string()
+		value := s.string()
+		if name == "secret_key" && value != "" {
+			value = "[redacted]"
+		}
+
+		redacted = appendString(redacted, name)
+		redacted = appendString(redacted, value)
+	}
+
+	if s.err {
+		return nil
+	}
+
+	return append(redacted, payload[s.pos:]...)
+}
+
+type scanner struct {
+	data []byte
+	pos  int
+	err  bool
+}
+
+func (s *scanner) bytes(n int) []byte {
+	if s.err || n < 0 || len(s.data)-s.pos < n {
+		s.err = true
+		return nil
+	}
+
+	b := s.data[s.pos : s.pos+n]
+	s.pos += n
+	return b
+}
+
+func (s *scanner) uint16() uint16 {
+	b := s.bytes(2)
+	if b == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint16(b)
+}
+
+func (s *scanner) string() string {
+	b := s.bytes(4)
+	if b == nil {
+		return ""
+	}
+
+	return string(s.bytes(int(binary.BigEndian.Uint32(b))))
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// Wrap returns a net.Conn that calls fn with every frame read from and
+// written to conn. Frames read are sent by the server and frames written are
+// sent by the client. fn is called with frames whose Conn and Type are not
+// set.
+func Wrap(conn net.Conn, fn func(Frame)) net.Conn {
+	return &observedConn{Conn: conn, fn: fn, start: time.Now()}
+}
+
+type observedConn struct {
+	net.Conn
+	fn      func(Frame)
+	start   time.Time
+	read    splitter
+	written splitter
+}
+
+func (c *observedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.observe(Server, &c.read, p[:n])
+	return n, err
+}
+
+func (c *observedConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.observe(Client, &c.written, p[:n])
+	return n, err
+}
+
+func (c *observedConn) observe(sender Sender, s *splitter, p []byte) {
+	for _, payload := range s.push(p) {
+		c.fn(Frame{
+			Sender:  sender,
+			Code:    payload[0],
+			Elapsed: time.Since(c.start),
+			Payload: payload[5:],
+		})
+	}
+}
+
+// splitter splits a byte stream into frames.
+type splitter struct {
+	mu     sync.Mutex
+	buf    []byte
+	broken bool
+}
+
+// push adds p to the stream and returns the frames it completes.
+func (s *splitter) push(p []byte) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken {
+		return nil
+	}
+
+	s.buf = append(s.buf, p...)
+
+	var frames [][]byte
+	for len(s.buf) >= 5 {
+		n := int(binary.BigEndian.Uint32(s.buf[1:5]))
+		if n < 4 {
+			// The stream can not be framed anymore.
+			s.broken = true
+			s.buf = nil
+			return frames
+		}
+
+		if len(s.buf) < n+1 {
+			break
+		}
+
+		frames = append(frames, append([]byte(nil), s.buf[:n+1]...))
+		s.buf = s.buf[n+1:]
+	}
+
+	return frames
+}
@@ -0,0 +1,54 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wiretrace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitter(t *testing.T) {
+	sync := []byte{0x53, 0, 0, 0, 4}
+	parse := []byte{0x50, 0, 0, 0, 6, 1, 2}
+
+	var s splitter
+	assert.Nil(t, s.push(parse[:3]))
+	assert.Equal(t, [][]byte{parse}, s.push(parse[3:]))
+
+	stream := append(append([]byte{}, sync...), parse...)
+	assert.Equal(t, [][]byte{sync}, s.push(stream[:6]))
+	assert.Equal(t, [][]byte{parse}, s.push(stream[6:]))
+
+	assert.Nil(t, s.push([]byte{0x53, 0, 0, 0, 1}))
+	assert.Nil(t, s.push(sync), "a malformed stream is not recorded")
+}
+
+func TestRedactHandshake(t *testing.T) {
+	handshake := func(secret string) []byte {
+		b := []byte{0, 3, 0, 0, 0, 2}
+		b = appendString(b, "secret_key")
+		b = appendString(b, secret)
+		b = appendString(b, "user")
+		b = appendString(b, "edgedb")
+		return append(b, 0, 0)
+	}
+
+	assert.Equal(t, handshake("[redacted]"), redactHandshake(handshake("k")))
+	assert.Equal(t, handshake(""), redactHandshake(handshake("")))
+	assert.Nil(t, redactHandshake(handshake("k")[:10]))
+}
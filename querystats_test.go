@@ -0,0 +1,64 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/geldata/gel-go/gelstats"
+)
+
+func TestQueryStats(t *testing.T) {
+	ctx := context.Background()
+	tag := randomName()
+	tagged, err := client.WithQueryTag(tag)
+	require.NoError(t, err)
+
+	name := randomName()
+	query := fmt.Sprintf("SELECT (%s := <int64>1).%s", name, name)
+	filter := gelstats.Filter{Tag: tag}
+
+	before, err := client.QueryStats(ctx, filter)
+	require.NoError(t, err)
+	assert.Empty(t, before.Stats)
+
+	var result []int64
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tagged.Query(ctx, query, &result))
+	}
+
+	after, err := client.QueryStats(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, after.Stats, 1)
+	stats := after.Stats[0]
+	assert.Contains(t, stats.Query, name)
+	assert.Equal(t, tag, stats.Tag)
+	assert.Equal(t, int64(3), stats.Calls)
+	assert.Equal(t, int64(3), stats.Rows)
+	assert.NotEmpty(t, stats.Branch)
+	assert.False(t, after.Taken.Before(before.Taken))
+
+	report := gelstats.Diff(before, after)
+	require.Len(t, report.Tags, 1)
+	assert.Equal(t, tag, report.Tags[0].Tag)
+	assert.Equal(t, int64(3), report.Tags[0].Calls)
+}
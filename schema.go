@@ -0,0 +1,62 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+
+	"github.com/geldata/gel-go/gelcfg"
+	"github.com/geldata/gel-go/gelschema"
+	"github.com/geldata/gel-go/geltypes"
+)
+
+// Schema returns the user defined schema of the client's branch. The schema
+// is loaded in a read only transaction and cached until a DDL statement is
+// executed by any client sharing the same connection pool. DDL executed by
+// other processes is not detected, call [Client.RefreshSchema] to reload the
+// schema after it was changed externally.
+//
+// The returned schema is shared by all callers and must not be modified.
+func (c *Client) Schema(ctx context.Context) (*gelschema.Schema, error) {
+	if schema, _ := c.pool.CachedSchema(); schema != nil {
+		return schema, nil
+	}
+
+	return c.RefreshSchema(ctx)
+}
+
+// RefreshSchema reloads the schema of the client's branch and replaces the
+// cached schema.
+func (c *Client) RefreshSchema(
+	ctx context.Context,
+) (*gelschema.Schema, error) {
+	_, generation := c.pool.CachedSchema()
+
+	var schema *gelschema.Schema
+	client := c.WithTxOptions(gelcfg.NewTxOptions().WithReadOnly(true))
+	err := client.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+		var err error
+		schema, err = gelschema.Load(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.pool.CacheSchema(schema, generation)
+	return schema, nil
+}
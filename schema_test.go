@@ -0,0 +1,82 @@
+// This source file is part of the Gel open source project.
+//
+// Copyright Gel Data Inc. and the Gel authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/geldata/gel-go/gelschema"
+	"github.com/geldata/gel-go/geltypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	ctx := context.Background()
+	schema, err := client.Schema(ctx)
+	require.NoError(t, err)
+
+	bulkTest, ok := schema.ObjectType("default::BulkTest")
+	require.True(t, ok)
+	assert.False(t, bulkTest.Abstract)
+	assert.Equal(t, []string{"std::BaseObject", "std::Object"},
+		bulkTest.Bases)
+
+	name, ok := bulkTest.Property("name")
+	require.True(t, ok)
+	assert.Equal(t, "std::str", name.Target)
+	assert.Equal(t, gelschema.One, name.Cardinality)
+	require.Equal(t, 1, len(name.Constraints))
+	assert.Equal(t, "std::exclusive", name.Constraints[0].Name)
+
+	tags, ok := bulkTest.Property("tags")
+	require.True(t, ok)
+	assert.Equal(t, "array<std::str>", tags.Target)
+	assert.Equal(t, gelschema.AtMostOne, tags.Cardinality)
+
+	_, ok = schema.ObjectType("schema::ObjectType")
+	assert.False(t, ok)
+
+	cached, err := client.Schema(ctx)
+	require.NoError(t, err)
+	assert.Same(t, schema, cached)
+}
+
+func TestSchemaInvalidatedByDDL(t *testing.T) {
+	ctx := context.Background()
+	schema, err := client.Schema(ctx)
+	require.NoError(t, err)
+
+	rollback := errors.New("rollback")
+	err = client.Tx(ctx, func(ctx context.Context, tx geltypes.Tx) error {
+		e := tx.Execute(ctx, `CREATE TYPE SchemaSample;`)
+		if e != nil {
+			return e
+		}
+		return rollback
+	})
+	require.ErrorIs(t, err, rollback)
+
+	reloaded, err := client.Schema(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, schema, reloaded)
+
+	_, ok := reloaded.ObjectType("default::SchemaSample")
+	assert.False(t, ok)
+}
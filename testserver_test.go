@@ -116,6 +116,13 @@ func initServer() {
 				type TxTest {
 					required property name -> str;
 				}
+				type BulkTest {
+					required name: str {
+						constraint exclusive;
+					};
+					nick: str;
+					tags: array<str>;
+				}
 				type Counter {
 					name: str {
 						constraint exclusive;